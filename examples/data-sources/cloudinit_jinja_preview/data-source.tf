@@ -0,0 +1,21 @@
+data "cloudinit_jinja_preview" "foobar" {
+  instance_data = jsonencode({
+    v1 = {
+      local_hostname = "web-01"
+      region         = "us-east-1"
+    }
+    ds = {
+      meta_data = {
+        "instance-id" = "i-0123456789abcdef0"
+      }
+    }
+  })
+
+  part {
+    content = file("${path.module}/cloud-config.yaml")
+  }
+}
+
+output "rendered" {
+  value = data.cloudinit_jinja_preview.foobar.part[0].rendered
+}
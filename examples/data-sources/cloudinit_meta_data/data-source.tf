@@ -0,0 +1,14 @@
+data "cloudinit_config" "foobar" {
+  gzip          = false
+  base64_encode = false
+
+  part {
+    content_type = "text/cloud-config"
+    content      = file("${path.module}/cloud-config.yaml")
+  }
+}
+
+data "cloudinit_meta_data" "foobar" {
+  user_data      = data.cloudinit_config.foobar.rendered
+  local_hostname = "web-01"
+}
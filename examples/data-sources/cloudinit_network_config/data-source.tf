@@ -0,0 +1,25 @@
+data "cloudinit_network_config" "foobar" {
+  ethernet {
+    name        = "eth0"
+    mac_address = "52:54:00:12:34:56"
+  }
+
+  ethernet {
+    name        = "eth1"
+    mac_address = "52:54:00:12:34:57"
+  }
+
+  bond {
+    name        = "bond0"
+    interfaces  = ["eth0", "eth1"]
+    mode        = "active-backup"
+    primary     = "eth0"
+    addresses   = ["192.0.2.10/24"]
+    nameservers = ["192.0.2.53"]
+
+    route {
+      to  = "default"
+      via = "192.0.2.1"
+    }
+  }
+}
@@ -0,0 +1,32 @@
+data "cloudinit_meta_data" "foobar" {
+  instance_id    = "iid-web-01"
+  local_hostname = "web-01"
+}
+
+data "cloudinit_network_config" "foobar" {
+  ethernet {
+    name      = "ens192"
+    addresses = ["192.0.2.10/24"]
+
+    route {
+      to  = "default"
+      via = "192.0.2.1"
+    }
+  }
+}
+
+data "cloudinit_vmware_guestinfo" "foobar" {
+  meta_data      = data.cloudinit_meta_data.foobar.rendered
+  network_config = data.cloudinit_network_config.foobar.rendered
+
+  part {
+    content_type = "text/cloud-config"
+    content      = file("${path.module}/cloud-config.yaml")
+  }
+}
+
+resource "vsphere_virtual_machine" "foobar" {
+  # ...
+
+  extra_config = data.cloudinit_vmware_guestinfo.foobar.guestinfo
+}
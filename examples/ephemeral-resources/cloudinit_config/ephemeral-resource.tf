@@ -0,0 +1,14 @@
+variable "join_token" {
+  type      = string
+  sensitive = true
+  ephemeral = true
+}
+
+ephemeral "cloudinit_config" "foobar" {
+  part {
+    filename     = "join.sh"
+    content_type = "text/x-shellscript"
+
+    content = "#!/bin/sh\nkubeadm join --token ${var.join_token}\n"
+  }
+}
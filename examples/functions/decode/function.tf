@@ -0,0 +1,4 @@
+# Inspect the parts of user data taken from an existing launch template
+output "user_data_parts" {
+  value = provider::cloudinit::decode(data.aws_launch_template.example.user_data)
+}
@@ -0,0 +1,15 @@
+# Preview the cloud-config cloud-init will see on boot
+output "effective_cloud_config" {
+  value = provider::cloudinit::merge_cloud_config([
+    {
+      cloud_config = {
+        packages = ["git"]
+        runcmd   = ["echo base"]
+      }
+    },
+    {
+      content    = file("${path.module}/app.yaml")
+      merge_type = "list(append)+dict(recurse_array)+str()"
+    },
+  ])
+}
@@ -0,0 +1,24 @@
+locals {
+  user_data = provider::cloudinit::render(
+    [
+      {
+        filename     = "hello-script.sh"
+        content_type = "text/x-shellscript"
+        content      = file("${path.module}/hello-script.sh")
+      },
+      {
+        filename     = "cloud-config.yaml"
+        content_type = "text/cloud-config"
+        content      = file("${path.module}/cloud-config.yaml")
+      },
+    ],
+    {
+      gzip          = false
+      base64_encode = false
+    },
+  )
+}
+
+output "user_data" {
+  value = local.user_data
+}
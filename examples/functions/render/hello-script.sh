@@ -0,0 +1,2 @@
+#!/bin/sh
+echo "Hello World! I'm starting up now at $(date -R)!"
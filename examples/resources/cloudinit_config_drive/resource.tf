@@ -0,0 +1,31 @@
+resource "cloudinit_config_drive" "foobar" {
+  filename = "${path.module}/config-drive.iso"
+
+  meta_data = jsonencode({
+    uuid     = "0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+    hostname = "cloudimg"
+    public_keys = {
+      default = file("~/.ssh/id_ed25519.pub")
+    }
+  })
+
+  network_data = jsonencode({
+    links = [{
+      id                   = "eth0"
+      type                 = "phy"
+      ethernet_mac_address = "52:54:00:12:34:56"
+    }]
+    networks = [{
+      id   = "network0"
+      link = "eth0"
+      type = "ipv4_dhcp"
+    }]
+    services = []
+  })
+
+  part {
+    content_type = "text/cloud-config"
+
+    content = file("${path.module}/cloud-config.yaml")
+  }
+}
@@ -0,0 +1,14 @@
+resource "cloudinit_nocloud_seed" "foobar" {
+  filename = "${path.module}/seed.iso"
+
+  meta_data = yamlencode({
+    instance-id    = "iid-local01"
+    local-hostname = "cloudimg"
+  })
+
+  part {
+    content_type = "text/cloud-config"
+
+    content = file("${path.module}/cloud-config.yaml")
+  }
+}
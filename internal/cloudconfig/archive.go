@@ -0,0 +1,176 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ArchiveHeader is the first line cloud-init requires of a cloud-config-archive
+// document.
+const ArchiveHeader = "#cloud-config-archive"
+
+// ArchiveEntry is an entry of a cloud-config-archive document, which cloud-init
+// turns into a part of a multi-part MIME message.
+type ArchiveEntry struct {
+	Type      string
+	Filename  string
+	MergeType string
+	Content   []byte
+
+	// LaunchIndex limits the entry to the instance of a multi-instance launch
+	// with this index, or to all instances when nil.
+	LaunchIndex *int
+}
+
+// MarshalArchive writes entries as a cloud-config-archive document, a YAML list
+// of mappings with the header of the part as keys. Content which is not valid
+// UTF-8 is written as !!binary, which cloud-init decodes back into bytes.
+func MarshalArchive(entries []ArchiveEntry) ([]byte, error) {
+	document := &yaml.Node{Kind: yaml.SequenceNode}
+
+	for _, entry := range entries {
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+
+		addString := func(key string, value string) {
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+			)
+		}
+
+		addString("type", entry.Type)
+
+		if entry.Filename != "" {
+			addString("filename", entry.Filename)
+		}
+
+		if entry.LaunchIndex != nil {
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "launch-index"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(*entry.LaunchIndex)},
+			)
+		}
+
+		// cloud-init adds keys it does not know as headers of the part, which is
+		// where it looks for the merge type.
+		if entry.MergeType != "" {
+			addString("X-Merge-Type", entry.MergeType)
+		}
+
+		if utf8.Valid(entry.Content) {
+			addString("content", string(entry.Content))
+		} else {
+			mapping.Content = append(mapping.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "content"},
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!binary", Value: base64.StdEncoding.EncodeToString(entry.Content)},
+			)
+		}
+
+		document.Content = append(document.Content, mapping)
+	}
+
+	return marshal(ArchiveHeader, document)
+}
+
+// ParseArchive reads the entries of a cloud-config-archive document. Like
+// cloud-init, an entry may be a string, which is taken as its content, or a
+// mapping with the type, content, filename and launch-index keys and the
+// X-Merge-Type header. Entries without a type are left for the caller to infer
+// one from the content. Other headers are not supported.
+func ParseArchive(data []byte) ([]ArchiveEntry, error) {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parsing YAML: %w", err)
+	}
+
+	if document == nil {
+		return nil, nil
+	}
+
+	list, ok := document.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of entries, got %s", jsonType(document))
+	}
+
+	entries := make([]ArchiveEntry, 0, len(list))
+
+	for i, item := range list {
+		if content, ok := item.(string); ok {
+			entries = append(entries, ArchiveEntry{Content: []byte(content)})
+			continue
+		}
+
+		mapping, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("entry %d: expected a string or a mapping, got %s", i, jsonType(item))
+		}
+
+		keys := make([]string, 0, len(mapping))
+		for key := range mapping {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		var entry ArchiveEntry
+
+		for _, key := range keys {
+			value := mapping[key]
+
+			if strings.EqualFold(key, "launch-index") {
+				index, ok := value.(int)
+				if !ok {
+					return nil, fmt.Errorf("entry %d: expected launch-index to be an integer, got %s", i, jsonType(value))
+				}
+
+				if index < 0 {
+					return nil, fmt.Errorf("entry %d: expected launch-index to be at least 0, got %d", i, index)
+				}
+
+				entry.LaunchIndex = &index
+				continue
+			}
+
+			var field *string
+
+			switch strings.ToLower(key) {
+			case "type":
+				field = &entry.Type
+			case "filename":
+				field = &entry.Filename
+			case "x-merge-type", "merge-type":
+				field = &entry.MergeType
+			case "content":
+				content, ok := value.(string)
+				if !ok {
+					return nil, fmt.Errorf("entry %d: expected content to be a string, got %s", i, jsonType(value))
+				}
+
+				entry.Content = []byte(content)
+				continue
+			default:
+				return nil, fmt.Errorf("entry %d: unsupported key %q, expected type, content, filename, launch-index or X-Merge-Type", i, key)
+			}
+
+			text, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("entry %d: expected %s to be a string, got %s", i, key, jsonType(value))
+			}
+
+			*field = text
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
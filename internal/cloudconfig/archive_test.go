@@ -0,0 +1,150 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMarshalArchive(t *testing.T) {
+	launchIndex := 1
+
+	testCases := map[string]struct {
+		entries  []ArchiveEntry
+		expected string
+	}{
+		"empty": {
+			entries:  nil,
+			expected: "#cloud-config-archive\n[]\n",
+		},
+		"single line content": {
+			entries: []ArchiveEntry{
+				{Type: "text/x-shellscript", Content: []byte("echo hello")},
+			},
+			expected: "#cloud-config-archive\n- type: text/x-shellscript\n  content: echo hello\n",
+		},
+		"multi-line content": {
+			entries: []ArchiveEntry{
+				{Type: "text/cloud-config", Content: []byte("#cloud-config\npackages:\n  - git\n")},
+			},
+			expected: "#cloud-config-archive\n- type: text/cloud-config\n  content: |\n    #cloud-config\n    packages:\n      - git\n",
+		},
+		"filename and merge type": {
+			entries: []ArchiveEntry{
+				{Type: "text/plain", Filename: "a.txt", MergeType: "dict()+list()", Content: []byte("baz")},
+			},
+			expected: "#cloud-config-archive\n- type: text/plain\n  filename: a.txt\n  X-Merge-Type: dict()+list()\n  content: baz\n",
+		},
+		"ambiguous content is quoted": {
+			entries: []ArchiveEntry{
+				{Type: "text/plain", Content: []byte("123")},
+			},
+			expected: "#cloud-config-archive\n- type: text/plain\n  content: \"123\"\n",
+		},
+		"binary content": {
+			entries: []ArchiveEntry{
+				{Type: "application/octet-stream", Content: []byte{0xff, 0x00}},
+			},
+			expected: "#cloud-config-archive\n- type: application/octet-stream\n  content: !!binary /wA=\n",
+		},
+		"launch index": {
+			entries: []ArchiveEntry{
+				{Type: "text/x-shellscript", LaunchIndex: &launchIndex, Content: []byte("echo hello")},
+			},
+			expected: "#cloud-config-archive\n- type: text/x-shellscript\n  launch-index: 1\n  content: echo hello\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := MarshalArchive(tc.entries)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(actual) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseArchive(t *testing.T) {
+	launchIndex := 1
+
+	testCases := map[string]struct {
+		document string
+		expected []ArchiveEntry
+	}{
+		"empty": {
+			document: "#cloud-config-archive\n",
+			expected: nil,
+		},
+		"string entry": {
+			document: "#cloud-config-archive\n- \"#!/bin/sh\\necho hello\\n\"\n",
+			expected: []ArchiveEntry{
+				{Content: []byte("#!/bin/sh\necho hello\n")},
+			},
+		},
+		"all keys": {
+			document: "#cloud-config-archive\n- type: text/x-shellscript\n  filename: hello.sh\n  launch-index: 1\n  X-Merge-Type: list(append)\n  content: echo hello\n",
+			expected: []ArchiveEntry{
+				{Type: "text/x-shellscript", Filename: "hello.sh", MergeType: "list(append)", LaunchIndex: &launchIndex, Content: []byte("echo hello")},
+			},
+		},
+		"binary content": {
+			document: "#cloud-config-archive\n- type: application/octet-stream\n  content: !!binary /wA=\n",
+			expected: []ArchiveEntry{
+				{Type: "application/octet-stream", Content: []byte{0xff, 0x00}},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseArchive([]byte(tc.document))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseArchive_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		document string
+		expected string
+	}{
+		"not a list": {
+			document: "#cloud-config-archive\ntype: text/plain\n",
+			expected: "expected a list of entries, got object",
+		},
+		"negative launch index": {
+			document: "#cloud-config-archive\n- launch-index: -1\n  content: echo hello\n",
+			expected: "entry 0: expected launch-index to be at least 0, got -1",
+		},
+		"unsupported key": {
+			document: "#cloud-config-archive\n- content: echo hello\n  X-Custom: foo\n",
+			expected: `entry 0: unsupported key "X-Custom", expected type, content, filename, launch-index or X-Merge-Type`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseArchive([]byte(tc.document))
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
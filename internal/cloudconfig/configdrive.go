@@ -0,0 +1,142 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// uuidPattern matches a UUID in its canonical form, as OpenStack and Ironic
+// write the uuid of an instance.
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// publicKeyTypes are the SSH public key types OpenSSH accepts in
+// authorized_keys files.
+var publicKeyTypes = map[string]bool{
+	"ssh-rsa":                            true,
+	"ssh-dss":                            true,
+	"ssh-ed25519":                        true,
+	"ecdsa-sha2-nistp256":                true,
+	"ecdsa-sha2-nistp384":                true,
+	"ecdsa-sha2-nistp521":                true,
+	"sk-ssh-ed25519@openssh.com":         true,
+	"sk-ecdsa-sha2-nistp256@openssh.com": true,
+}
+
+// ValidateConfigDriveMetaData checks the meta_data.json document of an
+// OpenStack config drive, given as a JSON object. cloud-init requires the uuid
+// key, which it uses as the instance-id, and copies hostname to the
+// local-hostname. public_keys maps key names to SSH public keys.
+func ValidateConfigDriveMetaData(data []byte) error {
+	document, err := decodeJSONObject(data)
+	if err != nil {
+		return err
+	}
+
+	uuid, ok := document["uuid"].(string)
+	if !ok {
+		return fmt.Errorf("expected the uuid key to be a string, which cloud-init uses as the instance-id")
+	}
+
+	if !uuidPattern.MatchString(uuid) {
+		return fmt.Errorf("expected the uuid key to be a UUID, such as 0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b, got %q", uuid)
+	}
+
+	if value, ok := document["hostname"]; ok {
+		hostname, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected the hostname key to be a string, got %s", jsonType(value))
+		}
+
+		if err := ValidateHostname(hostname); err != nil {
+			return fmt.Errorf("invalid hostname %q: %w", hostname, err)
+		}
+	}
+
+	if value, ok := document["public_keys"]; ok {
+		publicKeys, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("expected the public_keys key to be an object of key names and SSH public keys, got %s", jsonType(value))
+		}
+
+		names := make([]string, 0, len(publicKeys))
+		for name := range publicKeys {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			key, ok := publicKeys[name].(string)
+			if !ok {
+				return fmt.Errorf("expected public key %q to be a string, got %s", name, jsonType(publicKeys[name]))
+			}
+
+			if err := ValidatePublicKey(key); err != nil {
+				return fmt.Errorf("invalid public key %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateConfigDriveNetworkData checks the network_data.json document of an
+// OpenStack config drive, given as a JSON object whose links, networks and
+// services keys are lists.
+func ValidateConfigDriveNetworkData(data []byte) error {
+	document, err := decodeJSONObject(data)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{"links", "networks", "services"} {
+		if value, ok := document[key]; ok {
+			if _, ok := value.([]any); !ok {
+				return fmt.Errorf("expected the %s key to be a list, got %s", key, jsonType(value))
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateConfigDriveVendorData checks the vendor_data.json document of an
+// OpenStack config drive, given as a JSON object.
+func ValidateConfigDriveVendorData(data []byte) error {
+	_, err := decodeJSONObject(data)
+	return err
+}
+
+// ValidatePublicKey checks that key is an SSH public key in the format of an
+// authorized_keys line, such as "ssh-ed25519 AAAAC3Nza... user@host".
+func ValidatePublicKey(key string) error {
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return fmt.Errorf("expected a key type followed by the base64 encoded key")
+	}
+
+	keyType := fields[0]
+	if !publicKeyTypes[keyType] {
+		return fmt.Errorf("unsupported key type %q", keyType)
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return fmt.Errorf("decoding key: %w", err)
+	}
+
+	// The key starts with its type as a length-prefixed string.
+	if len(blob) < 4 || int(binary.BigEndian.Uint32(blob)) != len(keyType) || !bytes.HasPrefix(blob[4:], []byte(keyType)) {
+		return fmt.Errorf("expected a %s key, which the decoded key is not", keyType)
+	}
+
+	return nil
+}
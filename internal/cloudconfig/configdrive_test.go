@@ -0,0 +1,126 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"testing"
+)
+
+const testPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHTue4JVkwm7l/ynjOgg7XXAQOGsntY1T3Lgf2Nddx87 user@example.com"
+
+func TestValidateConfigDriveMetaData(t *testing.T) {
+	testCases := map[string]struct {
+		metaData string
+		expected string
+	}{
+		"uuid": {
+			metaData: `{"uuid":"0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b"}`,
+		},
+		"hostname and public keys": {
+			metaData: `{"uuid":"0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b","hostname":"web-01","public_keys":{"default":"` + testPublicKey + `"}}`,
+		},
+		"not an object": {
+			metaData: `["0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b"]`,
+			expected: "expected a JSON object, got array",
+		},
+		"missing uuid": {
+			metaData: `{"hostname":"web-01"}`,
+			expected: "expected the uuid key to be a string, which cloud-init uses as the instance-id",
+		},
+		"invalid uuid": {
+			metaData: `{"uuid":"iid-local01"}`,
+			expected: `expected the uuid key to be a UUID, such as 0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b, got "iid-local01"`,
+		},
+		"invalid hostname": {
+			metaData: `{"uuid":"0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b","hostname":"web_01"}`,
+			expected: `invalid hostname "web_01": expected labels of at most 63 letters, digits and hyphens, separated by dots, which do not start or end with a hyphen`,
+		},
+		"public keys as a list": {
+			metaData: `{"uuid":"0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b","public_keys":["` + testPublicKey + `"]}`,
+			expected: "expected the public_keys key to be an object of key names and SSH public keys, got array",
+		},
+		"invalid public key": {
+			metaData: `{"uuid":"0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b","public_keys":{"default":"ssh-rsa AAAAC3NzaC1lZDI1NTE5AAAAIHTue4JVkwm7l/ynjOgg7XXAQOGsntY1T3Lgf2Nddx87"}}`,
+			expected: `invalid public key "default": expected a ssh-rsa key, which the decoded key is not`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateConfigDriveMetaData([]byte(tc.metaData))
+
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateConfigDriveNetworkData(t *testing.T) {
+	testCases := map[string]struct {
+		networkData string
+		expected    string
+	}{
+		"links and networks": {
+			networkData: `{"links":[{"id":"eth0","type":"phy","ethernet_mac_address":"52:54:00:12:34:56"}],"networks":[{"id":"network0","link":"eth0","type":"ipv4_dhcp"}],"services":[]}`,
+		},
+		"links not a list": {
+			networkData: `{"links":{"id":"eth0"}}`,
+			expected:    "expected the links key to be a list, got object",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateConfigDriveNetworkData([]byte(tc.networkData))
+
+			if tc.expected == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidatePublicKey(t *testing.T) {
+	testCases := map[string]bool{
+		testPublicKey: true,
+		"ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHTue4JVkwm7l/ynjOgg7XXAQOGsntY1T3Lgf2Nddx87": true,
+		"AAAAC3NzaC1lZDI1NTE5AAAAIHTue4JVkwm7l/ynjOgg7XXAQOGsntY1T3Lgf2Nddx87":             false,
+		"ssh-foo AAAAC3NzaC1lZDI1NTE5AAAAIHTue4JVkwm7l/ynjOgg7XXAQOGsntY1T3Lgf2Nddx87":     false,
+		"ssh-ed25519 not-base64":   false,
+		"ssh-ed25519 c3NoLXJzYQ==": false,
+	}
+
+	for key, valid := range testCases {
+		t.Run(key, func(t *testing.T) {
+			if err := ValidatePublicKey(key); (err == nil) != valid {
+				t.Errorf("expected valid %t for %q, got %v", valid, key, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Keys of the guestinfo variables the VMware datasource of cloud-init reads.
+const (
+	GuestInfoUserData         = "guestinfo.userdata"
+	GuestInfoUserDataEncoding = "guestinfo.userdata.encoding"
+	GuestInfoMetaData         = "guestinfo.metadata"
+	GuestInfoMetaDataEncoding = "guestinfo.metadata.encoding"
+)
+
+// Encodings of guestinfo variables, as the VMware datasource names them.
+const (
+	EncodingBase64     = "base64"
+	EncodingGzipBase64 = "gzip+base64"
+)
+
+const (
+	// NetworkKey is the meta-data key the VMware datasource reads the network
+	// config from.
+	NetworkKey = "network"
+
+	// NetworkEncodingKey is the meta-data key of the encoding of NetworkKey,
+	// when it holds an encoded string rather than a mapping.
+	NetworkEncodingKey = "network.encoding"
+)
+
+// EncodeGuestInfo returns data in the given encoding, EncodingBase64 or
+// EncodingGzipBase64.
+func EncodeGuestInfo(data []byte, encoding string) (string, error) {
+	switch encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case EncodingGzipBase64:
+		var buffer bytes.Buffer
+
+		gzipWriter := gzip.NewWriter(&buffer)
+		if _, err := gzipWriter.Write(data); err != nil {
+			return "", err
+		}
+
+		if err := gzipWriter.Close(); err != nil {
+			return "", err
+		}
+
+		return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+// EmbedNetworkConfig adds networkConfig to the meta-data document metaData
+// under NetworkKey, where the VMware datasource looks for it. With an empty
+// encoding the network config is added as a mapping, otherwise it is added as
+// a string in that encoding, with the encoding under NetworkEncodingKey. Both
+// documents may be YAML or JSON, and metaData may be empty.
+func EmbedNetworkConfig(metaData []byte, networkConfig []byte, encoding string) ([]byte, error) {
+	document, err := decodeYAMLMapping(metaData)
+	if err != nil {
+		return nil, fmt.Errorf("parsing meta-data: %w", err)
+	}
+
+	for i := 0; i < len(document.Content); i += 2 {
+		if key := document.Content[i].Value; key == NetworkKey || key == NetworkEncodingKey {
+			return nil, fmt.Errorf("unexpected key %q in the meta-data, which is set from the network config", key)
+		}
+	}
+
+	network, err := decodeYAMLMapping(networkConfig)
+	if err != nil {
+		return nil, fmt.Errorf("parsing network config: %w", err)
+	}
+
+	if len(network.Content) == 0 {
+		return nil, fmt.Errorf("parsing network config: expected a non-empty document")
+	}
+
+	addKey := func(key string, value *yaml.Node) {
+		document.Content = append(document.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	if encoding == "" {
+		addKey(NetworkKey, network)
+		return marshal("", document)
+	}
+
+	encoded, err := EncodeGuestInfo(networkConfig, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	addKey(NetworkKey, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: encoded})
+	addKey(NetworkEncodingKey, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: encoding})
+
+	return marshal("", document)
+}
+
+// decodeYAMLMapping decodes a YAML or JSON document which must be a mapping,
+// or empty, into a mapping node. The nodes are written in block style, so that
+// JSON documents are written as YAML like the rest.
+func decodeYAMLMapping(data []byte) (*yaml.Node, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode}, nil
+	}
+
+	mapping := document.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping, got %s", mapping.ShortTag())
+	}
+
+	var blockStyle func(node *yaml.Node)
+	blockStyle = func(node *yaml.Node) {
+		node.Style &^= yaml.FlowStyle
+		for _, child := range node.Content {
+			blockStyle(child)
+		}
+	}
+
+	blockStyle(mapping)
+
+	return mapping, nil
+}
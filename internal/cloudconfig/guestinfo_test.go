@@ -0,0 +1,144 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"testing"
+)
+
+func TestEncodeGuestInfo(t *testing.T) {
+	testCases := map[string]struct {
+		encoding string
+		decode   func(t *testing.T, encoded string) string
+	}{
+		EncodingBase64: {
+			encoding: EncodingBase64,
+			decode: func(t *testing.T, encoded string) string {
+				return decodeGuestInfoBase64(t, encoded)
+			},
+		},
+		EncodingGzipBase64: {
+			encoding: EncodingGzipBase64,
+			decode: func(t *testing.T, encoded string) string {
+				gzipReader, err := gzip.NewReader(bytes.NewReader([]byte(decodeGuestInfoBase64(t, encoded))))
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				decoded, err := io.ReadAll(gzipReader)
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				return string(decoded)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := EncodeGuestInfo([]byte("#cloud-config\n"), tc.encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if decoded := tc.decode(t, encoded); decoded != "#cloud-config\n" {
+				t.Errorf("expected %q after decoding, got %q", "#cloud-config\n", decoded)
+			}
+		})
+	}
+}
+
+func TestEmbedNetworkConfig(t *testing.T) {
+	networkConfig := "version: 2\nethernets:\n  eth0:\n    dhcp4: true\n"
+
+	testCases := map[string]struct {
+		metaData string
+		encoding string
+		expected string
+	}{
+		"base64": {
+			metaData: "instance-id: iid-local01\n",
+			encoding: EncodingBase64,
+			expected: "instance-id: iid-local01\nnetwork: " + base64.StdEncoding.EncodeToString([]byte(networkConfig)) + "\nnetwork.encoding: base64\n",
+		},
+		"mapping": {
+			metaData: "instance-id: iid-local01\n",
+			expected: "instance-id: iid-local01\nnetwork:\n  version: 2\n  ethernets:\n    eth0:\n      dhcp4: true\n",
+		},
+		"JSON meta-data": {
+			metaData: `{"instance-id": "iid-local01", "public-keys": ["ssh-ed25519 AAAA"]}`,
+			expected: "\"instance-id\": \"iid-local01\"\n\"public-keys\":\n  - \"ssh-ed25519 AAAA\"\nnetwork:\n  version: 2\n  ethernets:\n    eth0:\n      dhcp4: true\n",
+		},
+		"empty meta-data": {
+			metaData: "",
+			expected: "network:\n  version: 2\n  ethernets:\n    eth0:\n      dhcp4: true\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := EmbedNetworkConfig([]byte(tc.metaData), []byte(networkConfig), tc.encoding)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(actual) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestEmbedNetworkConfig_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		metaData      string
+		networkConfig string
+		expected      string
+	}{
+		"meta-data not a mapping": {
+			metaData:      "- iid-local01\n",
+			networkConfig: "version: 2\n",
+			expected:      "parsing meta-data: expected a mapping, got !!seq",
+		},
+		"meta-data with a network key": {
+			metaData:      "instance-id: iid-local01\nnetwork: {}\n",
+			networkConfig: "version: 2\n",
+			expected:      `unexpected key "network" in the meta-data, which is set from the network config`,
+		},
+		"empty network config": {
+			metaData:      "instance-id: iid-local01\n",
+			networkConfig: "",
+			expected:      "parsing network config: expected a non-empty document",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := EmbedNetworkConfig([]byte(tc.metaData), []byte(tc.networkConfig), EncodingBase64)
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
+
+func decodeGuestInfoBase64(t *testing.T, encoded string) string {
+	t.Helper()
+
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return string(decoded)
+}
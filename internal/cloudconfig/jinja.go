@@ -0,0 +1,119 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/jinja"
+)
+
+// MissingJinjaVariablePrefix is what cloud-init writes in place of an undefined
+// variable of a Jinja template, followed by the name of the variable.
+const MissingJinjaVariablePrefix = "CI_MISSING_JINJA_VAR/"
+
+// templateHeader matches the first line of a template, with the template type.
+var templateHeader = regexp.MustCompile(`(?i)^##\s*template:(.*)`)
+
+// versionKey matches the keys of instance data whose keys cloud-init also makes
+// available at the top level, such as v1.
+var versionKey = regexp.MustCompile(`^v\d+$`)
+
+// IsJinjaTemplate reports whether the first line of content is the header of a
+// Jinja template, such as "## template: jinja". Like cloud-init, the case and
+// spacing of the header are ignored.
+func IsJinjaTemplate(content string) bool {
+	firstLine, _, _ := strings.Cut(content, "\n")
+
+	match := templateHeader.FindStringSubmatch(firstLine)
+	return match != nil && strings.ToLower(strings.TrimSpace(match[1])) == "jinja"
+}
+
+// JinjaVariables returns the variables cloud-init renders Jinja templates with
+// from an instance-data.json document given as a JSON object. Like cloud-init,
+// keys with dashes or dots also have an alias with underscores, such as
+// ds.meta_data.local_hostname for local-hostname, and the keys of v1 are also
+// variables themselves.
+func JinjaVariables(instanceData []byte) (map[string]any, error) {
+	document, err := decodeJSONObject(instanceData)
+	if err != nil {
+		return nil, err
+	}
+
+	return jinjaVariables(document), nil
+}
+
+func jinjaVariables(data map[string]any) map[string]any {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	variables := make(map[string]any, len(data))
+	for _, key := range keys {
+		variables[key] = data[key]
+
+		if mapping, ok := data[key].(map[string]any); ok {
+			converted := jinjaVariables(mapping)
+			variables[key] = converted
+
+			if versionKey.MatchString(key) {
+				for subkey, value := range converted {
+					variables[subkey] = value
+				}
+			}
+		}
+
+		if alias := strings.NewReplacer("-", "_", ".", "_").Replace(key); alias != key {
+			variables[alias] = variables[key]
+		}
+	}
+
+	return variables
+}
+
+// RenderJinjaTemplate renders a part whose content is a Jinja template with
+// variables from JinjaVariables, like cloud-init does on boot. The header line
+// is removed, and undefined variables are written as
+// MissingJinjaVariablePrefix followed by their name, which are also returned.
+// The lines of errors are counted from the start of content.
+func RenderJinjaTemplate(content string, variables map[string]any) (string, []string, error) {
+	_, template, _ := strings.Cut(content, "\n")
+
+	var undefined []string
+
+	rendered, err := jinja.Render(template, variables, jinja.Options{
+		TrimBlocks: true,
+		Undefined: func(name string) string {
+			if !slices.Contains(undefined, name) {
+				undefined = append(undefined, name)
+			}
+
+			return MissingJinjaVariablePrefix + name
+		},
+	})
+	if err != nil {
+		var templateErr *jinja.Error
+		if errors.As(err, &templateErr) {
+			return "", nil, fmt.Errorf("line %d: %s", templateErr.Line+1, templateErr.Message)
+		}
+
+		return "", nil, err
+	}
+
+	// cloud-init adds back the newline at the end of the template, which Jinja
+	// removes.
+	if strings.HasSuffix(template, "\n") {
+		rendered += "\n"
+	}
+
+	return rendered, undefined, nil
+}
@@ -0,0 +1,123 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsJinjaTemplate(t *testing.T) {
+	testCases := map[string]bool{
+		"## template: jinja\n#cloud-config\n": true,
+		"##template:JINJA \n#cloud-config\n":  true,
+		"## template: jinja":                  true,
+		"## template: basic\n":                false,
+		"#cloud-config\n## template: jinja\n": false,
+		" ## template: jinja\n":               false,
+	}
+
+	for content, expected := range testCases {
+		if actual := IsJinjaTemplate(content); actual != expected {
+			t.Errorf("expected IsJinjaTemplate(%q) to be %t", content, expected)
+		}
+	}
+}
+
+func TestJinjaVariables(t *testing.T) {
+	variables, err := JinjaVariables([]byte(`{
+		"v1": {"local_hostname": "web-01", "cloud_name": "nocloud"},
+		"ds": {"meta_data": {"local-hostname": "web-01", "instance-id": "iid-01"}},
+		"cloud_name": "overridden by v1"
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]any{
+		"v1": map[string]any{"local_hostname": "web-01", "cloud_name": "nocloud"},
+		"ds": map[string]any{
+			"meta_data": map[string]any{
+				"local-hostname": "web-01",
+				"local_hostname": "web-01",
+				"instance-id":    "iid-01",
+				"instance_id":    "iid-01",
+			},
+		},
+		"local_hostname": "web-01",
+		"cloud_name":     "nocloud",
+	}
+
+	if !reflect.DeepEqual(variables, expected) {
+		t.Errorf("expected:\n%#v\ngot:\n%#v", expected, variables)
+	}
+}
+
+func TestJinjaVariables_invalid(t *testing.T) {
+	_, err := JinjaVariables([]byte(`["v1"]`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if expected := "expected a JSON object, got array"; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestRenderJinjaTemplate(t *testing.T) {
+	variables, err := JinjaVariables([]byte(`{"v1": {"local_hostname": "web-01", "region": "us-east-1"}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testCases := map[string]struct {
+		content   string
+		expected  string
+		undefined []string
+	}{
+		"variables": {
+			content:  "## template: jinja\n#cloud-config\nhostname: {{ v1.local_hostname }}\nregion: {{ region }}\n",
+			expected: "#cloud-config\nhostname: web-01\nregion: us-east-1\n",
+		},
+		// Jinja removes the newline at the end before trim_blocks applies to it,
+		// and cloud-init adds it back after rendering.
+		"blocks": {
+			content:  "## template: jinja\n#!/bin/sh\n{% if v1.region.startswith('us-') %}\necho us\n{% endif %}\n",
+			expected: "#!/bin/sh\necho us\n\n",
+		},
+		"undefined variables": {
+			content:   "## template: jinja\n#cloud-config\nfqdn: {{ v1.local_hostname }}.{{ v1.domain }}\nzone: {{ v1.zone }}\nhostname: {{ v1.zone }}",
+			expected:  "#cloud-config\nfqdn: web-01.CI_MISSING_JINJA_VAR/domain\nzone: CI_MISSING_JINJA_VAR/zone\nhostname: CI_MISSING_JINJA_VAR/zone",
+			undefined: []string{"domain", "zone"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rendered, undefined, err := RenderJinjaTemplate(tc.content, variables)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if rendered != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, rendered)
+			}
+
+			if !reflect.DeepEqual(undefined, tc.undefined) {
+				t.Errorf("expected undefined variables %q, got %q", tc.undefined, undefined)
+			}
+		})
+	}
+}
+
+func TestRenderJinjaTemplate_invalid(t *testing.T) {
+	_, _, err := RenderJinjaTemplate("## template: jinja\n#cloud-config\n{% if v1.zone %}\n", map[string]any{"v1": map[string]any{}})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	if expected := "line 3: unexpected end of template, expected elif or else or endif"; err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err)
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Header is the first line cloud-init requires of a cloud-config document.
+const Header = "#cloud-config"
+
+// MarshalJSON converts a cloud-config document given as a JSON object, such as
+// the output of Terraform's jsonencode, into YAML with the #cloud-config header.
+// Keys are sorted, multi-line strings are written as literal block scalars and
+// strings are only quoted where YAML requires it, so the output stays readable.
+func MarshalJSON(data []byte) ([]byte, error) {
+	document, err := decodeJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(Header, document)
+}
+
+// decodeJSONObject decodes a JSON object, such as the output of Terraform's
+// jsonencode, with its numbers as integers or floats.
+func decodeJSONObject(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+
+	if decoder.More() {
+		return nil, fmt.Errorf("parsing JSON: unexpected data after the top-level value")
+	}
+
+	object, ok := document.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object, got %s", jsonType(document))
+	}
+
+	normalizeNumbers(object)
+
+	return object, nil
+}
+
+// marshal serializes a document into YAML with the given header line, such as
+// the #cloud-config header, if any.
+func marshal(header string, document any) ([]byte, error) {
+	var buffer bytes.Buffer
+	if header != "" {
+		buffer.WriteString(header + "\n")
+	}
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// normalizeNumbers replaces the json.Number values in a decoded JSON document
+// with integers or floats, which would otherwise be written as strings.
+func normalizeNumbers(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+
+		return v.String()
+	}
+
+	return value
+}
+
+// jsonType returns the JSON type name of a value decoded from JSON or YAML.
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number, int, float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	}
+
+	return "object"
+}
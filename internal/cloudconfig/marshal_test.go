@@ -0,0 +1,78 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	testCases := map[string]struct {
+		json     string
+		expected string
+	}{
+		"empty": {
+			json:     `{}`,
+			expected: "#cloud-config\n{}\n",
+		},
+		"sorted keys": {
+			json:     `{"runcmd":["echo hello"],"package_update":true,"packages":["git"]}`,
+			expected: "#cloud-config\npackage_update: true\npackages:\n  - git\nruncmd:\n  - echo hello\n",
+		},
+		"multi-line strings": {
+			json:     `{"write_files":[{"path":"/etc/motd","content":"hello\nworld\n"}]}`,
+			expected: "#cloud-config\nwrite_files:\n  - content: |\n      hello\n      world\n    path: /etc/motd\n",
+		},
+		"ambiguous strings are quoted": {
+			json:     `{"write_files":[{"path":"/etc/motd","permissions":"0644","defer":"true"}],"timezone":"null"}`,
+			expected: "#cloud-config\ntimezone: \"null\"\nwrite_files:\n  - defer: \"true\"\n    path: /etc/motd\n    permissions: \"0644\"\n",
+		},
+		"numbers": {
+			json:     `{"swap":{"size":1073741824},"ratio":0.5}`,
+			expected: "#cloud-config\nratio: 0.5\nswap:\n  size: 1073741824\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := MarshalJSON([]byte(tc.json))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(actual) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMarshalJSON_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		json     string
+		expected string
+	}{
+		"not JSON": {
+			json:     `packages: [git]`,
+			expected: "parsing JSON: invalid character 'p' looking for beginning of value",
+		},
+		"not an object": {
+			json:     `["git"]`,
+			expected: "expected a JSON object, got array",
+		},
+		"trailing data": {
+			json:     `{} {}`,
+			expected: "parsing JSON: unexpected data after the top-level value",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := MarshalJSON([]byte(tc.json))
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
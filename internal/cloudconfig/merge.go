@@ -0,0 +1,484 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// DefaultMergeType is the merge type the cloud-config part handler of
+// cloud-init uses for parts without an X-Merge-Type header or inline merge_how
+// key, which replaces the values of keys set by earlier parts.
+const DefaultMergeType = "dict(replace)+list()+str()"
+
+// Merger is one merger of a merge type, such as list(append).
+type Merger struct {
+	Name    string
+	Options []string
+}
+
+// mergerOptions are the options supported by each of the mergers cloud-init
+// provides.
+var mergerOptions = map[string][]string{
+	"dict": {"allow_delete", "no_replace", "recurse_array", "recurse_list", "recurse_str", "replace"},
+	"list": {"append", "no_replace", "prepend", "recurse_array", "recurse_dict", "recurse_list", "recurse_str", "replace"},
+	"str":  {"append"},
+}
+
+// Validate checks that the merger is one cloud-init provides, and that it
+// supports all of the options. cloud-init fails to merge a part with an
+// unknown merger, and silently ignores unknown options.
+func (m Merger) Validate() error {
+	options, ok := mergerOptions[m.Name]
+	if !ok {
+		names := make([]string, 0, len(mergerOptions))
+		for name := range mergerOptions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if suggestion := Suggest(m.Name, names); suggestion != "" {
+			return fmt.Errorf("unknown merger %q, did you mean %q?", m.Name, suggestion)
+		}
+
+		return fmt.Errorf("unknown merger %q, expected one of: %s", m.Name, strings.Join(names, ", "))
+	}
+
+	for _, option := range m.Options {
+		if slices.Contains(options, option) {
+			continue
+		}
+
+		if suggestion := Suggest(option, options); suggestion != "" {
+			return fmt.Errorf("unknown option %q for the %s merger, did you mean %q?", option, m.Name, suggestion)
+		}
+
+		return fmt.Errorf("unknown option %q for the %s merger, expected any of: %s", option, m.Name, strings.Join(options, ", "))
+	}
+
+	return nil
+}
+
+// String returns the merger in the format of a merge type, such as
+// list(append).
+func (m Merger) String() string {
+	return m.Name + "(" + strings.Join(m.Options, ",") + ")"
+}
+
+var mergerPattern = regexp.MustCompile(`^([\w-]*)\((.*)\)$`)
+
+// ParseMergeType parses a merge type such as "list(append)+dict(no_replace)"
+// the way cloud-init parses the X-Merge-Type header. Names and options are
+// canonicalized, but only checked against the mergers cloud-init provides by
+// Merger.Validate.
+func ParseMergeType(mergeType string) ([]Merger, error) {
+	var mergers []Merger
+
+	for _, name := range strings.Split(mergeType, "+") {
+		name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "-", "_")
+		if name == "" {
+			continue
+		}
+
+		match := mergerPattern.FindStringSubmatch(name)
+		if match == nil {
+			return nil, fmt.Errorf("merger %q is not in the format name(option,...)", name)
+		}
+
+		merger := Merger{Name: match[1]}
+		for _, option := range strings.Split(match[2], ",") {
+			if option = strings.TrimSpace(option); option != "" {
+				merger.Options = append(merger.Options, option)
+			}
+		}
+
+		mergers = append(mergers, merger)
+	}
+
+	return mergers, nil
+}
+
+// Part is a cloud-config part to merge, with the value of its X-Merge-Type
+// header, if any.
+type Part struct {
+	Content   string
+	MergeType string
+}
+
+// Merge merges cloud-config parts in order into a single document, emulating
+// the cloud-config part handler of cloud-init. The mergers of a part are taken
+// from its inline merge_how or merge_type key followed by its X-Merge-Type
+// header, falling back to DefaultMergeType when neither is set. The result is
+// serialized like MarshalJSON.
+func Merge(parts []Part) ([]byte, error) {
+	merged := any(map[string]any{})
+
+	for i, part := range parts {
+		if strings.HasPrefix(part.Content, "## template: jinja") {
+			return nil, fmt.Errorf("part %d: Jinja templates are only rendered on boot, and cannot be merged", i)
+		}
+
+		var document any
+		if err := yaml.Unmarshal([]byte(part.Content), &document); err != nil {
+			return nil, fmt.Errorf("part %d: %s", i, strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+
+		document = normalizeKeys(document)
+		if document == nil {
+			document = map[string]any{}
+		}
+
+		config, ok := document.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("part %d: expected a cloud-config mapping, got %s", i, jsonType(document))
+		}
+
+		mergers, err := extractInlineMergers(config)
+		if err != nil {
+			return nil, fmt.Errorf("part %d: inline merge_how: %w", i, err)
+		}
+
+		headerMergers, err := ParseMergeType(part.MergeType)
+		if err != nil {
+			return nil, fmt.Errorf("part %d: merge_type: %w", i, err)
+		}
+
+		mergers = append(mergers, headerMergers...)
+		if len(mergers) == 0 {
+			mergers, _ = ParseMergeType(DefaultMergeType)
+		}
+
+		m, err := newLookupMerger(mergers)
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", i, err)
+		}
+
+		merged = m.merge(merged, config)
+	}
+
+	return marshal(Header, merged)
+}
+
+// InlineMergers returns the mergers specified by the merge_how key, or the
+// merge_type key in its absence, of a cloud-config document. cloud-init uses
+// them before the mergers of the X-Merge-Type header of the part.
+func InlineMergers(content string) ([]Merger, error) {
+	var document any
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		return nil, fmt.Errorf("%s", strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+
+	config, ok := normalizeKeys(document).(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+
+	return extractInlineMergers(config)
+}
+
+// extractInlineMergers returns the mergers specified by the merge_how key, or
+// the merge_type key in its absence, of a cloud-config document. The key is
+// kept in the document, as cloud-init merges it like any other key. Besides a
+// merge type string, cloud-init accepts a list of mergers given as
+// {name, settings} objects or [name, option...] lists.
+func extractInlineMergers(config map[string]any) ([]Merger, error) {
+	value, ok := config["merge_how"]
+	if !ok {
+		value = config["merge_type"]
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	if mergeType, ok := value.(string); ok {
+		return ParseMergeType(mergeType)
+	}
+
+	list, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a string or a list, got %s", jsonType(value))
+	}
+
+	var mergers []Merger
+
+	for _, item := range list {
+		var merger Merger
+		var options []any
+
+		switch item := item.(type) {
+		case map[string]any:
+			merger.Name, _ = item["name"].(string)
+			merger.Name = strings.ReplaceAll(strings.TrimSpace(merger.Name), "-", "_")
+			options, _ = item["settings"].([]any)
+		case []any:
+			if len(item) > 0 {
+				merger.Name, _ = item[0].(string)
+				options = item[1:]
+			}
+		default:
+			return nil, fmt.Errorf("expected each merger to be an object or a list, got %s", jsonType(item))
+		}
+
+		if merger.Name == "" {
+			continue
+		}
+
+		for _, option := range options {
+			if option, ok := option.(string); ok {
+				merger.Options = append(merger.Options, strings.ToLower(strings.TrimSpace(option)))
+			}
+		}
+
+		mergers = append(mergers, merger)
+	}
+
+	return mergers, nil
+}
+
+// normalizeKeys converts the map[any]any values yaml.v3 decodes mappings with
+// non-string keys into, so that every mapping is a map[string]any.
+func normalizeKeys(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeKeys(item)
+		}
+
+		return result
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeKeys(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeKeys(item)
+		}
+	}
+
+	return value
+}
+
+// lookupMerger dispatches a merge to the first of its mergers which handles
+// the type of the existing value, like cloud-init's LookupMerger. Values of a
+// type no merger handles are kept.
+type lookupMerger struct {
+	dict *dictMerger
+	list *listMerger
+	str  *strMerger
+}
+
+func newLookupMerger(mergers []Merger) (*lookupMerger, error) {
+	m := &lookupMerger{}
+
+	for _, merger := range mergers {
+		if _, ok := mergerOptions[merger.Name]; !ok {
+			return nil, merger.Validate()
+		}
+
+		options := map[string]bool{}
+		for _, option := range merger.Options {
+			options[option] = true
+		}
+
+		switch merger.Name {
+		case "dict":
+			if m.dict == nil {
+				m.dict = newDictMerger(m, options)
+			}
+		case "list":
+			if m.list == nil {
+				m.list = newListMerger(m, options)
+			}
+		case "str":
+			if m.str == nil {
+				m.str = &strMerger{append: options["append"]}
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *lookupMerger) merge(value any, mergeWith any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		if m.dict != nil {
+			return m.dict.merge(value, mergeWith)
+		}
+	case []any:
+		if m.list != nil {
+			return m.list.merge(value, mergeWith)
+		}
+	case string:
+		if m.str != nil {
+			return m.str.merge(value, mergeWith)
+		}
+	}
+
+	return value
+}
+
+type dictMerger struct {
+	lookup       *lookupMerger
+	replace      bool
+	recurseStr   bool
+	recurseArray bool
+	allowDelete  bool
+}
+
+func newDictMerger(lookup *lookupMerger, options map[string]bool) *dictMerger {
+	return &dictMerger{
+		lookup:       lookup,
+		replace:      options["replace"],
+		recurseStr:   options["recurse_str"],
+		recurseArray: options["recurse_array"] || options["recurse_list"],
+		allowDelete:  options["allow_delete"],
+	}
+}
+
+func (m *dictMerger) merge(value map[string]any, mergeWith any) any {
+	other, ok := mergeWith.(map[string]any)
+	if !ok {
+		return value
+	}
+
+	merged := make(map[string]any, len(value)+len(other))
+	for key, v := range value {
+		merged[key] = v
+	}
+
+	for key, newValue := range other {
+		oldValue, exists := merged[key]
+
+		switch {
+		case !exists:
+			merged[key] = newValue
+		case newValue == nil && m.allowDelete:
+			delete(merged, key)
+		case m.replace:
+			merged[key] = newValue
+		default:
+			merged[key] = m.mergeSameKey(oldValue, newValue)
+		}
+	}
+
+	return merged
+}
+
+// mergeSameKey merges the values of a key in both dicts when not replacing.
+// Nested dicts are always merged recursively, as cloud-init requires for
+// backwards compatibility.
+func (m *dictMerger) mergeSameKey(oldValue any, newValue any) any {
+	switch newValue.(type) {
+	case []any:
+		if m.recurseArray {
+			return m.lookup.merge(oldValue, newValue)
+		}
+	case string:
+		if m.recurseStr {
+			return m.lookup.merge(oldValue, newValue)
+		}
+	case map[string]any:
+		return m.lookup.merge(oldValue, newValue)
+	}
+
+	return oldValue
+}
+
+type listMerger struct {
+	lookup       *lookupMerger
+	method       string
+	recurseStr   bool
+	recurseDict  bool
+	recurseArray bool
+}
+
+func newListMerger(lookup *lookupMerger, options map[string]bool) *listMerger {
+	m := &listMerger{
+		lookup:       lookup,
+		method:       "replace",
+		recurseStr:   options["recurse_str"],
+		recurseDict:  options["recurse_dict"],
+		recurseArray: options["recurse_array"] || options["recurse_list"],
+	}
+
+	for _, method := range []string{"append", "prepend", "replace", "no_replace"} {
+		if options[method] {
+			m.method = method
+			break
+		}
+	}
+
+	return m
+}
+
+func (m *listMerger) merge(value []any, mergeWith any) any {
+	other, ok := mergeWith.([]any)
+	if !ok {
+		if m.method == "replace" {
+			return mergeWith
+		}
+
+		other = []any{mergeWith}
+	}
+
+	switch m.method {
+	case "append":
+		return append(append([]any{}, value...), other...)
+	case "prepend":
+		return append(append([]any{}, other...), value...)
+	}
+
+	// Elements at the same index are replaced, or kept with no_replace.
+	// Elements beyond the length of the shorter list are kept as they are.
+	merged := append([]any{}, value...)
+	for i := 0; i < len(merged) && i < len(other); i++ {
+		merged[i] = m.mergeSameIndex(merged[i], other[i])
+	}
+
+	return merged
+}
+
+func (m *listMerger) mergeSameIndex(oldValue any, newValue any) any {
+	if m.method == "no_replace" {
+		return oldValue
+	}
+
+	switch newValue.(type) {
+	case []any:
+		if m.recurseArray {
+			return m.lookup.merge(oldValue, newValue)
+		}
+	case string:
+		if m.recurseStr {
+			return m.lookup.merge(oldValue, newValue)
+		}
+	case map[string]any:
+		if m.recurseDict {
+			return m.lookup.merge(oldValue, newValue)
+		}
+	}
+
+	return newValue
+}
+
+type strMerger struct {
+	append bool
+}
+
+func (m *strMerger) merge(value string, mergeWith any) any {
+	if other, ok := mergeWith.(string); ok && m.append {
+		return value + other
+	}
+
+	return mergeWith
+}
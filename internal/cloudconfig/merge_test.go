@@ -0,0 +1,270 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMergeType(t *testing.T) {
+	testCases := map[string]struct {
+		mergeType string
+		expected  []Merger
+	}{
+		"empty": {
+			mergeType: "",
+		},
+		"default": {
+			mergeType: DefaultMergeType,
+			expected:  []Merger{{Name: "dict", Options: []string{"replace"}}, {Name: "list"}, {Name: "str"}},
+		},
+		"options": {
+			mergeType: "list(append)+dict(no_replace,recurse_list)+str()",
+			expected: []Merger{
+				{Name: "list", Options: []string{"append"}},
+				{Name: "dict", Options: []string{"no_replace", "recurse_list"}},
+				{Name: "str"},
+			},
+		},
+		"canonicalized": {
+			mergeType: " List(Append) + dict(no-replace, recurse_array) ",
+			expected: []Merger{
+				{Name: "list", Options: []string{"append"}},
+				{Name: "dict", Options: []string{"no_replace", "recurse_array"}},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseMergeType(tc.mergeType)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseMergeType_invalid(t *testing.T) {
+	_, err := ParseMergeType("list(append)+dict")
+	if err == nil || err.Error() != `merger "dict" is not in the format name(option,...)` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMergerValidate(t *testing.T) {
+	testCases := map[string]struct {
+		merger   Merger
+		expected string
+	}{
+		"valid": {
+			merger: Merger{Name: "dict", Options: []string{"no_replace", "recurse_list"}},
+		},
+		"no options": {
+			merger: Merger{Name: "str"},
+		},
+		"misspelled merger": {
+			merger:   Merger{Name: "lst"},
+			expected: `unknown merger "lst", did you mean "list"?`,
+		},
+		"unknown merger": {
+			merger:   Merger{Name: "yaml"},
+			expected: `unknown merger "yaml", expected one of: dict, list, str`,
+		},
+		"misspelled option": {
+			merger:   Merger{Name: "list", Options: []string{"apend"}},
+			expected: `unknown option "apend" for the list merger, did you mean "append"?`,
+		},
+		"option of another merger": {
+			merger:   Merger{Name: "str", Options: []string{"prepend"}},
+			expected: `unknown option "prepend" for the str merger, expected any of: append`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.merger.Validate()
+
+			var actual string
+			if err != nil {
+				actual = err.Error()
+			}
+
+			if actual != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestInlineMergers(t *testing.T) {
+	testCases := map[string]struct {
+		content  string
+		expected []Merger
+	}{
+		"none": {
+			content: "#cloud-config\npackages: [git]\n",
+		},
+		"string": {
+			content:  "#cloud-config\nmerge_how: list(append)+dict()\n",
+			expected: []Merger{{Name: "list", Options: []string{"append"}}, {Name: "dict"}},
+		},
+		"objects": {
+			content:  "#cloud-config\nmerge_how:\n  - name: list\n    settings: [append]\n  - name: dict\n    settings: [no_replace, recurse_list]\n",
+			expected: []Merger{{Name: "list", Options: []string{"append"}}, {Name: "dict", Options: []string{"no_replace", "recurse_list"}}},
+		},
+		"lists": {
+			content:  "#cloud-config\nmerge_type:\n  - [list, append]\n  - [str]\n",
+			expected: []Merger{{Name: "list", Options: []string{"append"}}, {Name: "str"}},
+		},
+		"merge_how takes precedence over merge_type": {
+			content:  "#cloud-config\nmerge_how: str(append)\nmerge_type: list(append)\n",
+			expected: []Merger{{Name: "str", Options: []string{"append"}}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := InlineMergers(tc.content)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %#v, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	testCases := map[string]struct {
+		parts    []Part
+		expected string
+	}{
+		"single part": {
+			parts: []Part{
+				{Content: "#cloud-config\npackages: [git]\n"},
+			},
+			expected: "#cloud-config\npackages:\n  - git\n",
+		},
+		"default merge type": {
+			parts: []Part{
+				{Content: "#cloud-config\nhostname: a\npackages: [git, vim]\nntp:\n  enabled: true\n"},
+				{Content: "#cloud-config\nhostname: b\npackages: [curl]\nntp:\n  servers: [time.example.com]\nruncmd: [echo]\n"},
+			},
+			expected: "#cloud-config\nhostname: b\nntp:\n  servers:\n    - time.example.com\npackages:\n  - curl\nruncmd:\n  - echo\n",
+		},
+		"list append": {
+			parts: []Part{
+				{Content: "#cloud-config\npackages: [git]\n"},
+				{Content: "#cloud-config\npackages: [curl]\n", MergeType: "list(append)+dict(recurse_array)+str()"},
+			},
+			expected: "#cloud-config\npackages:\n  - git\n  - curl\n",
+		},
+		"list prepend": {
+			parts: []Part{
+				{Content: "#cloud-config\npackages: [git]\n"},
+				{Content: "#cloud-config\npackages: [curl]\n", MergeType: "list(prepend)+dict(recurse_array)"},
+			},
+			expected: "#cloud-config\npackages:\n  - curl\n  - git\n",
+		},
+		"list replace keeps trailing elements": {
+			parts: []Part{
+				{Content: "#cloud-config\npackages: [git, vim]\n"},
+				{Content: "#cloud-config\npackages: [curl]\n", MergeType: "list(replace)+dict(recurse_array)"},
+			},
+			expected: "#cloud-config\npackages:\n  - curl\n  - vim\n",
+		},
+		"dict replace": {
+			parts: []Part{
+				{Content: "#cloud-config\nhostname: a\nntp:\n  enabled: true\n"},
+				{Content: "#cloud-config\nhostname: b\nntp:\n  servers: [time.example.com]\n", MergeType: "dict(replace)"},
+			},
+			expected: "#cloud-config\nhostname: b\nntp:\n  servers:\n    - time.example.com\n",
+		},
+		"dict allow_delete": {
+			parts: []Part{
+				{Content: "#cloud-config\nhostname: a\nmanage_etc_hosts: true\n"},
+				{Content: "#cloud-config\nmanage_etc_hosts: null\n", MergeType: "dict(allow_delete)"},
+			},
+			expected: "#cloud-config\nhostname: a\n",
+		},
+		"str append": {
+			parts: []Part{
+				{Content: "#cloud-config\nbootcmd:\n  - echo a\n"},
+				{Content: "#cloud-config\nbootcmd:\n  - ' b'\n", MergeType: "dict(recurse_array)+list(recurse_str)+str(append)"},
+			},
+			expected: "#cloud-config\nbootcmd:\n  - echo a b\n",
+		},
+		"inline merge_how takes precedence": {
+			parts: []Part{
+				{Content: "#cloud-config\npackages: [git]\n"},
+				{Content: "#cloud-config\nmerge_how:\n  - name: list\n    settings: [append]\n  - name: dict\n    settings: [recurse_array]\npackages: [curl]\n", MergeType: "list(prepend)+dict(recurse_array)"},
+			},
+			expected: "#cloud-config\nmerge_how:\n  - name: list\n    settings:\n      - append\n  - name: dict\n    settings:\n      - recurse_array\npackages:\n  - git\n  - curl\n",
+		},
+		"inline merge_type string": {
+			parts: []Part{
+				{Content: "#cloud-config\npackages: [git]\n"},
+				{Content: "#cloud-config\nmerge_type: list(append)+dict(recurse_array)\npackages: [curl]\n"},
+			},
+			expected: "#cloud-config\nmerge_type: list(append)+dict(recurse_array)\npackages:\n  - git\n  - curl\n",
+		},
+		"empty part": {
+			parts: []Part{
+				{Content: "#cloud-config\npackages: [git]\n"},
+				{Content: "#cloud-config\n"},
+			},
+			expected: "#cloud-config\npackages:\n  - git\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Merge(tc.parts)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(actual) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMerge_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		parts    []Part
+		expected string
+	}{
+		"not a mapping": {
+			parts:    []Part{{Content: "#cloud-config\n- git\n"}},
+			expected: "part 0: expected a cloud-config mapping, got array",
+		},
+		"jinja template": {
+			parts:    []Part{{Content: "## template: jinja\n#cloud-config\nhostname: {{ v1.local_hostname }}\n"}},
+			expected: "part 0: Jinja templates are only rendered on boot, and cannot be merged",
+		},
+		"unknown merger": {
+			parts:    []Part{{Content: "#cloud-config\n", MergeType: "lists(append)"}},
+			expected: `part 0: unknown merger "lists", did you mean "list"?`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Merge(tc.parts)
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// InstanceIDKey is the meta-data key cloud-init compares on boot to decide
+	// whether an instance is new and per-instance modules run again.
+	InstanceIDKey = "instance-id"
+
+	// LocalHostnameKey is the meta-data key of the hostname of the instance.
+	LocalHostnameKey = "local-hostname"
+)
+
+// MetaData is the meta-data of an instance, such as the content of the
+// meta-data file of a NoCloud seed.
+type MetaData struct {
+	InstanceID    string
+	LocalHostname string
+
+	// Extra holds additional keys as a JSON object, such as the output of
+	// Terraform's jsonencode, or nothing.
+	Extra []byte
+}
+
+// hostnameLabel matches a label of a hostname, as RFC 1123 allows.
+var hostnameLabel = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]{0,61}[A-Za-z0-9])?$`)
+
+// ValidateHostname checks that hostname is a valid hostname, such as "web-01"
+// or "web-01.example.com".
+func ValidateHostname(hostname string) error {
+	if len(hostname) > 253 {
+		return fmt.Errorf("expected at most 253 characters")
+	}
+
+	for _, label := range strings.Split(hostname, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return fmt.Errorf("expected labels of at most 63 letters, digits and hyphens, separated by dots, which do not start or end with a hyphen")
+		}
+	}
+
+	return nil
+}
+
+// MarshalMetaData writes meta-data as a YAML document, with instance-id and
+// local-hostname first and the extra keys after them, sorted.
+func MarshalMetaData(metaData MetaData) ([]byte, error) {
+	document := &yaml.Node{Kind: yaml.MappingNode}
+
+	addString := func(key string, value string) {
+		document.Content = append(document.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value},
+		)
+	}
+
+	addString(InstanceIDKey, metaData.InstanceID)
+
+	if metaData.LocalHostname != "" {
+		addString(LocalHostnameKey, metaData.LocalHostname)
+	}
+
+	if len(metaData.Extra) > 0 {
+		extra, err := decodeJSONObject(metaData.Extra)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range []string{InstanceIDKey, LocalHostnameKey} {
+			if _, ok := extra[key]; ok {
+				return nil, fmt.Errorf("unexpected key %q in the extra keys", key)
+			}
+		}
+
+		extraNode := &yaml.Node{}
+		if err := extraNode.Encode(extra); err != nil {
+			return nil, err
+		}
+
+		document.Content = append(document.Content, extraNode.Content...)
+	}
+
+	return marshal("", document)
+}
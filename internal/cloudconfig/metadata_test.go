@@ -0,0 +1,103 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarshalMetaData(t *testing.T) {
+	testCases := map[string]struct {
+		metaData MetaData
+		expected string
+	}{
+		"instance-id": {
+			metaData: MetaData{InstanceID: "iid-local01"},
+			expected: "instance-id: iid-local01\n",
+		},
+		"local-hostname": {
+			metaData: MetaData{InstanceID: "iid-local01", LocalHostname: "web-01"},
+			expected: "instance-id: iid-local01\nlocal-hostname: web-01\n",
+		},
+		"ambiguous values are quoted": {
+			metaData: MetaData{InstanceID: "12345", LocalHostname: "true"},
+			expected: "instance-id: \"12345\"\nlocal-hostname: \"true\"\n",
+		},
+		"extra keys": {
+			metaData: MetaData{
+				InstanceID: "iid-local01",
+				Extra:      []byte(`{"public-keys":["ssh-ed25519 AAAA"],"availability-zone":"zone-a","launch-index":0}`),
+			},
+			expected: "instance-id: iid-local01\navailability-zone: zone-a\nlaunch-index: 0\npublic-keys:\n  - ssh-ed25519 AAAA\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := MarshalMetaData(tc.metaData)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(actual) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMarshalMetaData_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		metaData MetaData
+		expected string
+	}{
+		"extra keys not an object": {
+			metaData: MetaData{InstanceID: "iid-local01", Extra: []byte(`["zone-a"]`)},
+			expected: "expected a JSON object, got array",
+		},
+		"extra instance-id": {
+			metaData: MetaData{InstanceID: "iid-local01", Extra: []byte(`{"instance-id":"iid-local02"}`)},
+			expected: `unexpected key "instance-id" in the extra keys`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := MarshalMetaData(tc.metaData)
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateHostname(t *testing.T) {
+	testCases := map[string]bool{
+		"web-01":                        true,
+		"web-01.example.com":            true,
+		"1.example.com":                 true,
+		strings.Repeat("a", 63):         true,
+		strings.Repeat("a.", 126) + "a": true,
+		"":                              false,
+		"-web":                          false,
+		"web-":                          false,
+		"web_01":                        false,
+		"web..example.com":              false,
+		strings.Repeat("a", 64):         false,
+		strings.Repeat("a.", 127) + "a": false,
+	}
+
+	for hostname, valid := range testCases {
+		t.Run(hostname, func(t *testing.T) {
+			if err := ValidateHostname(hostname); (err == nil) != valid {
+				t.Errorf("expected valid %t for %q, got %v", valid, hostname, err)
+			}
+		})
+	}
+}
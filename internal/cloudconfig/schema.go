@@ -0,0 +1,236 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed schemas/cloud-config-*.json
+var schemaFiles embed.FS
+
+// LatestVersion is the most recent cloud-init release with a bundled schema.
+const LatestVersion = "24.4"
+
+var (
+	schemaCache   = map[string]*schema{}
+	schemaCacheMu sync.Mutex
+)
+
+// Versions returns the cloud-init releases with a bundled schema, oldest first.
+func Versions() []string {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		return nil
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		version := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "cloud-config-"), ".json")
+		versions = append(versions, version)
+	}
+
+	sort.Strings(versions)
+
+	return versions
+}
+
+// schema is a JSON Schema (draft 2020-12) with cloud-init's own annotation
+// and deprecation keywords. Schemas with keywords not listed here fail to
+// load, rather than being validated as if those keywords were not there.
+type schema struct {
+	Ref                  string              `json:"$ref"`
+	Defs                 map[string]*schema  `json:"$defs"`
+	Type                 schemaType          `json:"type"`
+	Enum                 []any               `json:"enum"`
+	Const                json.RawMessage     `json:"const"`
+	AllOf                []*schema           `json:"allOf"`
+	OneOf                []*schema           `json:"oneOf"`
+	AnyOf                []*schema           `json:"anyOf"`
+	Properties           map[string]*schema  `json:"properties"`
+	PatternProperties    map[string]*schema  `json:"patternProperties"`
+	AdditionalProperties *schemaOrBool       `json:"additionalProperties"`
+	Required             []string            `json:"required"`
+	DependentRequired    map[string][]string `json:"dependentRequired"`
+	MinProperties        *int                `json:"minProperties"`
+	MaxProperties        *int                `json:"maxProperties"`
+	Items                *schema             `json:"items"`
+	MinItems             *int                `json:"minItems"`
+	MaxItems             *int                `json:"maxItems"`
+	UniqueItems          bool                `json:"uniqueItems"`
+	Pattern              string              `json:"pattern"`
+	MinLength            *int                `json:"minLength"`
+	MaxLength            *int                `json:"maxLength"`
+	Minimum              *float64            `json:"minimum"`
+	Maximum              *float64            `json:"maximum"`
+	ExclusiveMinimum     *float64            `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *float64            `json:"exclusiveMaximum"`
+
+	Deprecated            bool   `json:"deprecated"`
+	DeprecatedVersion     string `json:"deprecated_version"`
+	DeprecatedDescription string `json:"deprecated_description"`
+
+	// Annotations, which do not affect validation. format is an annotation
+	// by default in draft 2020-12.
+	Schema              string          `json:"$schema"`
+	ID                  string          `json:"$id"`
+	Comment             string          `json:"$comment"`
+	Title               string          `json:"title"`
+	Description         string          `json:"description"`
+	MarkdownDescription string          `json:"markdownDescription"`
+	Default             json.RawMessage `json:"default"`
+	Examples            json.RawMessage `json:"examples"`
+	Format              string          `json:"format"`
+	Changed             bool            `json:"changed"`
+	ChangedVersion      string          `json:"changed_version"`
+	ChangedDescription  string          `json:"changed_description"`
+	New                 bool            `json:"new"`
+	NewVersion          string          `json:"new_version"`
+	NewDescription      string          `json:"new_description"`
+
+	patterns  map[string]*regexp.Regexp
+	patternRE *regexp.Regexp
+}
+
+// schemaType is the "type" keyword, which may be a single type name or a list.
+type schemaType []string
+
+func (t *schemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaType{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*t = multiple
+
+	return nil
+}
+
+// schemaOrBool is the "additionalProperties" keyword, which may be a boolean
+// or a schema.
+type schemaOrBool struct {
+	Allowed bool
+	Schema  *schema
+}
+
+func (s *schemaOrBool) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &s.Allowed); err == nil {
+		return nil
+	}
+
+	s.Allowed = true
+
+	return decodeSchema(data, &s.Schema)
+}
+
+// decodeSchema decodes a schema, failing on keywords it does not support.
+func decodeSchema(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(v)
+}
+
+func loadSchema(version string) (*schema, error) {
+	schemaCacheMu.Lock()
+	defer schemaCacheMu.Unlock()
+
+	if s, ok := schemaCache[version]; ok {
+		return s, nil
+	}
+
+	data, err := schemaFiles.ReadFile(fmt.Sprintf("schemas/cloud-config-%s.json", version))
+	if err != nil {
+		return nil, fmt.Errorf("no cloud-config schema for cloud-init version %q, expected one of: %s", version, strings.Join(Versions(), ", "))
+	}
+
+	s, err := parseSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cloud-config schema for cloud-init version %q: %w", version, err)
+	}
+
+	schemaCache[version] = s
+
+	return s, nil
+}
+
+func parseSchema(data []byte) (*schema, error) {
+	var s schema
+	if err := decodeSchema(data, &s); err != nil {
+		return nil, err
+	}
+
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// compile prepares the regular expressions of all pattern and
+// patternProperties keywords.
+func (s *schema) compile() error {
+	if s == nil {
+		return nil
+	}
+
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return err
+		}
+
+		s.patternRE = re
+	}
+
+	for pattern := range s.PatternProperties {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+
+		if s.patterns == nil {
+			s.patterns = map[string]*regexp.Regexp{}
+		}
+		s.patterns[pattern] = re
+	}
+
+	children := []*schema{s.Items}
+	children = append(children, s.AllOf...)
+	children = append(children, s.OneOf...)
+	children = append(children, s.AnyOf...)
+	for _, child := range s.Defs {
+		children = append(children, child)
+	}
+	for _, child := range s.Properties {
+		children = append(children, child)
+	}
+	for _, child := range s.PatternProperties {
+		children = append(children, child)
+	}
+	if s.AdditionalProperties != nil {
+		children = append(children, s.AdditionalProperties.Schema)
+	}
+
+	for _, child := range children {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
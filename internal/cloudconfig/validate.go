@@ -0,0 +1,553 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/agext/levenshtein"
+	"go.yaml.in/yaml/v3"
+)
+
+// DiagnosticKind is the kind of problem a Diagnostic describes.
+type DiagnosticKind int
+
+const (
+	// DiagnosticInvalid is a value which does not match the schema, or a
+	// document which is not valid YAML.
+	DiagnosticInvalid DiagnosticKind = iota
+
+	// DiagnosticUnknownKey is a key the bundled schema has no property for.
+	// The bundled schemas only cover the most common modules, so cloud-init
+	// may still handle the key.
+	DiagnosticUnknownKey
+
+	// DiagnosticDeprecated is a key cloud-init has deprecated.
+	DiagnosticDeprecated
+)
+
+// Diagnostic describes a problem found while validating a cloud-config
+// document. Line and Column are 1-based positions in the document, or zero
+// when the position is unknown.
+type Diagnostic struct {
+	Kind    DiagnosticKind
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return d.Message
+	}
+
+	return fmt.Sprintf("line %d, column %d: %s", d.Line, d.Column, d.Message)
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+):`)
+
+// Validate checks a cloud-config document against the bundled schema of the
+// given cloud-init version. An error is only returned when no schema exists
+// for the version, problems with the document itself are returned as
+// diagnostics.
+func Validate(content string, version string) ([]Diagnostic, error) {
+	s, err := loadSchema(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return validateDocument(s, content), nil
+}
+
+func validateDocument(s *schema, content string) []Diagnostic {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+		diagnostic := Diagnostic{
+			Message: strings.TrimPrefix(err.Error(), "yaml: "),
+		}
+
+		if match := yamlErrorLine.FindStringSubmatch(diagnostic.Message); match != nil {
+			diagnostic.Line, _ = strconv.Atoi(match[1])
+			diagnostic.Column = 1
+			diagnostic.Message = strings.TrimPrefix(diagnostic.Message, match[0]+" ")
+		}
+
+		return []Diagnostic{diagnostic}
+	}
+
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	if nodeType(root) == "null" {
+		return nil
+	}
+
+	v := &validator{root: s}
+
+	return v.validate(s, root, "")
+}
+
+type validator struct {
+	root *schema
+}
+
+func (v *validator) resolve(s *schema) *schema {
+	for s != nil && s.Ref != "" {
+		s = v.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+	}
+
+	return s
+}
+
+func (v *validator) validate(s *schema, node *yaml.Node, path string) []Diagnostic {
+	s = v.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	var diags []Diagnostic
+
+	for _, required := range s.AllOf {
+		diags = append(diags, v.validate(required, node, path)...)
+	}
+
+	if len(s.OneOf) > 0 {
+		diags = append(diags, v.validateAlternatives(s.OneOf, node, path)...)
+	}
+
+	if len(s.AnyOf) > 0 {
+		diags = append(diags, v.validateAlternatives(s.AnyOf, node, path)...)
+	}
+
+	actual := nodeType(node)
+
+	if len(s.Type) > 0 && !typeMatches(s.Type, actual) {
+		return append(diags, errorAt(node, path, fmt.Sprintf("expected %s, got %s", strings.Join(s.Type, " or "), actual)))
+	}
+
+	if len(s.Const) > 0 && !constMatches(s.Const, node) {
+		return append(diags, errorAt(node, path, fmt.Sprintf("expected %s", s.Const)))
+	}
+
+	if len(s.Enum) > 0 && !enumMatches(s.Enum, node) {
+		allowed := make([]string, 0, len(s.Enum))
+		for _, value := range s.Enum {
+			allowed = append(allowed, fmt.Sprint(value))
+		}
+
+		return append(diags, errorAt(node, path, fmt.Sprintf("%q is not one of [%s]", node.Value, strings.Join(allowed, ", "))))
+	}
+
+	switch actual {
+	case "object":
+		diags = append(diags, v.validateObject(s, node, path)...)
+	case "array":
+		diags = append(diags, v.validateArray(s, node, path)...)
+	case "string":
+		diags = append(diags, validateString(s, node, path)...)
+	case "integer", "number":
+		diags = append(diags, validateNumber(s, node, path)...)
+	}
+
+	return diags
+}
+
+// validateAlternatives validates oneOf and anyOf. Both are treated as "any
+// of", since the alternatives in the cloud-init schema do not overlap.
+// Alternatives whose only problems are unknown keys are preferred over those
+// with invalid values, as the bundled schemas do not have every key.
+func (v *validator) validateAlternatives(alternatives []*schema, node *yaml.Node, path string) []Diagnostic {
+	var candidates [][]Diagnostic
+	var unknownKeys []Diagnostic
+	var expected []string
+
+	for _, alternative := range alternatives {
+		diags := v.validate(alternative, node, path)
+		if !hasKind(diags, DiagnosticInvalid) {
+			if !hasKind(diags, DiagnosticUnknownKey) {
+				return diags
+			}
+
+			if unknownKeys == nil {
+				unknownKeys = diags
+			}
+			continue
+		}
+
+		alternative = v.resolve(alternative)
+		if alternative != nil && len(alternative.Type) > 0 {
+			if typeMatches(alternative.Type, nodeType(node)) {
+				candidates = append(candidates, diags)
+			}
+			expected = append(expected, alternative.Type...)
+		}
+	}
+
+	if unknownKeys != nil {
+		return unknownKeys
+	}
+
+	// When only one of the alternatives has the right type, its problems are
+	// more useful than a summary of all alternatives.
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	if len(expected) == 0 {
+		return []Diagnostic{errorAt(node, path, "does not match any of the allowed values")}
+	}
+
+	return []Diagnostic{errorAt(node, path, fmt.Sprintf("expected %s, got %s", strings.Join(expected, " or "), nodeType(node)))}
+}
+
+func (v *validator) validateObject(s *schema, node *yaml.Node, path string) []Diagnostic {
+	var diags []Diagnostic
+
+	seen := map[string]bool{}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		// YAML merge keys are resolved by the parser on boot.
+		if key.Value == "<<" && key.ShortTag() == "!!merge" {
+			continue
+		}
+
+		seen[key.Value] = true
+		childPath := joinPath(path, key.Value)
+
+		child, ok := s.Properties[key.Value]
+		if !ok {
+			for pattern, re := range s.patterns {
+				if re.MatchString(key.Value) {
+					child, ok = s.PatternProperties[pattern], true
+					break
+				}
+			}
+		}
+
+		if !ok && s.AdditionalProperties != nil {
+			if !s.AdditionalProperties.Allowed {
+				message := fmt.Sprintf("unknown key %q", key.Value)
+				if suggestion := closestProperty(key.Value, s.Properties); suggestion != "" {
+					message += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+
+				diags = append(diags, diagnosticAt(DiagnosticUnknownKey, key, path, message))
+				continue
+			}
+
+			child = s.AdditionalProperties.Schema
+		}
+
+		if resolved := v.resolve(child); resolved != nil && resolved.Deprecated {
+			message := "deprecated"
+			if resolved.DeprecatedVersion != "" {
+				message += " in version " + resolved.DeprecatedVersion
+			}
+			message += "."
+			if resolved.DeprecatedDescription != "" {
+				message += " " + resolved.DeprecatedDescription
+			}
+
+			diags = append(diags, diagnosticAt(DiagnosticDeprecated, key, childPath, message))
+		}
+
+		diags = append(diags, v.validate(child, value, childPath)...)
+	}
+
+	for _, required := range s.Required {
+		if !seen[required] {
+			diags = append(diags, errorAt(node, path, fmt.Sprintf("missing required property %q", required)))
+		}
+	}
+
+	for property, dependencies := range s.DependentRequired {
+		if !seen[property] {
+			continue
+		}
+
+		for _, required := range dependencies {
+			if !seen[required] {
+				diags = append(diags, errorAt(node, path, fmt.Sprintf("missing property %q, which %q requires", required, property)))
+			}
+		}
+	}
+
+	properties := len(node.Content) / 2
+
+	if s.MinProperties != nil && properties < *s.MinProperties {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at least %d properties, got %d", *s.MinProperties, properties)))
+	}
+
+	if s.MaxProperties != nil && properties > *s.MaxProperties {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at most %d properties, got %d", *s.MaxProperties, properties)))
+	}
+
+	return diags
+}
+
+func (v *validator) validateArray(s *schema, node *yaml.Node, path string) []Diagnostic {
+	var diags []Diagnostic
+
+	if s.MinItems != nil && len(node.Content) < *s.MinItems {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at least %d items, got %d", *s.MinItems, len(node.Content))))
+	}
+
+	if s.MaxItems != nil && len(node.Content) > *s.MaxItems {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at most %d items, got %d", *s.MaxItems, len(node.Content))))
+	}
+
+	if s.UniqueItems {
+		values := make([]any, len(node.Content))
+		for i, item := range node.Content {
+			values[i], _ = nodeValue(item)
+		}
+
+	unique:
+		for i := range values {
+			for j := range i {
+				if reflect.DeepEqual(values[i], values[j]) {
+					diags = append(diags, errorAt(node.Content[i], path, fmt.Sprintf("expected unique items, item %d repeats item %d", i, j)))
+					break unique
+				}
+			}
+		}
+	}
+
+	if s.Items == nil {
+		return diags
+	}
+
+	for i, item := range node.Content {
+		diags = append(diags, v.validate(s.Items, item, fmt.Sprintf("%s.%d", path, i))...)
+	}
+
+	return diags
+}
+
+func validateString(s *schema, node *yaml.Node, path string) []Diagnostic {
+	var diags []Diagnostic
+
+	length := utf8.RuneCountInString(node.Value)
+
+	if s.MinLength != nil && length < *s.MinLength {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at least %d characters, got %d", *s.MinLength, length)))
+	}
+
+	if s.MaxLength != nil && length > *s.MaxLength {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at most %d characters, got %d", *s.MaxLength, length)))
+	}
+
+	if s.patternRE != nil && !s.patternRE.MatchString(node.Value) {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("%q does not match the pattern %q", node.Value, s.Pattern)))
+	}
+
+	return diags
+}
+
+func validateNumber(s *schema, node *yaml.Node, path string) []Diagnostic {
+	var diags []Diagnostic
+
+	var value float64
+	if err := node.Decode(&value); err != nil {
+		return diags
+	}
+
+	if s.Minimum != nil && value < *s.Minimum {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at least %s, got %s", formatNumber(*s.Minimum), node.Value)))
+	}
+
+	if s.Maximum != nil && value > *s.Maximum {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected at most %s, got %s", formatNumber(*s.Maximum), node.Value)))
+	}
+
+	if s.ExclusiveMinimum != nil && value <= *s.ExclusiveMinimum {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected more than %s, got %s", formatNumber(*s.ExclusiveMinimum), node.Value)))
+	}
+
+	if s.ExclusiveMaximum != nil && value >= *s.ExclusiveMaximum {
+		diags = append(diags, errorAt(node, path, fmt.Sprintf("expected less than %s, got %s", formatNumber(*s.ExclusiveMaximum), node.Value)))
+	}
+
+	return diags
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func errorAt(node *yaml.Node, path string, message string) Diagnostic {
+	return diagnosticAt(DiagnosticInvalid, node, path, message)
+}
+
+func diagnosticAt(kind DiagnosticKind, node *yaml.Node, path string, message string) Diagnostic {
+	if path != "" {
+		message = path + ": " + message
+	}
+
+	return Diagnostic{
+		Kind:    kind,
+		Path:    path,
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: message,
+	}
+}
+
+func hasKind(diags []Diagnostic, kind DiagnosticKind) bool {
+	for _, d := range diags {
+		if d.Kind == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// nodeType returns the JSON Schema type name of a YAML node.
+func nodeType(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			return nodeType(node.Alias)
+		}
+	}
+
+	switch node.ShortTag() {
+	case "!!int":
+		return "integer"
+	case "!!float":
+		return "number"
+	case "!!bool":
+		return "boolean"
+	case "!!null":
+		return "null"
+	}
+
+	return "string"
+}
+
+func typeMatches(expected []string, actual string) bool {
+	for _, t := range expected {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func enumMatches(enum []any, node *yaml.Node) bool {
+	value, ok := nodeValue(node)
+	if !ok {
+		return false
+	}
+
+	for _, allowed := range enum {
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func constMatches(constant json.RawMessage, node *yaml.Node) bool {
+	var expected any
+	if err := json.Unmarshal(constant, &expected); err != nil {
+		return false
+	}
+
+	value, ok := nodeValue(node)
+
+	return ok && reflect.DeepEqual(expected, value)
+}
+
+// nodeValue decodes a YAML node into the types values decode into from JSON,
+// so that they can be compared with the values of a schema.
+func nodeValue(node *yaml.Node) (any, bool) {
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	return jsonValue(value), true
+}
+
+func jsonValue(value any) any {
+	switch value := value.(type) {
+	case int:
+		return float64(value)
+	case int64:
+		return float64(value)
+	case uint64:
+		return float64(value)
+	case []any:
+		for i, item := range value {
+			value[i] = jsonValue(item)
+		}
+	case map[string]any:
+		for key, item := range value {
+			value[key] = jsonValue(item)
+		}
+	}
+
+	return value
+}
+
+// closestProperty returns the known property closest to name, to suggest when
+// name is likely a typo, or an empty string if none are close enough.
+func closestProperty(name string, properties map[string]*schema) string {
+	candidates := make([]string, 0, len(properties))
+	for property := range properties {
+		candidates = append(candidates, property)
+	}
+
+	return Suggest(name, candidates)
+}
+
+// Suggest returns the candidate closest to value, to suggest when value is
+// likely a typo, or an empty string if none are close enough.
+func Suggest(value string, candidates []string) string {
+	best, bestDistance := "", 0
+
+	for _, candidate := range candidates {
+		distance := levenshtein.Distance(value, candidate, nil)
+		if distance > 2 || distance > len(candidate)/2 {
+			continue
+		}
+
+		if best == "" || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,242 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package cloudconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := map[string]struct {
+		content  string
+		version  string
+		expected []Diagnostic
+	}{
+		"valid": {
+			content: "#cloud-config\npackage_update: true\npackages:\n  - git\n  - [libpython3.10, 3.10.12-1]\nruncmd:\n  - echo hello\n  - [ls, -l, /]\nwrite_files:\n  - path: /etc/motd\n    content: hello\n    permissions: '0644'\n",
+			version: LatestVersion,
+		},
+		"empty": {
+			content: "#cloud-config\n",
+			version: LatestVersion,
+		},
+		"unknown top-level key": {
+			content: "#cloud-config\nruncmds:\n  - echo hello\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Kind: DiagnosticUnknownKey, Line: 2, Column: 1, Message: `unknown key "runcmds", did you mean "runcmd"?`},
+			},
+		},
+		"unknown nested key": {
+			content: "#cloud-config\nwrite_files:\n  - path: /etc/motd\n    contents: hello\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Kind: DiagnosticUnknownKey, Path: "write_files.0", Line: 4, Column: 5, Message: `write_files.0: unknown key "contents", did you mean "content"?`},
+			},
+		},
+		"wrong type": {
+			content: "#cloud-config\npackage_update: \"yes\"\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Path: "package_update", Line: 2, Column: 17, Message: "package_update: expected boolean, got string"},
+			},
+		},
+		"missing required property": {
+			content: "#cloud-config\npower_state:\n  delay: now\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Path: "power_state", Line: 3, Column: 3, Message: `power_state: missing required property "mode"`},
+			},
+		},
+		"enum": {
+			content: "#cloud-config\npower_state:\n  mode: shutdown\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Path: "power_state.mode", Line: 3, Column: 9, Message: `power_state.mode: "shutdown" is not one of [poweroff, reboot, halt]`},
+			},
+		},
+		"alternatives": {
+			content: "#cloud-config\nruncmd:\n  - {echo: hello}\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Path: "runcmd.0", Line: 3, Column: 5, Message: "runcmd.0: expected string or array or null, got object"},
+			},
+		},
+		"deprecated": {
+			content: "#cloud-config\napt_update: true\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Kind: DiagnosticDeprecated, Path: "apt_update", Line: 2, Column: 1, Message: "apt_update: deprecated in version 22.2. Use `package_update` instead."},
+			},
+		},
+		"version specific": {
+			content: "#cloud-config\nubuntu_pro:\n  token: abc\n",
+			version: "22.4",
+			expected: []Diagnostic{
+				{Kind: DiagnosticUnknownKey, Line: 2, Column: 1, Message: `unknown key "ubuntu_pro"`},
+			},
+		},
+		"invalid yaml": {
+			content: "#cloud-config\npackages:\n  - git\nfoo: bar: baz\n",
+			version: LatestVersion,
+			expected: []Diagnostic{
+				{Line: 4, Column: 1, Message: "mapping values are not allowed in this context"},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Validate(tc.content, tc.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("bad: %#v\n\t%#v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestValidate_keywords(t *testing.T) {
+	s, err := parseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "pattern": "^[a-z]+$", "minLength": 2, "maxLength": 4},
+			"port": {"type": "integer", "minimum": 1, "exclusiveMaximum": 65536},
+			"ratio": {"type": "number", "exclusiveMinimum": 0, "maximum": 1},
+			"version": {"const": 1},
+			"tags": {"type": "array", "uniqueItems": true},
+			"labels": {"type": "object", "minProperties": 1, "maxProperties": 2},
+			"user": {
+				"type": "object",
+				"allOf": [{"required": ["name"]}, {"dependentRequired": {"password": ["hash"]}}]
+			}
+		},
+		"additionalProperties": false
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	testCases := map[string]struct {
+		content  string
+		expected []Diagnostic
+	}{
+		"valid": {
+			content: "name: abc\nport: 22\nratio: 0.5\nversion: 1\ntags: [a, b]\nlabels: {a: b}\nuser: {name: abc, password: x, hash: y}\n",
+		},
+		"pattern": {
+			content: "name: Abc\n",
+			expected: []Diagnostic{
+				{Path: "name", Line: 1, Column: 7, Message: `name: "Abc" does not match the pattern "^[a-z]+$"`},
+			},
+		},
+		"length": {
+			content: "name: abcde\n",
+			expected: []Diagnostic{
+				{Path: "name", Line: 1, Column: 7, Message: "name: expected at most 4 characters, got 5"},
+			},
+		},
+		"minimum": {
+			content: "port: 0\n",
+			expected: []Diagnostic{
+				{Path: "port", Line: 1, Column: 7, Message: "port: expected at least 1, got 0"},
+			},
+		},
+		"exclusive maximum": {
+			content: "port: 65536\n",
+			expected: []Diagnostic{
+				{Path: "port", Line: 1, Column: 7, Message: "port: expected less than 65536, got 65536"},
+			},
+		},
+		"exclusive minimum": {
+			content: "ratio: 0\n",
+			expected: []Diagnostic{
+				{Path: "ratio", Line: 1, Column: 8, Message: "ratio: expected more than 0, got 0"},
+			},
+		},
+		"const": {
+			content: "version: 2\n",
+			expected: []Diagnostic{
+				{Path: "version", Line: 1, Column: 10, Message: "version: expected 1"},
+			},
+		},
+		"unique items": {
+			content: "tags: [a, b, a]\n",
+			expected: []Diagnostic{
+				{Path: "tags", Line: 1, Column: 14, Message: "tags: expected unique items, item 2 repeats item 0"},
+			},
+		},
+		"max properties": {
+			content: "labels: {a: b, c: d, e: f}\n",
+			expected: []Diagnostic{
+				{Path: "labels", Line: 1, Column: 9, Message: "labels: expected at most 2 properties, got 3"},
+			},
+		},
+		"all of": {
+			content: "user: {password: x}\n",
+			expected: []Diagnostic{
+				{Path: "user", Line: 1, Column: 7, Message: `user: missing required property "name"`},
+				{Path: "user", Line: 1, Column: 7, Message: `user: missing property "hash", which "password" requires`},
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := validateDocument(s, tc.content)
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Fatalf("bad: %#v\n\t%#v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseSchema_unsupportedKeyword(t *testing.T) {
+	if _, err := parseSchema([]byte(`{"type": "string", "contentEncoding": "base64"}`)); err == nil {
+		t.Fatal("expected error for unsupported keyword")
+	}
+}
+
+func TestValidate_unknownVersion(t *testing.T) {
+	if _, err := Validate("#cloud-config\n", "1.0"); err == nil {
+		t.Fatal("expected error for unknown version")
+	}
+}
+
+func TestVersions(t *testing.T) {
+	versions := Versions()
+
+	if len(versions) == 0 || versions[len(versions)-1] != LatestVersion {
+		t.Fatalf("bad: %#v, expected %s to be the last version", versions, LatestVersion)
+	}
+
+	for _, version := range versions {
+		if _, err := loadSchema(version); err != nil {
+			t.Fatalf("unexpected error loading schema %s: %s", version, err)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"text/x-shellscript", "text/x-shellscript-per-boot", "text/cloud-config"}
+
+	testCases := map[string]string{
+		"text/x-shellscipt":  "text/x-shellscript",
+		"text/cloudconfig":   "text/cloud-config",
+		"text/cloud-config":  "text/cloud-config",
+		"application/x-tar":  "",
+		"text/x-include-url": "",
+	}
+
+	for value, expected := range testCases {
+		if actual := Suggest(value, candidates); actual != expected {
+			t.Fatalf("bad suggestion for %q: %#v\n\t%#v", value, actual, expected)
+		}
+	}
+}
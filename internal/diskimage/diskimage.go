@@ -0,0 +1,140 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package diskimage builds small read-only filesystem images, such as the
+// seed images cloud-init reads its configuration from, in pure Go. The images
+// are reproducible: the same files always give the same image, as timestamps
+// are fixed and nothing depends on the host.
+package diskimage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// File is a file of an image.
+type File struct {
+	// Path is the slash separated path of the file from the root directory of
+	// the image, such as "openstack/latest/meta_data.json". Parent directories
+	// are created as needed.
+	Path    string
+	Content []byte
+}
+
+// timestamp is the modification time of every file and directory, so that
+// images do not change between runs. 1980 is the earliest year FAT supports.
+var timestamp = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// node is a file or directory of the tree built from the files of an image.
+type node struct {
+	name     string
+	content  []byte
+	dir      bool
+	parent   *node
+	children []*node
+}
+
+// newTree returns the root directory of the tree holding files.
+func newTree(files []File) (*node, error) {
+	root := &node{dir: true}
+
+	for _, file := range files {
+		names := strings.Split(file.Path, "/")
+
+		dir := root
+		for i, name := range names {
+			if name == "" || name == "." || name == ".." {
+				return nil, fmt.Errorf("invalid path %q", file.Path)
+			}
+
+			child := dir.child(name)
+
+			if i == len(names)-1 {
+				if child != nil {
+					return nil, fmt.Errorf("duplicate path %q", file.Path)
+				}
+
+				dir.children = append(dir.children, &node{name: name, content: file.Content, parent: dir})
+				break
+			}
+
+			if child == nil {
+				child = &node{name: name, dir: true, parent: dir}
+				dir.children = append(dir.children, child)
+			} else if !child.dir {
+				return nil, fmt.Errorf("path %q is below the file %q", file.Path, strings.Join(names[:i+1], "/"))
+			}
+
+			dir = child
+		}
+	}
+
+	return root, nil
+}
+
+// child returns the child of the directory n with the given name, or nil.
+func (n *node) child(name string) *node {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// directories returns the directories of the tree below root, root included,
+// breadth first with the children of each directory in the order given by
+// less, which is the order of the ISO 9660 path table.
+func directories(root *node, less func(a, b *node) bool) []*node {
+	dirs := []*node{root}
+
+	for i := 0; i < len(dirs); i++ {
+		for _, child := range sortedChildren(dirs[i], less) {
+			if child.dir {
+				dirs = append(dirs, child)
+			}
+		}
+	}
+
+	return dirs
+}
+
+// sortedChildren returns the children of the directory n in the order given by
+// less.
+func sortedChildren(n *node, less func(a, b *node) bool) []*node {
+	children := append([]*node(nil), n.children...)
+	sort.SliceStable(children, func(i, j int) bool {
+		return less(children[i], children[j])
+	})
+
+	return children
+}
+
+// byName orders nodes by their names.
+func byName(a, b *node) bool {
+	return a.name < b.name
+}
+
+// files returns the files of the tree below root, depth first.
+func files(root *node) []*node {
+	var result []*node
+
+	for _, child := range root.children {
+		if child.dir {
+			result = append(result, files(child)...)
+		} else {
+			result = append(result, child)
+		}
+	}
+
+	return result
+}
+
+// sectors returns the number of sectors of the given size needed for size
+// bytes.
+func sectors(size, sectorSize int) int {
+	return (size + sectorSize - 1) / sectorSize
+}
@@ -0,0 +1,543 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package diskimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	isoSectorSize = 2048
+
+	// isoSystemAreaSectors is the number of sectors before the first volume
+	// descriptor, which are left empty.
+	isoSystemAreaSectors = 16
+
+	// isoMaxNameLength is the maximum length of a name, which is the limit of
+	// Joliet and keeps the Rock Ridge name within a directory record.
+	isoMaxNameLength = 64
+
+	// isoMaxLabelLength is the maximum length of the volume label, which is the
+	// limit of Joliet.
+	isoMaxLabelLength = 16
+
+	// Rock Ridge modes: files and directories are readable, but not writable,
+	// by everyone like genisoimage -r does.
+	isoFileMode      = 0o100444
+	isoDirectoryMode = 0o040555
+)
+
+// Rock Ridge extension reference, as written by genisoimage and xorriso. Linux
+// only uses the Rock Ridge names when it is present.
+const (
+	rockRidgeID         = "RRIP_1991A"
+	rockRidgeDescriptor = "THE ROCK RIDGE INTERCHANGE PROTOCOL PROVIDES SUPPORT FOR POSIX FILE SYSTEM SEMANTICS"
+	rockRidgeSource     = "PLEASE CONTACT DISC PUBLISHER FOR SPECIFICATION SOURCE.  SEE PUBLISHER IDENTIFIER IN PRIMARY VOLUME DESCRIPTOR FOR CONTACT INFORMATION."
+)
+
+// isoHierarchy is one of the two directory hierarchies of an image: the
+// primary hierarchy, with ISO 9660 names and the Rock Ridge names in the
+// system use fields, and the Joliet hierarchy with UCS-2 names.
+type isoHierarchy struct {
+	joliet bool
+
+	// dirs are the directories in path table order.
+	dirs    []*node
+	numbers map[*node]int
+
+	identifiers map[*node][]byte
+	extents     map[*node]int
+	sizes       map[*node]int
+
+	pathTableSize int
+	lPathTable    int
+	mPathTable    int
+}
+
+// isoWriter lays out and writes an ISO 9660 image.
+type isoWriter struct {
+	label string
+	root  *node
+
+	primary *isoHierarchy
+	joliet  *isoHierarchy
+
+	// continuation is the sector of the continuation area holding the Rock
+	// Ridge extension reference, which does not fit in the root record.
+	continuation int
+
+	// extents are the first sectors of the files.
+	extents map[*node]int
+	total   int
+}
+
+// ISO9660 returns an ISO 9660 image with the volume label label holding files.
+// Like images written by genisoimage -J -r, it has Joliet and Rock Ridge
+// extensions, so that the original names are read back by Windows and Linux,
+// and ISO 9660 names for systems which support neither.
+func ISO9660(label string, files []File) ([]byte, error) {
+	if len(label) > isoMaxLabelLength {
+		return nil, fmt.Errorf("volume label %q is longer than %d characters", label, isoMaxLabelLength)
+	}
+
+	root, err := newTree(files)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &isoWriter{
+		label:   label,
+		root:    root,
+		extents: make(map[*node]int),
+	}
+
+	if w.primary, err = w.hierarchy(false); err != nil {
+		return nil, err
+	}
+
+	if w.joliet, err = w.hierarchy(true); err != nil {
+		return nil, err
+	}
+
+	w.layout()
+
+	return w.write(), nil
+}
+
+// hierarchy returns the primary or Joliet hierarchy of the tree, with the
+// identifiers of its nodes set.
+func (w *isoWriter) hierarchy(joliet bool) (*isoHierarchy, error) {
+	h := &isoHierarchy{
+		joliet:      joliet,
+		numbers:     make(map[*node]int),
+		identifiers: map[*node][]byte{w.root: {0}},
+		extents:     make(map[*node]int),
+		sizes:       make(map[*node]int),
+	}
+
+	var queue []*node
+	queue = append(queue, w.root)
+
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+
+		taken := make(map[string]bool)
+		for _, child := range sortedChildren(dir, byName) {
+			if len([]rune(child.name)) > isoMaxNameLength {
+				return nil, fmt.Errorf("name %q is longer than %d characters", child.name, isoMaxNameLength)
+			}
+
+			identifier := isoIdentifier(child, joliet, taken)
+			taken[string(identifier)] = true
+			h.identifiers[child] = identifier
+
+			if child.dir {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	h.dirs = directories(w.root, h.less)
+	for i, dir := range h.dirs {
+		h.numbers[dir] = i + 1
+		h.pathTableSize += len(isoPathTableRecord(h.identifiers[dir], 0, 1, binary.LittleEndian))
+	}
+
+	return h, nil
+}
+
+// less orders the nodes of a directory by their identifiers.
+func (h *isoHierarchy) less(a, b *node) bool {
+	return bytes.Compare(h.identifiers[a], h.identifiers[b]) < 0
+}
+
+// isoIdentifier returns the identifier of n in the primary or Joliet
+// hierarchy which is not taken yet. ISO 9660 identifiers are limited to upper
+// case letters, digits and underscores, and files have an extension and a
+// version, so names which differ only in other characters or after the length
+// limit are told apart by a number at the end of the name.
+func isoIdentifier(n *node, joliet bool, taken map[string]bool) []byte {
+	if joliet {
+		var identifier []byte
+		for _, unit := range utf16.Encode([]rune(n.name)) {
+			identifier = binary.BigEndian.AppendUint16(identifier, unit)
+		}
+
+		return identifier
+	}
+
+	name, extension, length := n.name, "", 31
+	if !n.dir {
+		if i := strings.LastIndex(n.name, "."); i > 0 {
+			name, extension = n.name[:i], n.name[i+1:]
+		}
+
+		extension = truncate(isoDChars(extension), 30)
+		length = 30 - len(extension)
+	}
+
+	name = isoDChars(name)
+
+	for i := 0; ; i++ {
+		identifier := truncate(name, length)
+		if i > 0 {
+			suffix := strconv.Itoa(i)
+			identifier = truncate(name, length-len(suffix)) + suffix
+		}
+
+		if !n.dir {
+			identifier += "." + extension + ";1"
+		}
+
+		if !taken[identifier] {
+			return []byte(identifier)
+		}
+	}
+}
+
+// isoDChars returns s with lower case letters in upper case and any other
+// character which is not a d-character replaced by an underscore.
+func isoDChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		}
+
+		return '_'
+	}, s)
+}
+
+func truncate(s string, length int) string {
+	if len(s) > length {
+		return s[:length]
+	}
+
+	return s
+}
+
+// layout assigns the sectors of the volume descriptors, path tables,
+// directories and files.
+func (w *isoWriter) layout() {
+	// The primary and Joliet volume descriptors, and the terminator
+	next := isoSystemAreaSectors + 3
+
+	for _, h := range []*isoHierarchy{w.primary, w.joliet} {
+		h.lPathTable = next
+		next += sectors(h.pathTableSize, isoSectorSize)
+		h.mPathTable = next
+		next += sectors(h.pathTableSize, isoSectorSize)
+	}
+
+	for _, h := range []*isoHierarchy{w.primary, w.joliet} {
+		for _, dir := range h.dirs {
+			h.extents[dir] = next
+			h.sizes[dir] = len(w.directory(h, dir)) * isoSectorSize
+			next += len(w.directory(h, dir))
+		}
+	}
+
+	w.continuation = next
+	next++
+
+	for _, file := range files(w.root) {
+		if len(file.content) == 0 {
+			continue
+		}
+
+		w.extents[file] = next
+		next += sectors(len(file.content), isoSectorSize)
+	}
+
+	w.total = next
+}
+
+// write returns the image laid out by layout.
+func (w *isoWriter) write() []byte {
+	image := make([]byte, w.total*isoSectorSize)
+	sector := func(i int) []byte {
+		return image[i*isoSectorSize:]
+	}
+
+	w.volumeDescriptor(sector(isoSystemAreaSectors), w.primary)
+	w.volumeDescriptor(sector(isoSystemAreaSectors+1), w.joliet)
+
+	terminator := sector(isoSystemAreaSectors + 2)
+	terminator[0] = 255
+	copy(terminator[1:], "CD001")
+	terminator[6] = 1
+
+	for _, h := range []*isoHierarchy{w.primary, w.joliet} {
+		copy(sector(h.lPathTable), w.pathTable(h, binary.LittleEndian))
+		copy(sector(h.mPathTable), w.pathTable(h, binary.BigEndian))
+
+		for _, dir := range h.dirs {
+			for i, records := range w.directory(h, dir) {
+				copy(sector(h.extents[dir]+i), records)
+			}
+		}
+	}
+
+	copy(sector(w.continuation), rockRidgeExtensionReference())
+
+	for file, extent := range w.extents {
+		copy(sector(extent), file.content)
+	}
+
+	return image
+}
+
+// volumeDescriptor writes the primary or Joliet supplementary volume
+// descriptor of h to sector.
+func (w *isoWriter) volumeDescriptor(sector []byte, h *isoHierarchy) {
+	text := func(field []byte, value string) {
+		if !h.joliet {
+			copy(field, value+strings.Repeat(" ", len(field)))
+			return
+		}
+
+		var units []byte
+		for _, unit := range utf16.Encode([]rune(value + strings.Repeat(" ", len(field)/2))) {
+			units = binary.BigEndian.AppendUint16(units, unit)
+		}
+
+		copy(field, units)
+	}
+
+	sector[0] = 1
+	if h.joliet {
+		sector[0] = 2
+	}
+
+	copy(sector[1:], "CD001")
+	sector[6] = 1
+
+	text(sector[8:40], "")
+	text(sector[40:72], w.label)
+	bothEndian32(sector[80:], w.total)
+
+	if h.joliet {
+		// UCS-2 level 3
+		copy(sector[88:], "%/E")
+	}
+
+	bothEndian16(sector[120:], 1)
+	bothEndian16(sector[124:], 1)
+	bothEndian16(sector[128:], isoSectorSize)
+	bothEndian32(sector[132:], h.pathTableSize)
+	binary.LittleEndian.PutUint32(sector[140:], uint32(h.lPathTable))
+	binary.BigEndian.PutUint32(sector[148:], uint32(h.mPathTable))
+	copy(sector[156:190], isoRecord([]byte{0}, h.extents[w.root], h.sizes[w.root], true, nil))
+
+	// Volume set, publisher, data preparer, application, copyright, abstract
+	// and bibliographic identifiers
+	text(sector[190:813], "")
+
+	copy(sector[813:], isoVolumeDate(true))
+	copy(sector[830:], isoVolumeDate(true))
+	copy(sector[847:], isoVolumeDate(false))
+	copy(sector[864:], isoVolumeDate(false))
+	sector[881] = 1
+}
+
+// pathTable returns the path table of h in the given byte order.
+func (w *isoWriter) pathTable(h *isoHierarchy, order binary.ByteOrder) []byte {
+	var table []byte
+
+	for _, dir := range h.dirs {
+		parent := dir
+		if dir.parent != nil {
+			parent = dir.parent
+		}
+
+		table = append(table, isoPathTableRecord(h.identifiers[dir], h.extents[dir], h.numbers[parent], order)...)
+	}
+
+	return table
+}
+
+func isoPathTableRecord(identifier []byte, extent, parent int, order binary.ByteOrder) []byte {
+	record := make([]byte, 8, 8+len(identifier)+1)
+	record[0] = byte(len(identifier))
+	order.PutUint32(record[2:], uint32(extent))
+	order.PutUint16(record[6:], uint16(parent))
+	record = append(record, identifier...)
+
+	if len(identifier)%2 == 1 {
+		record = append(record, 0)
+	}
+
+	return record
+}
+
+// directory returns the sectors of the directory dir in h. Directory records
+// do not cross sectors, so each sector is filled with whole records.
+func (w *isoWriter) directory(h *isoHierarchy, dir *node) [][]byte {
+	parent := dir
+	if dir.parent != nil {
+		parent = dir.parent
+	}
+
+	records := [][]byte{
+		isoRecord([]byte{0}, h.extents[dir], h.sizes[dir], true, w.systemUse(h, dir, []byte{0})),
+		isoRecord([]byte{1}, h.extents[parent], h.sizes[parent], true, w.systemUse(h, parent, []byte{1})),
+	}
+
+	for _, child := range sortedChildren(dir, h.less) {
+		identifier := h.identifiers[child]
+		if child.dir {
+			records = append(records, isoRecord(identifier, h.extents[child], h.sizes[child], true, w.systemUse(h, child, identifier)))
+		} else {
+			records = append(records, isoRecord(identifier, w.extents[child], len(child.content), false, w.systemUse(h, child, identifier)))
+		}
+	}
+
+	var sectors [][]byte
+	var current []byte
+
+	for i, record := range records {
+		if i == 0 || len(current)+len(record) > isoSectorSize {
+			if i > 0 {
+				sectors = append(sectors, current)
+			}
+
+			current = nil
+		}
+
+		current = append(current, record...)
+	}
+
+	return append(sectors, current)
+}
+
+// systemUse returns the Rock Ridge entries of the record of n with the given
+// identifier in h, which hold the POSIX mode and the original name. The "."
+// record of the root directory also marks the use of Rock Ridge.
+func (w *isoWriter) systemUse(h *isoHierarchy, n *node, identifier []byte) []byte {
+	if h.joliet {
+		return nil
+	}
+
+	var entries []byte
+
+	// The "." and ".." records have the identifiers 0 and 1
+	dot := len(identifier) == 1 && identifier[0] <= 1
+
+	if dot && identifier[0] == 0 && n == w.root {
+		entries = append(entries, 'S', 'P', 7, 1, 0xbe, 0xef, 0)
+
+		ce := []byte{'C', 'E', 28, 1}
+		ce = appendBothEndian32(ce, w.continuation)
+		ce = appendBothEndian32(ce, 0)
+		ce = appendBothEndian32(ce, len(rockRidgeExtensionReference()))
+		entries = append(entries, ce...)
+	}
+
+	mode, links := isoFileMode, 1
+	if n.dir {
+		mode, links = isoDirectoryMode, 2
+		for _, child := range n.children {
+			if child.dir {
+				links++
+			}
+		}
+	}
+
+	px := []byte{'P', 'X', 36, 1}
+	px = appendBothEndian32(px, mode)
+	px = appendBothEndian32(px, links)
+	px = appendBothEndian32(px, 0)
+	px = appendBothEndian32(px, 0)
+	entries = append(entries, px...)
+
+	if !dot {
+		entries = append(entries, 'N', 'M', byte(5+len(n.name)), 1, 0)
+		entries = append(entries, n.name...)
+	}
+
+	return entries
+}
+
+func rockRidgeExtensionReference() []byte {
+	entry := []byte{'E', 'R', byte(8 + len(rockRidgeID) + len(rockRidgeDescriptor) + len(rockRidgeSource)), 1}
+	entry = append(entry, byte(len(rockRidgeID)), byte(len(rockRidgeDescriptor)), byte(len(rockRidgeSource)), 1)
+	entry = append(entry, rockRidgeID...)
+	entry = append(entry, rockRidgeDescriptor...)
+	entry = append(entry, rockRidgeSource...)
+
+	return entry
+}
+
+// isoRecord returns a directory record. Records have an even length, so the
+// identifier and the system use field are padded as needed.
+func isoRecord(identifier []byte, extent, size int, dir bool, systemUse []byte) []byte {
+	length := 33 + len(identifier)
+	if len(identifier)%2 == 0 {
+		length++
+	}
+
+	suOffset := length
+	length += len(systemUse)
+	if length%2 == 1 {
+		length++
+	}
+
+	record := make([]byte, length)
+	record[0] = byte(length)
+	bothEndian32(record[2:], extent)
+	bothEndian32(record[10:], size)
+
+	record[18] = byte(timestamp.Year() - 1900)
+	record[19] = byte(timestamp.Month())
+	record[20] = byte(timestamp.Day())
+	record[21] = byte(timestamp.Hour())
+	record[22] = byte(timestamp.Minute())
+	record[23] = byte(timestamp.Second())
+
+	if dir {
+		record[25] = 2
+	}
+
+	bothEndian16(record[28:], 1)
+	record[32] = byte(len(identifier))
+	copy(record[33:], identifier)
+	copy(record[suOffset:], systemUse)
+
+	return record
+}
+
+// isoVolumeDate returns a date of a volume descriptor, which is timestamp, or
+// the date for not specified when set is false.
+func isoVolumeDate(set bool) []byte {
+	if !set {
+		return append([]byte(strings.Repeat("0", 16)), 0)
+	}
+
+	return append([]byte(timestamp.Format("20060102150405")+"00"), 0)
+}
+
+// bothEndian32 writes v in little endian then big endian byte order, as ISO
+// 9660 does for most numbers.
+func bothEndian32(b []byte, v int) {
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	binary.BigEndian.PutUint32(b[4:], uint32(v))
+}
+
+func bothEndian16(b []byte, v int) {
+	binary.LittleEndian.PutUint16(b, uint16(v))
+	binary.BigEndian.PutUint16(b[2:], uint16(v))
+}
+
+func appendBothEndian32(b []byte, v int) []byte {
+	b = binary.LittleEndian.AppendUint32(b, uint32(v))
+	return binary.BigEndian.AppendUint32(b, uint32(v))
+}
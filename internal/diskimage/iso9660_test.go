@@ -0,0 +1,241 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package diskimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestISO9660(t *testing.T) {
+	files := []File{
+		{Path: "user-data", Content: []byte("#cloud-config\npackages:\n  - git\n")},
+		{Path: "meta-data", Content: []byte{}},
+		{Path: "network-config", Content: []byte("version: 2\n")},
+		{Path: "openstack/latest/meta_data.json", Content: []byte(`{"uuid": "d8e02d56"}`)},
+		{Path: "openstack/content/0000", Content: bytes.Repeat([]byte("0123456789"), 500)},
+	}
+
+	// Enough files for their directory to take several sectors
+	for i := 0; i < 100; i++ {
+		files = append(files, File{Path: fmt.Sprintf("many/file-%02d", i), Content: []byte{byte(i)}})
+	}
+
+	image, err := ISO9660("cidata", files)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(image)%isoSectorSize != 0 {
+		t.Errorf("expected the image size to be a multiple of %d, got %d", isoSectorSize, len(image))
+	}
+
+	primary := image[16*isoSectorSize:]
+	if label := strings.TrimRight(string(primary[40:72]), " "); label != "cidata" {
+		t.Errorf("expected the primary volume label %q, got %q", "cidata", label)
+	}
+
+	joliet := image[17*isoSectorSize:]
+	if label := strings.TrimRight(isoDecodeUCS2(joliet[40:72]), " "); label != "cidata" {
+		t.Errorf("expected the Joliet volume label %q, got %q", "cidata", label)
+	}
+
+	for name, read := range map[string]func([]byte) map[string][]byte{
+		"Rock Ridge": isoReadRockRidge,
+		"Joliet":     isoReadJoliet,
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual := read(image)
+
+			if len(actual) != len(files) {
+				t.Errorf("expected %d files, got %d", len(files), len(actual))
+			}
+
+			for _, file := range files {
+				content, ok := actual[file.Path]
+				if !ok {
+					t.Errorf("expected file %q", file.Path)
+					continue
+				}
+
+				if !bytes.Equal(content, file.Content) {
+					t.Errorf("expected file %q to hold %q, got %q", file.Path, file.Content, content)
+				}
+			}
+		})
+	}
+
+	again, err := ISO9660("cidata", files)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(image, again) {
+		t.Errorf("expected the same image for the same files")
+	}
+}
+
+func TestISO9660_identifiers(t *testing.T) {
+	testCases := map[string]struct {
+		name     string
+		dir      bool
+		taken    map[string]bool
+		expected string
+	}{
+		"file without extension": {
+			name:     "user-data",
+			expected: "USER_DATA.;1",
+		},
+		"file with extension": {
+			name:     "meta_data.json",
+			expected: "META_DATA.JSON;1",
+		},
+		"long file": {
+			name:     "a-very-long-file-name-for-iso-9660.json",
+			expected: "A_VERY_LONG_FILE_NAME_FOR_.JSON;1",
+		},
+		"directory": {
+			name:     "openstack",
+			dir:      true,
+			expected: "OPENSTACK",
+		},
+		"taken": {
+			name:     "user_data",
+			taken:    map[string]bool{"USER_DATA.;1": true},
+			expected: "USER_DATA1.;1",
+		},
+		"taken long file": {
+			name:     "a-very-long-file-name-for-iso-9660.json",
+			taken:    map[string]bool{"A_VERY_LONG_FILE_NAME_FOR_.JSON;1": true, "A_VERY_LONG_FILE_NAME_FOR1.JSON;1": true},
+			expected: "A_VERY_LONG_FILE_NAME_FOR2.JSON;1",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual := string(isoIdentifier(&node{name: tc.name, dir: tc.dir}, false, tc.taken))
+			if actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestISO9660_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		label    string
+		files    []File
+		expected string
+	}{
+		"label too long": {
+			label:    "a-label-which-is-too-long",
+			expected: `volume label "a-label-which-is-too-long" is longer than 16 characters`,
+		},
+		"empty path component": {
+			label:    "cidata",
+			files:    []File{{Path: "openstack//meta_data.json"}},
+			expected: `invalid path "openstack//meta_data.json"`,
+		},
+		"duplicate path": {
+			label:    "cidata",
+			files:    []File{{Path: "user-data"}, {Path: "user-data"}},
+			expected: `duplicate path "user-data"`,
+		},
+		"path below a file": {
+			label:    "cidata",
+			files:    []File{{Path: "openstack"}, {Path: "openstack/latest/user_data"}},
+			expected: `path "openstack/latest/user_data" is below the file "openstack"`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ISO9660(tc.label, tc.files)
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
+
+// isoReadJoliet returns the files of the Joliet hierarchy of image by path.
+func isoReadJoliet(image []byte) map[string][]byte {
+	files := make(map[string][]byte)
+	isoReadDirectory(image, image[17*isoSectorSize+156:], "", files, func(record []byte) string {
+		return isoDecodeUCS2(record[33 : 33+record[32]])
+	})
+
+	return files
+}
+
+// isoReadRockRidge returns the files of the primary hierarchy of image by
+// their Rock Ridge path.
+func isoReadRockRidge(image []byte) map[string][]byte {
+	files := make(map[string][]byte)
+	isoReadDirectory(image, image[16*isoSectorSize+156:], "", files, func(record []byte) string {
+		offset := 33 + int(record[32])
+		if record[32]%2 == 0 {
+			offset++
+		}
+
+		for systemUse := record[offset:record[0]]; len(systemUse) >= 4; systemUse = systemUse[systemUse[2]:] {
+			if string(systemUse[:2]) == "NM" {
+				return string(systemUse[5:systemUse[2]])
+			}
+		}
+
+		return ""
+	})
+
+	return files
+}
+
+func isoReadDirectory(image, dirRecord []byte, prefix string, files map[string][]byte, name func([]byte) string) {
+	extent := int(binary.LittleEndian.Uint32(dirRecord[2:]))
+	size := int(binary.LittleEndian.Uint32(dirRecord[10:]))
+	dir := image[extent*isoSectorSize : extent*isoSectorSize+size]
+
+	for offset := 0; offset < len(dir); {
+		record := dir[offset:]
+		if record[0] == 0 {
+			// Records do not cross sectors
+			offset = (offset/isoSectorSize + 1) * isoSectorSize
+			continue
+		}
+
+		offset += int(record[0])
+
+		if record[32] == 1 && record[33] <= 1 {
+			continue
+		}
+
+		path := prefix + name(record)
+
+		if record[25]&2 != 0 {
+			isoReadDirectory(image, record, path+"/", files, name)
+			continue
+		}
+
+		fileExtent := int(binary.LittleEndian.Uint32(record[2:]))
+		fileSize := int(binary.LittleEndian.Uint32(record[10:]))
+		files[path] = image[fileExtent*isoSectorSize : fileExtent*isoSectorSize+fileSize]
+	}
+}
+
+func isoDecodeUCS2(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+
+	return string(utf16.Decode(units))
+}
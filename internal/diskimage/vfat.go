@@ -0,0 +1,445 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package diskimage
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	fatSectorSize      = 512
+	fatReservedSectors = 1
+	fatCount           = 2
+	fatRootEntries     = 512
+	fatEntrySize       = 32
+
+	// fatMaxClusters is the maximum number of clusters of FAT12, which is
+	// enough for seed images with clusters of up to 64 KiB.
+	fatMaxClusters          = 4084
+	fatMaxSectorsPerCluster = 128
+
+	fatMedia = 0xf8
+
+	// fatMaxLabelLength is the maximum length of the volume label.
+	fatMaxLabelLength = 11
+
+	// fatMaxNameLength is the maximum length of a long file name, in UTF-16
+	// code units.
+	fatMaxNameLength = 255
+
+	// Directory entry attributes
+	fatAttrVolumeID  = 0x08
+	fatAttrDirectory = 0x10
+	fatAttrArchive   = 0x20
+	fatAttrLongName  = 0x0f
+)
+
+// fatWriter lays out and writes a FAT12 image.
+type fatWriter struct {
+	label string
+	root  *node
+
+	shortNames map[*node][11]byte
+	longNames  map[*node]bool
+
+	sectorsPerCluster int
+	clusters          map[*node]int
+	clusterCounts     map[*node]int
+	totalClusters     int
+	fatSectors        int
+}
+
+// VFAT returns a FAT12 image with the volume label label holding files, with
+// long file names for names which are not valid 8.3 names. The label is
+// written in upper case, as FAT labels conventionally are.
+func VFAT(label string, files []File) ([]byte, error) {
+	if len(label) > fatMaxLabelLength {
+		return nil, fmt.Errorf("volume label %q is longer than %d characters", label, fatMaxLabelLength)
+	}
+
+	root, err := newTree(files)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &fatWriter{
+		label:         strings.ToUpper(label),
+		root:          root,
+		shortNames:    make(map[*node][11]byte),
+		longNames:     make(map[*node]bool),
+		clusters:      make(map[*node]int),
+		clusterCounts: make(map[*node]int),
+	}
+
+	if err := w.names(root); err != nil {
+		return nil, err
+	}
+
+	// The root directory also holds the volume label
+	if entries := w.entryCount(root) + 1; entries > fatRootEntries {
+		return nil, fmt.Errorf("the root directory needs %d entries, more than the %d available", entries, fatRootEntries)
+	}
+
+	if err := w.layout(); err != nil {
+		return nil, err
+	}
+
+	return w.write(), nil
+}
+
+// names sets the short names of the nodes below dir, and which of them need a
+// long name.
+func (w *fatWriter) names(dir *node) error {
+	taken := make(map[[11]byte]bool)
+
+	for _, child := range sortedChildren(dir, byName) {
+		if len(utf16.Encode([]rune(child.name))) > fatMaxNameLength {
+			return fmt.Errorf("name %q is longer than %d characters", child.name, fatMaxNameLength)
+		}
+
+		shortName, longName := fatShortName(child.name, taken)
+		taken[shortName] = true
+		w.shortNames[child] = shortName
+		w.longNames[child] = longName
+
+		if child.dir {
+			if err := w.names(child); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// fatShortName returns the 8.3 name of name which is not taken yet, and
+// whether name needs a long name as it is not a valid 8.3 name itself. Like
+// Windows, names which are not valid are shortened to the first characters
+// followed by "~" and a number.
+func fatShortName(name string, taken map[[11]byte]bool) ([11]byte, bool) {
+	base, extension := name, ""
+	if i := strings.LastIndex(name, "."); i > 0 {
+		base, extension = name[:i], name[i+1:]
+	}
+
+	shortBase, shortExtension := fatChars(base), fatChars(extension)
+
+	if shortBase == base && shortExtension == extension && len(base) <= 8 && len(extension) <= 3 {
+		shortName := fatName(shortBase, shortExtension)
+		if !taken[shortName] {
+			return shortName, false
+		}
+	}
+
+	shortExtension = truncate(shortExtension, 3)
+
+	for i := 1; ; i++ {
+		tail := "~" + strconv.Itoa(i)
+
+		shortName := fatName(truncate(shortBase, 8-len(tail))+tail, shortExtension)
+		if !taken[shortName] {
+			return shortName, true
+		}
+	}
+}
+
+// fatChars returns s in upper case, without spaces and dots, and with any other
+// character which is not valid in an 8.3 name replaced by an underscore.
+func fatChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ' || r == '.':
+			return -1
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune("!#$%&'()-@^_`{}~", r):
+			return r
+		}
+
+		return '_'
+	}, s)
+}
+
+// fatName returns the 11 byte directory entry name of base and extension,
+// padded with spaces.
+func fatName(base, extension string) [11]byte {
+	var name [11]byte
+	copy(name[:], fmt.Sprintf("%-8s%-3s", base, extension))
+
+	return name
+}
+
+// entryCount returns the number of directory entries of the children of dir,
+// including those of their long names.
+func (w *fatWriter) entryCount(dir *node) int {
+	count := 0
+
+	for _, child := range dir.children {
+		count++
+
+		if w.longNames[child] {
+			count += len(fatLongNameEntries(child.name, [11]byte{}))
+		}
+	}
+
+	return count
+}
+
+// layout picks the smallest cluster size which fits the files in FAT12, and
+// assigns the clusters of the directories and files.
+func (w *fatWriter) layout() error {
+	for w.sectorsPerCluster = 1; ; w.sectorsPerCluster *= 2 {
+		if w.sectorsPerCluster > fatMaxSectorsPerCluster {
+			return fmt.Errorf("the files do not fit in a FAT12 image")
+		}
+
+		clusterSize := w.sectorsPerCluster * fatSectorSize
+		w.totalClusters = 0
+
+		w.walk(w.root, func(n *node) {
+			count := sectors(len(n.content), clusterSize)
+			if n.dir {
+				// Subdirectories also have "." and ".." entries
+				count = sectors((w.entryCount(n)+2)*fatEntrySize, clusterSize)
+			}
+
+			w.clusterCounts[n] = count
+			w.totalClusters += count
+		})
+
+		if w.totalClusters <= fatMaxClusters {
+			break
+		}
+	}
+
+	// Keep at least one cluster, as a FAT without any is not valid
+	w.totalClusters = max(w.totalClusters, 1)
+	w.fatSectors = sectors((w.totalClusters+2)*3/2+1, fatSectorSize)
+
+	next := 2
+	w.walk(w.root, func(n *node) {
+		if w.clusterCounts[n] > 0 {
+			w.clusters[n] = next
+			next += w.clusterCounts[n]
+		}
+	})
+
+	return nil
+}
+
+// walk calls fn for the nodes below dir, with each directory before its
+// children.
+func (w *fatWriter) walk(dir *node, fn func(n *node)) {
+	for _, child := range sortedChildren(dir, byName) {
+		fn(child)
+
+		if child.dir {
+			w.walk(child, fn)
+		}
+	}
+}
+
+// write returns the image laid out by layout.
+func (w *fatWriter) write() []byte {
+	rootSectors := fatRootEntries * fatEntrySize / fatSectorSize
+	rootStart := fatReservedSectors + fatCount*w.fatSectors
+	dataStart := rootStart + rootSectors
+	totalSectors := dataStart + w.totalClusters*w.sectorsPerCluster
+
+	image := make([]byte, totalSectors*fatSectorSize)
+	sector := func(i int) []byte {
+		return image[i*fatSectorSize:]
+	}
+	cluster := func(i int) []byte {
+		return sector(dataStart + (i-2)*w.sectorsPerCluster)
+	}
+
+	fat := make([]byte, w.fatSectors*fatSectorSize)
+	fat12Set(fat, 0, 0xf00|fatMedia)
+	fat12Set(fat, 1, 0xfff)
+
+	rootEntries := fatEntry(fatName(w.label, ""), fatAttrVolumeID, 0, 0)
+
+	w.walk(w.root, func(n *node) {
+		first, count := w.clusters[n], w.clusterCounts[n]
+		for i := first; i < first+count; i++ {
+			next := i + 1
+			if next == first+count {
+				next = 0xfff
+			}
+
+			fat12Set(fat, i, next)
+		}
+
+		var entries []byte
+		if w.longNames[n] {
+			for _, entry := range fatLongNameEntries(n.name, w.shortNames[n]) {
+				entries = append(entries, entry...)
+			}
+		}
+
+		if n.dir {
+			entries = append(entries, fatEntry(w.shortNames[n], fatAttrDirectory, first, 0)...)
+
+			parent := 0
+			if n.parent != w.root {
+				parent = w.clusters[n.parent]
+			}
+
+			contents := fatEntry(fatName(".", ""), fatAttrDirectory, first, 0)
+			contents = append(contents, fatEntry(fatName("..", ""), fatAttrDirectory, parent, 0)...)
+			copy(cluster(first), contents)
+		} else {
+			entries = append(entries, fatEntry(w.shortNames[n], fatAttrArchive, first, len(n.content))...)
+
+			if count > 0 {
+				copy(cluster(first), n.content)
+			}
+		}
+
+		if n.parent == w.root {
+			rootEntries = append(rootEntries, entries...)
+		} else {
+			// After the "." and ".." entries and those of the previous children
+			dir := cluster(w.clusters[n.parent])
+			offset := 0
+			for dir[offset] != 0 {
+				offset += fatEntrySize
+			}
+
+			copy(dir[offset:], entries)
+		}
+	})
+
+	for i := 0; i < fatCount; i++ {
+		copy(sector(fatReservedSectors+i*w.fatSectors), fat)
+	}
+
+	copy(sector(rootStart), rootEntries)
+
+	w.bootSector(sector(0), totalSectors)
+
+	// The volume serial number is derived from the image, so that it does not
+	// change as long as the files do.
+	binary.LittleEndian.PutUint32(image[39:], crc32.ChecksumIEEE(image))
+
+	return image
+}
+
+// bootSector writes the boot sector, with the BIOS parameter block describing
+// the layout of the image, to sector.
+func (w *fatWriter) bootSector(sector []byte, totalSectors int) {
+	copy(sector, []byte{0xeb, 0x3c, 0x90})
+	copy(sector[3:11], "MSWIN4.1")
+	binary.LittleEndian.PutUint16(sector[11:], fatSectorSize)
+	sector[13] = byte(w.sectorsPerCluster)
+	binary.LittleEndian.PutUint16(sector[14:], fatReservedSectors)
+	sector[16] = fatCount
+	binary.LittleEndian.PutUint16(sector[17:], fatRootEntries)
+
+	if totalSectors < 0x10000 {
+		binary.LittleEndian.PutUint16(sector[19:], uint16(totalSectors))
+	} else {
+		binary.LittleEndian.PutUint32(sector[32:], uint32(totalSectors))
+	}
+
+	sector[21] = fatMedia
+	binary.LittleEndian.PutUint16(sector[22:], uint16(w.fatSectors))
+	binary.LittleEndian.PutUint16(sector[24:], 32)
+	binary.LittleEndian.PutUint16(sector[26:], 64)
+
+	// Extended boot signature, followed by the volume serial number, label and
+	// file system type
+	sector[36] = 0x80
+	sector[38] = 0x29
+	name := fatName(w.label, "")
+	copy(sector[43:54], name[:])
+	copy(sector[54:62], "FAT12   ")
+
+	sector[510] = 0x55
+	sector[511] = 0xaa
+}
+
+// fatEntry returns a directory entry.
+func fatEntry(name [11]byte, attr byte, cluster, size int) []byte {
+	date := uint16(timestamp.Year()-1980)<<9 | uint16(timestamp.Month())<<5 | uint16(timestamp.Day())
+	time := uint16(timestamp.Hour())<<11 | uint16(timestamp.Minute())<<5 | uint16(timestamp.Second()/2)
+
+	entry := make([]byte, fatEntrySize)
+	copy(entry, name[:])
+	entry[11] = attr
+	binary.LittleEndian.PutUint16(entry[14:], time)
+	binary.LittleEndian.PutUint16(entry[16:], date)
+	binary.LittleEndian.PutUint16(entry[18:], date)
+	binary.LittleEndian.PutUint16(entry[22:], time)
+	binary.LittleEndian.PutUint16(entry[24:], date)
+	binary.LittleEndian.PutUint16(entry[26:], uint16(cluster))
+	binary.LittleEndian.PutUint32(entry[28:], uint32(size))
+
+	return entry
+}
+
+// fatLongNameEntries returns the directory entries holding name as a long file
+// name for the entry with shortName, in the order they precede it.
+func fatLongNameEntries(name string, shortName [11]byte) [][]byte {
+	const unitsPerEntry = 13
+
+	units := utf16.Encode([]rune(name))
+	if len(units)%unitsPerEntry != 0 {
+		units = append(units, 0)
+	}
+
+	for len(units)%unitsPerEntry != 0 {
+		units = append(units, 0xffff)
+	}
+
+	var checksum byte
+	for _, c := range shortName {
+		checksum = (checksum&1)<<7 + checksum>>1 + c
+	}
+
+	count := len(units) / unitsPerEntry
+	entries := make([][]byte, 0, count)
+
+	for i := count; i > 0; i-- {
+		chunk := units[(i-1)*unitsPerEntry : i*unitsPerEntry]
+
+		entry := make([]byte, fatEntrySize)
+		entry[0] = byte(i)
+		if i == count {
+			entry[0] |= 0x40
+		}
+
+		entry[11] = fatAttrLongName
+		entry[13] = checksum
+
+		for j, unit := range chunk {
+			offset := []int{1, 3, 5, 7, 9, 14, 16, 18, 20, 22, 24, 28, 30}[j]
+			binary.LittleEndian.PutUint16(entry[offset:], unit)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// fat12Set sets the FAT12 entry of cluster to value. Entries are 12 bits, so
+// two of them share three bytes.
+func fat12Set(fat []byte, cluster, value int) {
+	offset := cluster + cluster/2
+
+	if cluster%2 == 0 {
+		fat[offset] = byte(value)
+		fat[offset+1] = fat[offset+1]&0xf0 | byte(value>>8)&0x0f
+	} else {
+		fat[offset] = fat[offset]&0x0f | byte(value<<4)
+		fat[offset+1] = byte(value >> 4)
+	}
+}
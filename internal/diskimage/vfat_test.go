@@ -0,0 +1,246 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package diskimage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestVFAT(t *testing.T) {
+	files := []File{
+		{Path: "user-data", Content: []byte("#cloud-config\npackages:\n  - git\n")},
+		{Path: "meta-data", Content: []byte{}},
+		{Path: "network-config", Content: []byte("version: 2\n")},
+		{Path: "README", Content: []byte("A valid 8.3 name without a long name")},
+		{Path: "openstack/latest/meta_data.json", Content: []byte(`{"uuid": "d8e02d56"}`)},
+		{Path: "openstack/content/0000", Content: bytes.Repeat([]byte("0123456789"), 500)},
+	}
+
+	for i := 0; i < 40; i++ {
+		files = append(files, File{Path: fmt.Sprintf("many/file-%02d", i), Content: []byte{byte(i)}})
+	}
+
+	image, err := VFAT("cidata", files)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if label := strings.TrimRight(string(image[43:54]), " "); label != "CIDATA" {
+		t.Errorf("expected the volume label %q, got %q", "CIDATA", label)
+	}
+
+	if image[510] != 0x55 || image[511] != 0xaa {
+		t.Errorf("expected the boot sector signature")
+	}
+
+	actual := fatRead(t, image)
+
+	if len(actual) != len(files) {
+		t.Errorf("expected %d files, got %d", len(files), len(actual))
+	}
+
+	for _, file := range files {
+		content, ok := actual[file.Path]
+		if !ok {
+			t.Errorf("expected file %q", file.Path)
+			continue
+		}
+
+		if !bytes.Equal(content, file.Content) {
+			t.Errorf("expected file %q to hold %q, got %q", file.Path, file.Content, content)
+		}
+	}
+
+	again, err := VFAT("cidata", files)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !bytes.Equal(image, again) {
+		t.Errorf("expected the same image for the same files")
+	}
+}
+
+func TestVFAT_shortNames(t *testing.T) {
+	testCases := map[string]struct {
+		names            []string
+		expected         []string
+		expectedLongName []bool
+	}{
+		"valid 8.3 names": {
+			names:            []string{"README", "CONFIG.JSO"},
+			expected:         []string{"README     ", "CONFIG  JSO"},
+			expectedLongName: []bool{false, false},
+		},
+		"lower case names": {
+			names:            []string{"readme", "config.json"},
+			expected:         []string{"README~1   ", "CONFIG~1JSO"},
+			expectedLongName: []bool{true, true},
+		},
+		"same prefix": {
+			names:            []string{"user-data", "user-data.json", "user-data-2"},
+			expected:         []string{"USER-D~1   ", "USER-D~1JSO", "USER-D~2   "},
+			expectedLongName: []bool{true, true, true},
+		},
+		"invalid characters": {
+			names:            []string{"a b+c"},
+			expected:         []string{"AB_C~1     "},
+			expectedLongName: []bool{true},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			taken := make(map[[11]byte]bool)
+
+			for i, name := range tc.names {
+				shortName, longName := fatShortName(name, taken)
+				taken[shortName] = true
+
+				if string(shortName[:]) != tc.expected[i] {
+					t.Errorf("expected %q for %q, got %q", tc.expected[i], name, shortName)
+				}
+
+				if longName != tc.expectedLongName[i] {
+					t.Errorf("expected long name %t for %q, got %t", tc.expectedLongName[i], name, longName)
+				}
+			}
+		})
+	}
+}
+
+func TestVFAT_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		label    string
+		files    []File
+		expected string
+	}{
+		"label too long": {
+			label:    "a-long-label",
+			expected: `volume label "a-long-label" is longer than 11 characters`,
+		},
+		"duplicate path": {
+			label:    "cidata",
+			files:    []File{{Path: "user-data"}, {Path: "user-data"}},
+			expected: `duplicate path "user-data"`,
+		},
+		"name too long": {
+			label:    "cidata",
+			files:    []File{{Path: strings.Repeat("a", 256)}},
+			expected: fmt.Sprintf("name %q is longer than 255 characters", strings.Repeat("a", 256)),
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := VFAT(tc.label, tc.files)
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
+
+// fatRead returns the files of the FAT12 image by path, reading the layout
+// from the BIOS parameter block.
+func fatRead(t *testing.T, image []byte) map[string][]byte {
+	sectorSize := int(binary.LittleEndian.Uint16(image[11:]))
+	sectorsPerCluster := int(image[13])
+	reservedSectors := int(binary.LittleEndian.Uint16(image[14:]))
+	fats := int(image[16])
+	rootEntries := int(binary.LittleEndian.Uint16(image[17:]))
+	totalSectors := int(binary.LittleEndian.Uint16(image[19:]))
+	fatSectors := int(binary.LittleEndian.Uint16(image[22:]))
+
+	if totalSectors*sectorSize != len(image) {
+		t.Fatalf("expected %d sectors, got %d bytes", totalSectors, len(image))
+	}
+
+	fat := image[reservedSectors*sectorSize:]
+	rootStart := (reservedSectors + fats*fatSectors) * sectorSize
+	dataStart := rootStart + rootEntries*fatEntrySize
+	clusterSize := sectorsPerCluster * sectorSize
+
+	if clusters := (len(image) - dataStart) / clusterSize; clusters > fatMaxClusters {
+		t.Fatalf("expected at most %d clusters for FAT12, got %d", fatMaxClusters, clusters)
+	}
+
+	chain := func(cluster int) []byte {
+		var data []byte
+
+		for cluster >= 2 && cluster < 0xff8 {
+			offset := dataStart + (cluster-2)*clusterSize
+			data = append(data, image[offset:offset+clusterSize]...)
+
+			entry := int(binary.LittleEndian.Uint16(fat[cluster+cluster/2:]))
+			if cluster%2 == 0 {
+				cluster = entry & 0xfff
+			} else {
+				cluster = entry >> 4
+			}
+		}
+
+		return data
+	}
+
+	files := make(map[string][]byte)
+
+	var readDirectory func(entries []byte, prefix string)
+	readDirectory = func(entries []byte, prefix string) {
+		var longName []uint16
+
+		for offset := 0; offset+fatEntrySize <= len(entries) && entries[offset] != 0; offset += fatEntrySize {
+			entry := entries[offset : offset+fatEntrySize]
+
+			if entry[11] == fatAttrLongName {
+				var units []uint16
+				for _, i := range []int{1, 3, 5, 7, 9, 14, 16, 18, 20, 22, 24, 28, 30} {
+					unit := binary.LittleEndian.Uint16(entry[i:])
+					if unit == 0 || unit == 0xffff {
+						break
+					}
+
+					units = append(units, unit)
+				}
+
+				longName = append(units, longName...)
+				continue
+			}
+
+			name := strings.TrimRight(string(entry[:8]), " ")
+			if extension := strings.TrimRight(string(entry[8:11]), " "); extension != "" {
+				name += "." + extension
+			}
+
+			if longName != nil {
+				name = string(utf16.Decode(longName))
+				longName = nil
+			}
+
+			cluster := int(binary.LittleEndian.Uint16(entry[26:]))
+			size := int(binary.LittleEndian.Uint32(entry[28:]))
+
+			switch {
+			case entry[11]&fatAttrVolumeID != 0, name == ".", name == "..":
+			case entry[11]&fatAttrDirectory != 0:
+				readDirectory(chain(cluster), prefix+name+"/")
+			default:
+				files[prefix+name] = chain(cluster)[:size]
+			}
+		}
+	}
+
+	readDirectory(image[rootStart:dataStart], "")
+
+	return files
+}
@@ -5,6 +5,10 @@ package hashcode
 
 import (
 	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"fmt"
 	"hash/crc32"
 )
@@ -36,3 +40,23 @@ func Strings(strings []string) string {
 
 	return fmt.Sprintf("%d", String(buf.String()))
 }
+
+// SHA256 returns the hex encoded SHA-256 digest of a string, as the sha256
+// Terraform function and the sha256sum utility do.
+func SHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// SHA512 returns the hex encoded SHA-512 digest of a string.
+func SHA512(s string) string {
+	sum := sha512.Sum512([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// MD5 returns the hex encoded MD5 digest of a string. MD5 is not collision
+// resistant, and is only provided for systems which expect it.
+func MD5(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -38,3 +38,31 @@ func TestString_positiveIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestDigests(t *testing.T) {
+	testCases := map[string]struct {
+		digest   func(string) string
+		expected string
+	}{
+		"sha256": {
+			digest:   SHA256,
+			expected: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+		},
+		"sha512": {
+			digest:   SHA512,
+			expected: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+		},
+		"md5": {
+			digest:   MD5,
+			expected: "900150983cd24fb0d6963f7d28e17f72",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if actual := tc.digest("abc"); actual != tc.expected {
+				t.Fatalf("bad: %#v\n\t%#v", actual, tc.expected)
+			}
+		})
+	}
+}
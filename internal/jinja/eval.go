@@ -0,0 +1,1159 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package jinja
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// undefined is the value of a variable, attribute or item which does not
+// exist. Like in Jinja, it is false, empty, and written as Options.Undefined
+// returns, but using it any other way is an error.
+type undefined struct {
+	name string
+}
+
+func (u undefined) err() error {
+	return fmt.Errorf("%s is undefined", quoteString(u.name))
+}
+
+// function is a global function, such as range.
+type function func(args []any, keywords map[string]any) (any, error)
+
+// globalFunctions are the functions templates can call besides filters, tests
+// and methods.
+var globalFunctions = map[string]function{
+	"range": rangeFunction,
+}
+
+type scope struct {
+	vars   map[string]any
+	parent *scope
+}
+
+func (s *scope) lookup(name string) (any, bool) {
+	for ; s != nil; s = s.parent {
+		if value, ok := s.vars[name]; ok {
+			return value, true
+		}
+	}
+
+	if fn, ok := globalFunctions[name]; ok {
+		return fn, true
+	}
+
+	return nil, false
+}
+
+type renderer struct {
+	options Options
+	output  strings.Builder
+}
+
+func (r *renderer) renderNodes(nodes []node, s *scope) error {
+	for _, n := range nodes {
+		if err := r.renderNode(n, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *renderer) renderNode(n node, s *scope) error {
+	switch n := n.(type) {
+	case textNode:
+		r.output.WriteString(n.text)
+	case outputNode:
+		value, err := r.eval(n.expr, s)
+		if err != nil {
+			return lineError(n.line, err)
+		}
+
+		r.output.WriteString(r.str(value))
+	case ifNode:
+		for _, branch := range n.branches {
+			value, err := r.eval(branch.test, s)
+			if err != nil {
+				return lineError(branch.line, err)
+			}
+
+			if truthy(value) {
+				return r.renderNodes(branch.body, s)
+			}
+		}
+
+		return r.renderNodes(n.elseBody, s)
+	case forNode:
+		return r.renderFor(n, s)
+	case setNode:
+		value, err := r.eval(n.value, s)
+		if err != nil {
+			return lineError(n.line, err)
+		}
+
+		if err := assign(s.vars, n.targets, value); err != nil {
+			return lineError(n.line, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *renderer) renderFor(n forNode, s *scope) error {
+	iterable, err := r.eval(n.iterable, s)
+	if err != nil {
+		return lineError(n.line, err)
+	}
+
+	items, err := iterate(iterable)
+	if err != nil {
+		return lineError(n.line, err)
+	}
+
+	if n.filter != nil {
+		var filtered []any
+
+		for _, item := range items {
+			vars := map[string]any{}
+			if err := assign(vars, n.targets, item); err != nil {
+				return lineError(n.line, err)
+			}
+
+			keep, err := r.eval(n.filter, &scope{vars: vars, parent: s})
+			if err != nil {
+				return lineError(n.line, err)
+			}
+
+			if truthy(keep) {
+				filtered = append(filtered, item)
+			}
+		}
+
+		items = filtered
+	}
+
+	if len(items) == 0 {
+		return r.renderNodes(n.elseBody, s)
+	}
+
+	for i, item := range items {
+		vars := map[string]any{
+			"loop": map[string]any{
+				"index":     int64(i + 1),
+				"index0":    int64(i),
+				"revindex":  int64(len(items) - i),
+				"revindex0": int64(len(items) - i - 1),
+				"first":     i == 0,
+				"last":      i == len(items)-1,
+				"length":    int64(len(items)),
+			},
+		}
+
+		if err := assign(vars, n.targets, item); err != nil {
+			return lineError(n.line, err)
+		}
+
+		if err := r.renderNodes(n.body, &scope{vars: vars, parent: s}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assign sets the targets of a for or set tag to value, unpacking it when there
+// are several.
+func assign(vars map[string]any, targets []string, value any) error {
+	if len(targets) == 1 {
+		vars[targets[0]] = value
+		return nil
+	}
+
+	items, err := iterate(value)
+	if err != nil {
+		return err
+	}
+
+	if len(items) != len(targets) {
+		return fmt.Errorf("expected %d values to unpack, got %d", len(targets), len(items))
+	}
+
+	for i, target := range targets {
+		vars[target] = items[i]
+	}
+
+	return nil
+}
+
+func (r *renderer) eval(e expr, s *scope) (any, error) {
+	switch e := e.(type) {
+	case literalExpr:
+		return e.value, nil
+	case nameExpr:
+		if value, ok := s.lookup(e.name); ok {
+			return value, nil
+		}
+
+		return undefined{name: e.name}, nil
+	case getattrExpr:
+		target, err := r.eval(e.target, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if u, ok := target.(undefined); ok {
+			return nil, u.err()
+		}
+
+		if mapping, ok := target.(map[string]any); ok {
+			if value, ok := mapping[e.name]; ok {
+				return value, nil
+			}
+		}
+
+		return undefined{name: e.name}, nil
+	case getitemExpr:
+		target, err := r.eval(e.target, s)
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := r.eval(e.index, s)
+		if err != nil {
+			return nil, err
+		}
+
+		return getitem(target, index)
+	case sliceExpr:
+		return r.evalSlice(e, s)
+	case listExpr:
+		return r.evalList(e.items, s)
+	case dictExpr:
+		dict := make(map[string]any, len(e.keys))
+
+		for i := range e.keys {
+			key, err := r.eval(e.keys[i], s)
+			if err != nil {
+				return nil, err
+			}
+
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected the keys of a dict to be strings, got %s", typeName(key))
+			}
+
+			value, err := r.eval(e.values[i], s)
+			if err != nil {
+				return nil, err
+			}
+
+			dict[name] = value
+		}
+
+		return dict, nil
+	case unaryExpr:
+		operand, err := r.eval(e.operand, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.operator == "not" {
+			return !truthy(operand), nil
+		}
+
+		return unary(e.operator, operand)
+	case binaryExpr:
+		return r.evalBinary(e, s)
+	case compareExpr:
+		left, err := r.eval(e.first, s)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, operator := range e.operators {
+			right, err := r.eval(e.operands[i], s)
+			if err != nil {
+				return nil, err
+			}
+
+			result, err := compare(operator, left, right)
+			if err != nil || !result {
+				return false, err
+			}
+
+			left = right
+		}
+
+		return true, nil
+	case condExpr:
+		test, err := r.eval(e.test, s)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case truthy(test):
+			return r.eval(e.then, s)
+		case e.otherwise != nil:
+			return r.eval(e.otherwise, s)
+		default:
+			return undefined{name: "None"}, nil
+		}
+	case filterExpr:
+		target, err := r.eval(e.target, s)
+		if err != nil {
+			return nil, err
+		}
+
+		args, keywords, err := r.evalArguments(e.args, s)
+		if err != nil {
+			return nil, err
+		}
+
+		return filters[e.name](r, target, args, keywords)
+	case testExpr:
+		target, err := r.eval(e.target, s)
+		if err != nil {
+			return nil, err
+		}
+
+		args, keywords, err := r.evalArguments(e.args, s)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := tests[e.name](r, target, args, keywords)
+		if err != nil {
+			return nil, err
+		}
+
+		return result != e.negated, nil
+	case callExpr:
+		return r.evalCall(e, s)
+	}
+
+	return nil, fmt.Errorf("unexpected expression %T", e)
+}
+
+func (r *renderer) evalList(items []expr, s *scope) ([]any, error) {
+	list := make([]any, 0, len(items))
+
+	for _, item := range items {
+		value, err := r.eval(item, s)
+		if err != nil {
+			return nil, err
+		}
+
+		list = append(list, value)
+	}
+
+	return list, nil
+}
+
+func (r *renderer) evalArguments(args arguments, s *scope) ([]any, map[string]any, error) {
+	positional, err := r.evalList(args.positional, s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keywords := make(map[string]any, len(args.keywords))
+	for name, arg := range args.keywords {
+		value, err := r.eval(arg, s)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		keywords[name] = value
+	}
+
+	return positional, keywords, nil
+}
+
+func (r *renderer) evalBinary(e binaryExpr, s *scope) (any, error) {
+	left, err := r.eval(e.left, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.operator {
+	case "and":
+		if !truthy(left) {
+			return left, nil
+		}
+
+		return r.eval(e.right, s)
+	case "or":
+		if truthy(left) {
+			return left, nil
+		}
+
+		return r.eval(e.right, s)
+	}
+
+	right, err := r.eval(e.right, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.operator == "~" {
+		return r.str(left) + r.str(right), nil
+	}
+
+	// A name with a dash, such as local-hostname, is read as a subtraction,
+	// which is worth pointing out over the undefined variable.
+	if u, ok := left.(undefined); ok && e.operator == "-" {
+		if name, ok := e.right.(nameExpr); ok {
+			return nil, fmt.Errorf("%s is undefined in %s-%s, which Jinja reads as a subtraction", quoteString(u.name), u.name, name.name)
+		}
+	}
+
+	return binary(e.operator, left, right)
+}
+
+func (r *renderer) evalSlice(e sliceExpr, s *scope) (any, error) {
+	target, err := r.eval(e.target, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var bounds [3]*int64
+	for i, part := range []expr{e.start, e.stop, e.step} {
+		if part == nil {
+			continue
+		}
+
+		value, err := r.eval(part, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if value == nil {
+			continue
+		}
+
+		number, ok := value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("expected slice indices to be integers, got %s", typeName(value))
+		}
+
+		bounds[i] = &number
+	}
+
+	switch target := target.(type) {
+	case string:
+		runes := []rune(target)
+
+		indexes, err := sliceIndexes(len(runes), bounds)
+		if err != nil {
+			return nil, err
+		}
+
+		var result strings.Builder
+		for _, i := range indexes {
+			result.WriteRune(runes[i])
+		}
+
+		return result.String(), nil
+	case []any:
+		indexes, err := sliceIndexes(len(target), bounds)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]any, 0, len(indexes))
+		for _, i := range indexes {
+			result = append(result, target[i])
+		}
+
+		return result, nil
+	case undefined:
+		return nil, target.err()
+	}
+
+	return nil, fmt.Errorf("%s object is not subscriptable", quoteString(typeName(target)))
+}
+
+// sliceIndexes returns the indexes of a sequence of the given length selected by
+// the start, stop and step of a slice, like Python does.
+func sliceIndexes(length int, bounds [3]*int64) ([]int, error) {
+	step := 1
+	if bounds[2] != nil {
+		step = int(*bounds[2])
+	}
+
+	if step == 0 {
+		return nil, errors.New("slice step cannot be zero")
+	}
+
+	clamp := func(bound *int64, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+
+		i := int(*bound)
+		if i < 0 {
+			i += length
+		}
+
+		low, high := 0, length
+		if step < 0 {
+			low, high = -1, length-1
+		}
+
+		return max(low, min(i, high))
+	}
+
+	var indexes []int
+
+	if step > 0 {
+		for i := clamp(bounds[0], 0); i < clamp(bounds[1], length); i += step {
+			indexes = append(indexes, i)
+		}
+	} else {
+		for i := clamp(bounds[0], length-1); i > clamp(bounds[1], -1); i += step {
+			indexes = append(indexes, i)
+		}
+	}
+
+	return indexes, nil
+}
+
+func (r *renderer) evalCall(e callExpr, s *scope) (any, error) {
+	args, keywords, err := r.evalArguments(e.args, s)
+	if err != nil {
+		return nil, err
+	}
+
+	if attr, ok := e.callee.(getattrExpr); ok {
+		target, err := r.eval(attr.target, s)
+		if err != nil {
+			return nil, err
+		}
+
+		if method, ok := lookupMethod(target, attr.name); ok {
+			if err := checkArguments(attr.name, methodParameters[attr.name], attr.name == "split", args, keywords); err != nil {
+				return nil, err
+			}
+
+			return method(args, keywords)
+		}
+	}
+
+	callee, err := r.eval(e.callee, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch callee := callee.(type) {
+	case function:
+		return callee(args, keywords)
+	case undefined:
+		return nil, callee.err()
+	}
+
+	return nil, fmt.Errorf("%s object is not callable", quoteString(typeName(callee)))
+}
+
+func getitem(target, index any) (any, error) {
+	switch target := target.(type) {
+	case map[string]any:
+		if key, ok := index.(string); ok {
+			if value, ok := target[key]; ok {
+				return value, nil
+			}
+		}
+	case []any:
+		if i, ok := index.(int64); ok {
+			if i < 0 {
+				i += int64(len(target))
+			}
+
+			if i >= 0 && i < int64(len(target)) {
+				return target[i], nil
+			}
+		}
+	case string:
+		if i, ok := index.(int64); ok {
+			runes := []rune(target)
+			if i < 0 {
+				i += int64(len(runes))
+			}
+
+			if i >= 0 && i < int64(len(runes)) {
+				return string(runes[i]), nil
+			}
+		}
+	case undefined:
+		return nil, target.err()
+	}
+
+	if name, ok := index.(string); ok {
+		return undefined{name: name}, nil
+	}
+
+	return undefined{name: pyStr(index)}, nil
+}
+
+// iterate returns the items a for loop goes through: the items of a list, the
+// sorted keys of a mapping or the characters of a string.
+func iterate(value any) ([]any, error) {
+	switch value := value.(type) {
+	case []any:
+		return value, nil
+	case map[string]any:
+		keys := sortedKeys(value)
+
+		items := make([]any, len(keys))
+		for i, key := range keys {
+			items[i] = key
+		}
+
+		return items, nil
+	case string:
+		var items []any
+		for _, c := range value {
+			items = append(items, string(c))
+		}
+
+		return items, nil
+	case undefined:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("%s object is not iterable", quoteString(typeName(value)))
+}
+
+func unary(operator string, operand any) (any, error) {
+	switch operand := operand.(type) {
+	case int64:
+		if operator == "-" {
+			return -operand, nil
+		}
+
+		return operand, nil
+	case float64:
+		if operator == "-" {
+			return -operand, nil
+		}
+
+		return operand, nil
+	case undefined:
+		return nil, operand.err()
+	}
+
+	return nil, fmt.Errorf("bad operand type for unary %s: %s", operator, quoteString(typeName(operand)))
+}
+
+func binary(operator string, left, right any) (any, error) {
+	if u, ok := left.(undefined); ok {
+		return nil, u.err()
+	}
+
+	if u, ok := right.(undefined); ok {
+		return nil, u.err()
+	}
+
+	unsupported := fmt.Errorf("unsupported operand types for %s: %s and %s", operator, quoteString(typeName(left)), quoteString(typeName(right)))
+
+	switch operator {
+	case "+":
+		switch left := left.(type) {
+		case string:
+			if right, ok := right.(string); ok {
+				return left + right, nil
+			}
+		case []any:
+			if right, ok := right.([]any); ok {
+				return append(append([]any{}, left...), right...), nil
+			}
+		}
+	case "*":
+		if _, ok := left.(int64); ok {
+			switch right.(type) {
+			case string, []any:
+				left, right = right, left
+			}
+		}
+
+		if count, ok := right.(int64); ok {
+			switch left := left.(type) {
+			case string:
+				return strings.Repeat(left, int(max(count, 0))), nil
+			case []any:
+				var result []any
+				for i := int64(0); i < count; i++ {
+					result = append(result, left...)
+				}
+
+				return result, nil
+			}
+		}
+	}
+
+	leftInt, leftIsInt := left.(int64)
+	rightInt, rightIsInt := right.(int64)
+
+	if leftIsInt && rightIsInt {
+		switch operator {
+		case "+":
+			return leftInt + rightInt, nil
+		case "-":
+			return leftInt - rightInt, nil
+		case "*":
+			return leftInt * rightInt, nil
+		case "//", "%":
+			if rightInt == 0 {
+				return nil, errors.New("integer division or modulo by zero")
+			}
+
+			quotient := leftInt / rightInt
+			if (leftInt%rightInt != 0) && ((leftInt < 0) != (rightInt < 0)) {
+				quotient--
+			}
+
+			if operator == "//" {
+				return quotient, nil
+			}
+
+			return leftInt - quotient*rightInt, nil
+		case "**":
+			if rightInt >= 0 {
+				result := int64(1)
+				for i := int64(0); i < rightInt; i++ {
+					result *= leftInt
+				}
+
+				return result, nil
+			}
+		}
+	}
+
+	leftFloat, ok := toFloat(left)
+	if !ok {
+		return nil, unsupported
+	}
+
+	rightFloat, ok := toFloat(right)
+	if !ok {
+		return nil, unsupported
+	}
+
+	switch operator {
+	case "+":
+		return leftFloat + rightFloat, nil
+	case "-":
+		return leftFloat - rightFloat, nil
+	case "*":
+		return leftFloat * rightFloat, nil
+	case "/", "//", "%":
+		if rightFloat == 0 {
+			return nil, errors.New("division by zero")
+		}
+
+		switch operator {
+		case "/":
+			return leftFloat / rightFloat, nil
+		case "//":
+			return math.Floor(leftFloat / rightFloat), nil
+		default:
+			return leftFloat - math.Floor(leftFloat/rightFloat)*rightFloat, nil
+		}
+	case "**":
+		return math.Pow(leftFloat, rightFloat), nil
+	}
+
+	return nil, unsupported
+}
+
+func compare(operator string, left, right any) (bool, error) {
+	switch operator {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in", "not in":
+		result, err := in(left, right)
+		return result == (operator == "in"), err
+	}
+
+	if u, ok := left.(undefined); ok {
+		return false, u.err()
+	}
+
+	if u, ok := right.(undefined); ok {
+		return false, u.err()
+	}
+
+	var order int
+
+	leftString, leftIsString := left.(string)
+	rightString, rightIsString := right.(string)
+	leftFloat, leftIsNumber := toFloat(left)
+	rightFloat, rightIsNumber := toFloat(right)
+
+	switch {
+	case leftIsString && rightIsString:
+		order = strings.Compare(leftString, rightString)
+	case leftIsNumber && rightIsNumber:
+		switch {
+		case leftFloat < rightFloat:
+			order = -1
+		case leftFloat > rightFloat:
+			order = 1
+		}
+	default:
+		return false, fmt.Errorf("%s not supported between instances of %s and %s", quoteString(operator), quoteString(typeName(left)), quoteString(typeName(right)))
+	}
+
+	switch operator {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	default:
+		return order >= 0, nil
+	}
+}
+
+func in(item, container any) (bool, error) {
+	switch container := container.(type) {
+	case string:
+		substring, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("'in <string>' requires string as left operand, not %s", typeName(item))
+		}
+
+		return strings.Contains(container, substring), nil
+	case map[string]any:
+		key, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+
+		_, ok = container[key]
+		return ok, nil
+	case []any:
+		for _, candidate := range container {
+			if equal(item, candidate) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	case undefined:
+		return false, nil
+	}
+
+	return false, fmt.Errorf("argument of type %s is not iterable", quoteString(typeName(container)))
+}
+
+// equal compares values like == in Python, in which True and False equal 1 and
+// 0.
+func equal(left, right any) bool {
+	leftFloat, leftIsNumber := toNumber(left)
+	rightFloat, rightIsNumber := toNumber(right)
+	if leftIsNumber && rightIsNumber {
+		return leftFloat == rightFloat
+	}
+
+	switch left := left.(type) {
+	case []any:
+		right, ok := right.([]any)
+		if !ok || len(left) != len(right) {
+			return false
+		}
+
+		for i := range left {
+			if !equal(left[i], right[i]) {
+				return false
+			}
+		}
+
+		return true
+	case map[string]any:
+		right, ok := right.(map[string]any)
+		if !ok || len(left) != len(right) {
+			return false
+		}
+
+		for key, value := range left {
+			if other, ok := right[key]; !ok || !equal(value, other) {
+				return false
+			}
+		}
+
+		return true
+	case undefined:
+		_, ok := right.(undefined)
+		return ok
+	}
+
+	return left == right
+}
+
+func truthy(value any) bool {
+	switch value := value.(type) {
+	case nil, undefined:
+		return false
+	case bool:
+		return value
+	case int64:
+		return value != 0
+	case float64:
+		return value != 0
+	case string:
+		return value != ""
+	case []any:
+		return len(value) > 0
+	case map[string]any:
+		return len(value) > 0
+	}
+
+	return true
+}
+
+// toFloat returns numbers as float64. Booleans are not numbers here, unlike in
+// Python.
+func toFloat(value any) (float64, bool) {
+	switch value := value.(type) {
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+
+	return 0, false
+}
+
+// toNumber returns numbers and booleans, which are numbers in Python, as
+// float64.
+func toNumber(value any) (float64, bool) {
+	switch value {
+	case true:
+		return 1, true
+	case false:
+		return 0, true
+	}
+
+	return toFloat(value)
+}
+
+// str returns value as a string, like str in Python, and writes undefined
+// values as Options.Undefined returns.
+func (r *renderer) str(value any) string {
+	if u, ok := value.(undefined); ok {
+		if r.options.Undefined == nil {
+			return ""
+		}
+
+		return r.options.Undefined(u.name)
+	}
+
+	return pyStr(value)
+}
+
+// pyStr returns value as a string, like str in Python.
+func pyStr(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case nil:
+		return "None"
+	case bool:
+		if value {
+			return "True"
+		}
+
+		return "False"
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case float64:
+		return formatFloat(value)
+	case []any:
+		items := make([]string, len(value))
+		for i, item := range value {
+			items[i] = pyReprValue(item)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		keys := sortedKeys(value)
+
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			items[i] = pyRepr(key) + ": " + pyReprValue(value[key])
+		}
+
+		return "{" + strings.Join(items, ", ") + "}"
+	case undefined:
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
+
+// pyReprValue returns value as Python writes it in a list or dict.
+func pyReprValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		return pyRepr(value)
+	case undefined:
+		return "Undefined"
+	}
+
+	return pyStr(value)
+}
+
+// pyRepr quotes s like repr does in Python.
+func pyRepr(s string) string {
+	quote := '\''
+	if strings.ContainsRune(s, '\'') && !strings.ContainsRune(s, '"') {
+		quote = '"'
+	}
+
+	var result strings.Builder
+
+	result.WriteRune(quote)
+	for _, c := range s {
+		switch {
+		case c == '\\':
+			result.WriteString(`\\`)
+		case c == quote:
+			result.WriteRune('\\')
+			result.WriteRune(c)
+		case c == '\n':
+			result.WriteString(`\n`)
+		case c == '\r':
+			result.WriteString(`\r`)
+		case c == '\t':
+			result.WriteString(`\t`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&result, `\x%02x`, c)
+		default:
+			result.WriteRune(c)
+		}
+	}
+	result.WriteRune(quote)
+
+	return result.String()
+}
+
+// formatFloat formats f like repr does in Python: with a decimal point, and in
+// exponent notation for very small or large numbers.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+
+	exponential := strconv.FormatFloat(f, 'e', -1, 64)
+
+	exponent, err := strconv.Atoi(exponential[strings.IndexByte(exponential, 'e')+1:])
+	if err != nil || f != 0 && (exponent < -4 || exponent >= 16) {
+		return exponential
+	}
+
+	decimal := strconv.FormatFloat(f, 'f', -1, 64)
+	if !strings.ContainsRune(decimal, '.') {
+		decimal += ".0"
+	}
+
+	return decimal
+}
+
+// typeName returns the name of the Python type of value, for errors.
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "NoneType"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case float64:
+		return "float"
+	case string:
+		return "str"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "dict"
+	case undefined:
+		return "Undefined"
+	case function:
+		return "function"
+	}
+
+	return fmt.Sprintf("%T", value)
+}
+
+func sortedKeys(mapping map[string]any) []string {
+	keys := make([]string, 0, len(mapping))
+	for key := range mapping {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// normalize converts the numbers of a decoded document to int64 or float64,
+// the only number types templates handle.
+func normalize(value any) any {
+	switch value := value.(type) {
+	case int:
+		return int64(value)
+	case int32:
+		return int64(value)
+	case float32:
+		return float64(value)
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := value.Float64()
+		return f
+	case []any:
+		items := make([]any, len(value))
+		for i, item := range value {
+			items[i] = normalize(item)
+		}
+
+		return items
+	case map[string]any:
+		mapping := make(map[string]any, len(value))
+		for key, item := range value {
+			mapping[key] = normalize(item)
+		}
+
+		return mapping
+	}
+
+	return value
+}
+
+// lineError adds the line of the tag or expression err was returned from.
+func lineError(line int, err error) error {
+	var templateErr *Error
+	if errors.As(err, &templateErr) {
+		return err
+	}
+
+	return &Error{Line: line, Message: err.Error()}
+}
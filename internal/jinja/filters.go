@@ -0,0 +1,869 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package jinja
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+type filter func(r *renderer, value any, args []any, keywords map[string]any) (any, error)
+
+type test func(r *renderer, value any, args []any, keywords map[string]any) (bool, error)
+
+// filters are the supported filters of Jinja.
+var filters = map[string]filter{
+	"abs":        absFilter,
+	"capitalize": stringFilter(capitalize),
+	"count":      lengthFilter,
+	"d":          defaultFilter,
+	"default":    defaultFilter,
+	"first":      firstFilter,
+	"float":      floatFilter,
+	"indent":     indentFilter,
+	"int":        intFilter,
+	"items":      itemsFilter,
+	"join":       joinFilter,
+	"last":       lastFilter,
+	"length":     lengthFilter,
+	"list":       listFilter,
+	"lower":      stringFilter(strings.ToLower),
+	"replace":    replaceFilter,
+	"sort":       sortFilter,
+	"string":     stringFilter(func(s string) string { return s }),
+	"title":      stringFilter(title),
+	"tojson":     toJSONFilter,
+	"trim":       trimFilter,
+	"unique":     uniqueFilter,
+	"upper":      stringFilter(strings.ToUpper),
+}
+
+// tests are the supported tests of Jinja.
+var tests = map[string]test{
+	"boolean":     typeTest(func(value any) bool { _, ok := value.(bool); return ok }),
+	"defined":     typeTest(func(value any) bool { _, ok := value.(undefined); return !ok }),
+	"divisibleby": divisibleByTest,
+	"even":        remainderTest(2, 0),
+	"false":       typeTest(func(value any) bool { return value == false }),
+	"float":       typeTest(func(value any) bool { _, ok := value.(float64); return ok }),
+	"integer":     typeTest(func(value any) bool { _, ok := value.(int64); return ok }),
+	"iterable":    typeTest(isIterable),
+	"lower":       stringTest(func(s string) bool { return isCased(s, unicode.IsLower) }),
+	"mapping":     typeTest(func(value any) bool { _, ok := value.(map[string]any); return ok }),
+	"none":        typeTest(func(value any) bool { return value == nil }),
+	"number":      typeTest(func(value any) bool { _, ok := toNumber(value); return ok }),
+	"odd":         remainderTest(2, 1),
+	"sequence":    typeTest(isIterable),
+	"string":      typeTest(func(value any) bool { _, ok := value.(string); return ok }),
+	"true":        typeTest(func(value any) bool { return value == true }),
+	"undefined":   typeTest(func(value any) bool { _, ok := value.(undefined); return ok }),
+	"upper":       stringTest(func(s string) bool { return isCased(s, unicode.IsUpper) }),
+}
+
+// filterParameters are the parameters of the filters after the value, in the
+// order of the filters of Jinja. Parameters which are not listed, such as the
+// attribute of join and sort, are not supported.
+var filterParameters = map[string][]string{
+	"d":       {"default_value", "boolean"},
+	"default": {"default_value", "boolean"},
+	"float":   {"default"},
+	"indent":  {"width", "first", "blank"},
+	"int":     {"default", "base"},
+	"join":    {"d"},
+	"replace": {"old", "new", "count"},
+	"sort":    {"reverse", "case_sensitive"},
+	"trim":    {"chars"},
+	"unique":  {"case_sensitive"},
+}
+
+// testParameters are the parameters of the tests after the value.
+var testParameters = map[string][]string{
+	"divisibleby": {"num"},
+}
+
+// methodParameters are the parameters of the supported methods. Like in
+// Python, only the arguments of split can be passed by name.
+var methodParameters = map[string][]string{
+	"endswith":   {"suffix"},
+	"get":        {"key", "default"},
+	"lstrip":     {"chars"},
+	"replace":    {"old", "new", "count"},
+	"rstrip":     {"chars"},
+	"split":      {"sep", "maxsplit"},
+	"startswith": {"prefix"},
+	"strip":      {"chars"},
+}
+
+// checkArguments reports arguments passed to the filter, test or method called
+// name which it does not support, rather than ignoring them.
+func checkArguments[T any](name string, parameters []string, keywordsAllowed bool, positional []T, keywords map[string]T) error {
+	if len(positional) > len(parameters) {
+		return fmt.Errorf("%s expected at most %d %s, got %d", name, len(parameters), plural(len(parameters), "argument"), len(positional))
+	}
+
+	if len(keywords) > 0 && !keywordsAllowed {
+		return fmt.Errorf("%s takes no keyword arguments", name)
+	}
+
+	for _, keyword := range slices.Sorted(maps.Keys(keywords)) {
+		i := slices.Index(parameters, keyword)
+		switch {
+		case i < 0:
+			return fmt.Errorf("%s got an unexpected keyword argument %s", name, quoteString(keyword))
+		case i < len(positional):
+			return fmt.Errorf("%s got multiple values for argument %s", name, quoteString(keyword))
+		}
+	}
+
+	return nil
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return word
+	}
+
+	return word + "s"
+}
+
+// argument returns the argument of a filter, test or function at position i or
+// with the given name, or fallback when it is not passed.
+func argument(args []any, keywords map[string]any, i int, name string, fallback any) any {
+	if i < len(args) {
+		return args[i]
+	}
+
+	if value, ok := keywords[name]; ok {
+		return value
+	}
+
+	return fallback
+}
+
+func stringFilter(fn func(string) string) filter {
+	return func(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+		return fn(r.str(value)), nil
+	}
+}
+
+func absFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	switch value := value.(type) {
+	case int64:
+		if value < 0 {
+			return -value, nil
+		}
+
+		return value, nil
+	case float64:
+		return math.Abs(value), nil
+	case undefined:
+		return nil, value.err()
+	}
+
+	return nil, fmt.Errorf("bad operand type for abs(): %s", quoteString(typeName(value)))
+}
+
+func defaultFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	fallback := argument(args, keywords, 0, "default_value", "")
+
+	if _, ok := value.(undefined); ok || truthy(argument(args, keywords, 1, "boolean", false)) && !truthy(value) {
+		return fallback, nil
+	}
+
+	return value, nil
+}
+
+func firstFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return undefined{name: "None"}, nil
+	}
+
+	return items[0], nil
+}
+
+func lastFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return undefined{name: "None"}, nil
+	}
+
+	return items[len(items)-1], nil
+}
+
+func floatFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	fallback := argument(args, keywords, 0, "default", 0.0)
+
+	switch value := value.(type) {
+	case int64:
+		return float64(value), nil
+	case float64:
+		return value, nil
+	case bool:
+		if value {
+			return 1.0, nil
+		}
+
+		return 0.0, nil
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return f, nil
+		}
+	}
+
+	return fallback, nil
+}
+
+func intFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	fallback := argument(args, keywords, 0, "default", int64(0))
+	base, baseIsInt := argument(args, keywords, 1, "base", int64(10)).(int64)
+
+	switch value := value.(type) {
+	case int64:
+		return value, nil
+	case float64:
+		return int64(value), nil
+	case bool:
+		if value {
+			return int64(1), nil
+		}
+
+		return int64(0), nil
+	case string:
+		value = strings.ReplaceAll(strings.TrimSpace(value), "_", "")
+
+		if baseIsInt {
+			if i, err := strconv.ParseInt(withoutBasePrefix(value, base), int(base), 64); err == nil {
+				return i, nil
+			}
+		}
+
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return int64(f), nil
+		}
+	}
+
+	return fallback, nil
+}
+
+// withoutBasePrefix removes the 0b, 0o or 0x prefix Python allows before the
+// digits of an integer in base 2, 8 or 16.
+func withoutBasePrefix(s string, base int64) string {
+	prefix := map[int64]string{2: "0b", 8: "0o", 16: "0x"}[base]
+
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	if prefix != "" && len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		s = s[len(prefix):]
+	}
+
+	return sign + s
+}
+
+// indentFilter indents every line but the first, like the indent filter of
+// Jinja 3.
+func indentFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	var indentation string
+
+	switch width := argument(args, keywords, 0, "width", int64(4)).(type) {
+	case int64:
+		indentation = strings.Repeat(" ", int(max(width, 0)))
+	case string:
+		indentation = width
+	default:
+		return nil, fmt.Errorf("expected the width of indent to be an integer or string, got %s", typeName(width))
+	}
+
+	first := truthy(argument(args, keywords, 1, "first", false))
+	blank := truthy(argument(args, keywords, 2, "blank", false))
+
+	lines := strings.Split(r.str(value)+"\n", "\n")
+	lines = lines[:len(lines)-1]
+
+	var result strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			result.WriteByte('\n')
+
+			if line != "" || blank {
+				result.WriteString(indentation)
+			}
+		}
+
+		result.WriteString(line)
+	}
+
+	if first {
+		return indentation + result.String(), nil
+	}
+
+	return result.String(), nil
+}
+
+func itemsFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	switch value := value.(type) {
+	case map[string]any:
+		return mappingItems(value), nil
+	case undefined:
+		return []any{}, nil
+	}
+
+	return nil, fmt.Errorf("can only get item pairs from a mapping, got %s", typeName(value))
+}
+
+func joinFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	separator, ok := argument(args, keywords, 0, "d", "").(string)
+	if !ok {
+		return nil, fmt.Errorf("expected the separator of join to be a string")
+	}
+
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := make([]string, len(items))
+	for i, item := range items {
+		strs[i] = r.str(item)
+	}
+
+	return strings.Join(strs, separator), nil
+}
+
+func lengthFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	switch value := value.(type) {
+	case string:
+		return int64(len([]rune(value))), nil
+	case []any:
+		return int64(len(value)), nil
+	case map[string]any:
+		return int64(len(value)), nil
+	case undefined:
+		return int64(0), nil
+	}
+
+	return nil, fmt.Errorf("object of type %s has no len()", quoteString(typeName(value)))
+}
+
+func listFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]any{}, items...), nil
+}
+
+func replaceFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	old, okOld := argument(args, keywords, 0, "old", nil).(string)
+	replacement, okNew := argument(args, keywords, 1, "new", nil).(string)
+	if !okOld || !okNew {
+		return nil, fmt.Errorf("expected the old and new arguments of replace to be strings")
+	}
+
+	count := int64(-1)
+	if c, ok := argument(args, keywords, 2, "count", nil).(int64); ok {
+		count = c
+	}
+
+	return strings.Replace(r.str(value), old, replacement, int(count)), nil
+}
+
+func sortFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	reverse := truthy(argument(args, keywords, 0, "reverse", false))
+	caseSensitive := truthy(argument(args, keywords, 1, "case_sensitive", false))
+
+	sorted := append([]any{}, items...)
+
+	var sortErr error
+	sort.SliceStable(sorted, func(i, j int) bool {
+		left, right := sorted[i], sorted[j]
+		if reverse {
+			left, right = right, left
+		}
+
+		if !caseSensitive {
+			left, right = foldCase(left), foldCase(right)
+		}
+
+		less, err := compare("<", left, right)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+
+		return less
+	})
+
+	return sorted, sortErr
+}
+
+// toJSONFilter writes value as JSON like the tojson filter of Jinja, which
+// uses the separators of Python and escapes characters which are special in
+// HTML.
+func toJSONFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	var result strings.Builder
+	if err := writeJSON(&result, value); err != nil {
+		return nil, err
+	}
+
+	return result.String(), nil
+}
+
+func writeJSON(result *strings.Builder, value any) error {
+	switch value := value.(type) {
+	case nil:
+		result.WriteString("null")
+	case bool:
+		result.WriteString(strconv.FormatBool(value))
+	case int64:
+		result.WriteString(strconv.FormatInt(value, 10))
+	case float64:
+		switch {
+		case math.IsInf(value, 1):
+			result.WriteString("Infinity")
+		case math.IsInf(value, -1):
+			result.WriteString("-Infinity")
+		case math.IsNaN(value):
+			result.WriteString("NaN")
+		default:
+			result.WriteString(formatFloat(value))
+		}
+	case string:
+		result.WriteByte('"')
+		for _, c := range value {
+			switch {
+			case c == '"':
+				result.WriteString(`\"`)
+			case c == '\\':
+				result.WriteString(`\\`)
+			case c == '\n':
+				result.WriteString(`\n`)
+			case c == '\r':
+				result.WriteString(`\r`)
+			case c == '\t':
+				result.WriteString(`\t`)
+			case c < 0x20 || c > 0x7e || c == '<' || c == '>' || c == '&' || c == '\'':
+				for _, unit := range utf16.Encode([]rune{c}) {
+					fmt.Fprintf(result, `\u%04x`, unit)
+				}
+			default:
+				result.WriteRune(c)
+			}
+		}
+		result.WriteByte('"')
+	case []any:
+		result.WriteByte('[')
+		for i, item := range value {
+			if i > 0 {
+				result.WriteString(", ")
+			}
+
+			if err := writeJSON(result, item); err != nil {
+				return err
+			}
+		}
+		result.WriteByte(']')
+	case map[string]any:
+		result.WriteByte('{')
+		for i, key := range sortedKeys(value) {
+			if i > 0 {
+				result.WriteString(", ")
+			}
+
+			if err := writeJSON(result, key); err != nil {
+				return err
+			}
+
+			result.WriteString(": ")
+
+			if err := writeJSON(result, value[key]); err != nil {
+				return err
+			}
+		}
+		result.WriteByte('}')
+	case undefined:
+		return value.err()
+	default:
+		return fmt.Errorf("object of type %s is not JSON serializable", typeName(value))
+	}
+
+	return nil
+}
+
+func trimFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	if chars, ok := argument(args, keywords, 0, "chars", nil).(string); ok {
+		return strings.Trim(r.str(value), chars), nil
+	}
+
+	return strings.TrimSpace(r.str(value)), nil
+}
+
+func uniqueFilter(r *renderer, value any, args []any, keywords map[string]any) (any, error) {
+	items, err := iterate(value)
+	if err != nil {
+		return nil, err
+	}
+
+	caseSensitive := truthy(argument(args, keywords, 0, "case_sensitive", false))
+
+	var unique, seen []any
+	for _, item := range items {
+		key := item
+		if !caseSensitive {
+			key = foldCase(item)
+		}
+
+		found := false
+		for _, other := range seen {
+			if equal(key, other) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			seen = append(seen, key)
+			unique = append(unique, item)
+		}
+	}
+
+	return unique, nil
+}
+
+func foldCase(value any) any {
+	if s, ok := value.(string); ok {
+		return strings.ToLower(s)
+	}
+
+	return value
+}
+
+func capitalize(s string) string {
+	runes := []rune(strings.ToLower(s))
+	if len(runes) > 0 {
+		runes[0] = unicode.ToTitle(runes[0])
+	}
+
+	return string(runes)
+}
+
+// title starts every word with an upper case letter and lowers the other
+// letters, like the title filter of Jinja, in which words start after spaces,
+// dashes and opening brackets.
+func title(s string) string {
+	var result strings.Builder
+
+	wordStart := true
+	for _, c := range s {
+		separator := unicode.IsSpace(c) || strings.ContainsRune("-({[<", c)
+
+		switch {
+		case separator:
+			result.WriteRune(c)
+		case wordStart:
+			result.WriteRune(unicode.ToUpper(c))
+		default:
+			result.WriteRune(unicode.ToLower(c))
+		}
+
+		wordStart = separator
+	}
+
+	return result.String()
+}
+
+func typeTest(fn func(value any) bool) test {
+	return func(r *renderer, value any, args []any, keywords map[string]any) (bool, error) {
+		return fn(value), nil
+	}
+}
+
+// stringTest tests value as a string, like the lower and upper tests of Jinja
+// do with any value.
+func stringTest(fn func(s string) bool) test {
+	return func(r *renderer, value any, args []any, keywords map[string]any) (bool, error) {
+		return fn(r.str(value)), nil
+	}
+}
+
+// isCased reports whether s has cased letters and fn is true for all of them,
+// like str.islower and str.isupper in Python.
+func isCased(s string, fn func(c rune) bool) bool {
+	cased := false
+	for _, c := range s {
+		if unicode.IsUpper(c) || unicode.IsLower(c) || unicode.IsTitle(c) {
+			if !fn(c) {
+				return false
+			}
+
+			cased = true
+		}
+	}
+
+	return cased
+}
+
+// remainderTest tests that the remainder of dividing a value by divisor is
+// remainder, like the even and odd tests of Jinja.
+func remainderTest(divisor, remainder int64) test {
+	return func(r *renderer, value any, args []any, keywords map[string]any) (bool, error) {
+		return hasRemainder(value, divisor, remainder)
+	}
+}
+
+func divisibleByTest(r *renderer, value any, args []any, keywords map[string]any) (bool, error) {
+	divisor := argument(args, keywords, 0, "num", nil)
+	if divisor == nil {
+		return false, fmt.Errorf("divisibleby is missing the argument num")
+	}
+
+	return hasRemainder(value, divisor, 0)
+}
+
+// hasRemainder reports whether value % divisor equals remainder, which works
+// with integers and floats like in Python.
+func hasRemainder(value, divisor any, remainder int64) (bool, error) {
+	result, err := binary("%", value, divisor)
+	if err != nil {
+		return false, err
+	}
+
+	return equal(result, remainder), nil
+}
+
+// isIterable reports whether value can be iterated, which undefined values can
+// like in Jinja.
+func isIterable(value any) bool {
+	switch value.(type) {
+	case string, []any, map[string]any, undefined:
+		return true
+	}
+
+	return false
+}
+
+func mappingItems(mapping map[string]any) []any {
+	keys := sortedKeys(mapping)
+
+	items := make([]any, len(keys))
+	for i, key := range keys {
+		items[i] = []any{key, mapping[key]}
+	}
+
+	return items
+}
+
+// lookupMethod returns the supported method of a string or mapping with the
+// given name.
+func lookupMethod(target any, name string) (function, bool) {
+	switch target := target.(type) {
+	case string:
+		return stringMethod(target, name)
+	case map[string]any:
+		return mappingMethod(target, name)
+	}
+
+	return nil, false
+}
+
+func stringMethod(s string, name string) (function, bool) {
+	stringArg := func(args []any, i int) (string, bool) {
+		if i >= len(args) || args[i] == nil {
+			return "", false
+		}
+
+		arg, ok := args[i].(string)
+		return arg, ok
+	}
+
+	trim := func(trimSpace func(string) string, trimChars func(string, string) string) function {
+		return func(args []any, keywords map[string]any) (any, error) {
+			if chars, ok := stringArg(args, 0); ok {
+				return trimChars(s, chars), nil
+			}
+
+			return trimSpace(s), nil
+		}
+	}
+
+	switch name {
+	case "lower":
+		return func(args []any, keywords map[string]any) (any, error) { return strings.ToLower(s), nil }, true
+	case "upper":
+		return func(args []any, keywords map[string]any) (any, error) { return strings.ToUpper(s), nil }, true
+	case "strip":
+		return trim(strings.TrimSpace, strings.Trim), true
+	case "lstrip":
+		return trim(func(s string) string { return strings.TrimLeftFunc(s, unicode.IsSpace) }, strings.TrimLeft), true
+	case "rstrip":
+		return trim(func(s string) string { return strings.TrimRightFunc(s, unicode.IsSpace) }, strings.TrimRight), true
+	case "startswith", "endswith":
+		return func(args []any, keywords map[string]any) (any, error) {
+			affix, ok := stringArg(args, 0)
+			if !ok {
+				return nil, fmt.Errorf("expected the argument of %s to be a string", name)
+			}
+
+			if name == "startswith" {
+				return strings.HasPrefix(s, affix), nil
+			}
+
+			return strings.HasSuffix(s, affix), nil
+		}, true
+	case "split":
+		return func(args []any, keywords map[string]any) (any, error) {
+			limit := -1
+			if maxSplit, ok := argument(args, keywords, 1, "maxsplit", nil).(int64); ok && maxSplit >= 0 {
+				limit = int(maxSplit) + 1
+			}
+
+			var parts []string
+			if separator, ok := argument(args, keywords, 0, "sep", nil).(string); ok {
+				if separator == "" {
+					return nil, fmt.Errorf("empty separator")
+				}
+
+				parts = strings.SplitN(s, separator, limit)
+			} else {
+				parts = strings.Fields(s)
+				if limit > 0 && len(parts) > limit {
+					rest := strings.TrimLeftFunc(s, unicode.IsSpace)
+					for i := 0; i < limit-1; i++ {
+						rest = strings.TrimLeftFunc(strings.TrimPrefix(rest, parts[i]), unicode.IsSpace)
+					}
+
+					parts = append(parts[:limit-1], rest)
+				}
+			}
+
+			result := make([]any, len(parts))
+			for i, part := range parts {
+				result[i] = part
+			}
+
+			return result, nil
+		}, true
+	case "replace":
+		return func(args []any, keywords map[string]any) (any, error) {
+			old, okOld := stringArg(args, 0)
+			replacement, okNew := stringArg(args, 1)
+			if !okOld || !okNew {
+				return nil, fmt.Errorf("expected the arguments of replace to be strings")
+			}
+
+			count := int64(-1)
+			if len(args) > 2 {
+				if c, ok := args[2].(int64); ok {
+					count = c
+				}
+			}
+
+			return strings.Replace(s, old, replacement, int(count)), nil
+		}, true
+	}
+
+	return nil, false
+}
+
+func mappingMethod(mapping map[string]any, name string) (function, bool) {
+	switch name {
+	case "get":
+		return func(args []any, keywords map[string]any) (any, error) {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("get expected at least 1 argument, got 0")
+			}
+
+			if key, ok := args[0].(string); ok {
+				if value, ok := mapping[key]; ok {
+					return value, nil
+				}
+			}
+
+			return argument(args, nil, 1, "", nil), nil
+		}, true
+	case "items":
+		return func(args []any, keywords map[string]any) (any, error) { return mappingItems(mapping), nil }, true
+	case "keys":
+		return func(args []any, keywords map[string]any) (any, error) { return iterate(mapping) }, true
+	case "values":
+		return func(args []any, keywords map[string]any) (any, error) {
+			keys := sortedKeys(mapping)
+
+			values := make([]any, len(keys))
+			for i, key := range keys {
+				values[i] = mapping[key]
+			}
+
+			return values, nil
+		}, true
+	}
+
+	return nil, false
+}
+
+// rangeFunction returns a list of integers like range in Python.
+func rangeFunction(args []any, keywords map[string]any) (any, error) {
+	if len(keywords) > 0 {
+		return nil, fmt.Errorf("range takes no keyword arguments")
+	}
+
+	bounds := make([]int64, len(args))
+	for i, arg := range args {
+		bound, ok := arg.(int64)
+		if !ok {
+			return nil, fmt.Errorf("expected the arguments of range to be integers, got %s", typeName(arg))
+		}
+
+		bounds[i] = bound
+	}
+
+	var start, stop, step int64 = 0, 0, 1
+	switch len(bounds) {
+	case 1:
+		stop = bounds[0]
+	case 2:
+		start, stop = bounds[0], bounds[1]
+	case 3:
+		start, stop, step = bounds[0], bounds[1], bounds[2]
+	default:
+		return nil, fmt.Errorf("range expected 1 to 3 arguments, got %d", len(bounds))
+	}
+
+	if step == 0 {
+		return nil, fmt.Errorf("range step cannot be zero")
+	}
+
+	var result []any
+	for i := start; step > 0 && i < stop || step < 0 && i > stop; i += step {
+		result = append(result, i)
+		if len(result) > 100000 {
+			return nil, fmt.Errorf("range is too long")
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,79 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package jinja renders templates in the subset of the Jinja template language
+// which cloud-init user data templates use, so that they can be previewed
+// without Python.
+//
+// Templates can write expressions with {{ }}, and use the if, for, set and raw
+// tags, comments and whitespace control. Expressions support the literals and
+// operators of Jinja, the filters and tests cloud-init templates commonly use,
+// the string methods split, startswith, endswith, strip, lstrip, rstrip, lower,
+// upper and replace, the mapping methods get, items, keys and values, and the
+// range function. Other tags, filters, tests, methods and arguments are
+// reported as errors rather than rendered differently from Jinja.
+//
+// Values are those of a decoded JSON document: nil, bool, int64, float64,
+// string, []any and map[string]any. Mappings are iterated in the order of
+// their keys.
+package jinja
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Options changes how templates are rendered, like the options of a Jinja
+// environment.
+type Options struct {
+	// TrimBlocks removes the first newline after a block tag or comment, like
+	// the trim_blocks option of Jinja.
+	TrimBlocks bool
+
+	// Undefined returns the text written in place of an undefined variable
+	// with the given name. Undefined variables are written as empty strings
+	// when it is nil, like in Jinja.
+	Undefined func(name string) string
+}
+
+// Error is an error in a template, at the line it was found.
+type Error struct {
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Render renders the template source with the variables of context. Like
+// Jinja, a single newline at the end of source is removed.
+func Render(source string, context map[string]any, options Options) (string, error) {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.TrimSuffix(source, "\n")
+
+	segments, err := splitSegments(source, options.TrimBlocks)
+	if err != nil {
+		return "", err
+	}
+
+	p := &parser{segments: segments}
+
+	body, err := p.parseTemplate()
+	if err != nil {
+		return "", err
+	}
+
+	globals := make(map[string]any, len(context))
+	for name, value := range context {
+		globals[name] = normalize(value)
+	}
+
+	r := &renderer{options: options}
+	if err := r.renderNodes(body, &scope{vars: globals}); err != nil {
+		return "", err
+	}
+
+	return r.output.String(), nil
+}
@@ -0,0 +1,464 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package jinja
+
+import (
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	context := map[string]any{
+		"v1": map[string]any{
+			"local_hostname": "web-01.example.com",
+			"region":         "us-east-1",
+			"distro":         "ubuntu",
+			"cpus":           4,
+		},
+		"ds": map[string]any{
+			"meta_data": map[string]any{
+				"tags": []any{"web", "prod"},
+				"disks": map[string]any{
+					"data": "/dev/sdb",
+					"logs": "/dev/sdc",
+				},
+			},
+		},
+		"ratio": 0.5,
+		"empty": "",
+	}
+
+	testCases := map[string]struct {
+		source   string
+		expected string
+	}{
+		"text": {
+			source:   "#cloud-config\npackages: [git]\n",
+			expected: "#cloud-config\npackages: [git]",
+		},
+		"variables": {
+			source:   "hostname: {{ v1.local_hostname }}\nregion: {{ v1['region'] }}\n",
+			expected: "hostname: web-01.example.com\nregion: us-east-1",
+		},
+		"undefined variables are empty": {
+			source:   "[{{ v1.zone }}][{{ missing }}]",
+			expected: "[][]",
+		},
+		"methods": {
+			source:   "{{ v1.local_hostname.split('.')[0] }} {{ v1.distro.upper() }} {{ v1.get('zone', 'none') }}",
+			expected: "web-01 UBUNTU none",
+		},
+		"filters": {
+			source:   "{{ v1.zone | default('us-east-1a') }} {{ ds.meta_data.tags | join(',') }} {{ v1.distro | title }} {{ ds.meta_data.tags | length }}",
+			expected: "us-east-1a web,prod Ubuntu 2",
+		},
+		"default filter of a falsy value": {
+			source:   "[{{ empty | default('x') }}][{{ empty | default('x', true) }}]",
+			expected: "[][x]",
+		},
+		"arithmetic": {
+			source:   "{{ v1.cpus * 2 }} {{ v1.cpus / 8 }} {{ 7 // 2 }} {{ -7 % 3 }} {{ ratio + 1 }} {{ 2 ** 10 }} {{ 'a' ~ 1 }}",
+			expected: "8 0.5 3 2 1.5 1024 a1",
+		},
+		"if": {
+			source:   "{% if v1.distro == 'centos' %}\nyum\n{% elif v1.distro in ['debian', 'ubuntu'] %}\napt\n{% else %}\nother\n{% endif %}\n",
+			expected: "apt\n",
+		},
+		"if with tests": {
+			source:   "{% if v1.zone is defined %}zone{% elif v1.cpus is divisibleby 2 and v1.cpus is not odd %}even{% endif %}",
+			expected: "even",
+		},
+		"for": {
+			source:   "{% for tag in ds.meta_data.tags %}\n- {{ loop.index }}/{{ loop.length }}: {{ tag }}\n{% endfor %}\n",
+			expected: "- 1/2: web\n- 2/2: prod\n",
+		},
+		"for over items with else": {
+			source:   "{% for name, device in ds.meta_data.disks.items() %}{{ name }}={{ device }} {% endfor %}{% for x in [] %}x{% else %}none{% endfor %}",
+			expected: "data=/dev/sdb logs=/dev/sdc none",
+		},
+		"for with a filter": {
+			source:   "{% for i in range(10) if i is even %}{{ i }}{% endfor %}",
+			expected: "02468",
+		},
+		"set": {
+			source:   "{% set short = v1.local_hostname.split('.')[0] %}{% set a, b = [1, 2] %}{{ short }} {{ a + b }}",
+			expected: "web-01 3",
+		},
+		"whitespace control": {
+			source:   "a  \n  {%- if true -%}  \n  b  \n  {%- endif %}  c",
+			expected: "ab  c",
+		},
+		"comments and raw": {
+			source:   "{# comment #}\n{% raw %}{{ not rendered }}{% endraw %}",
+			expected: "{{ not rendered }}",
+		},
+		"python values": {
+			source:   "{{ ds.meta_data.tags }} {{ ds.meta_data.disks }} {{ true }} {{ none }} {{ 1.0 }} {{ 1e20 }}",
+			expected: "['web', 'prod'] {'data': '/dev/sdb', 'logs': '/dev/sdc'} True None 1.0 1e+20",
+		},
+		"tojson": {
+			source:   "{{ {'b': [1, 2.5], 'a': \"<it's>\"} | tojson }}",
+			expected: `{"a": "\u003cit\u0027s\u003e", "b": [1, 2.5]}`,
+		},
+		"indent": {
+			source:   "{{ 'a\nb\n\nc' | indent(2) }}|{{ 'a\nb' | indent(2, true) }}",
+			expected: "a\n  b\n\n  c|  a\n  b",
+		},
+		"slices and conditional expressions": {
+			source:   "{{ v1.region[:2] }} {{ [1, 2, 3][::-1] }} {{ 'yes' if v1.cpus > 2 else 'no' }}",
+			expected: "us [3, 2, 1] yes",
+		},
+		"abs filter": {
+			source:   "{{ -3 | abs }} {{ 2.5 | abs }} {{ -ratio | abs }}",
+			expected: "3 2.5 0.5",
+		},
+		"capitalize filter": {
+			source:   "{{ 'hELLO wORLD' | capitalize }}",
+			expected: "Hello world",
+		},
+		"count and length filters": {
+			source:   "{{ [1, 2, 3] | count }} {{ 'héllo' | length }} {{ v1 | length }} {{ missing | length }}",
+			expected: "3 5 4 0",
+		},
+		"default filter": {
+			source:   "{{ missing | d('x') }} {{ none | default('x') }} {{ 0 | d(1, boolean=true) }}",
+			expected: "x None 1",
+		},
+		"first and last filters": {
+			source:   "{{ [1, 2] | first }} {{ 'abc' | first }} {{ [1, 2] | last }} {{ 'abc' | last }} [{{ [] | first }}][{{ [] | last }}]",
+			expected: "1 a 2 c [][]",
+		},
+		"float filter": {
+			source:   "{{ '3.5' | float }} {{ 2 | float }} {{ true | float }} {{ 'x' | float }} {{ 'x' | float(1.5) }} {{ none | float(default=2) }}",
+			expected: "3.5 2.0 1.0 0.0 1.5 2",
+		},
+		"int filter": {
+			source:   "{{ '42' | int }} {{ 3.9 | int }} {{ -3.9 | int }} {{ '1.9' | int }} {{ true | int }} {{ 'x' | int }} {{ 'x' | int(7) }} {{ 'ff' | int(base=16) }} {{ '0x1F' | int(0, 16) }} {{ '101' | int(0, 2) }}",
+			expected: "42 3 -3 1 1 0 7 255 31 5",
+		},
+		"indent filter arguments": {
+			source:   "{{ 'a\n\nb' | indent(2, blank=true) }}|{{ 'a\nb' | indent('> ') }}|{{ 'a\nb' | indent }}",
+			expected: "a\n  \n  b|a\n> b|a\n    b",
+		},
+		"items filter": {
+			source:   "{% for key, value in ds.meta_data.disks | items %}{{ key }}={{ value }} {% endfor %}{{ missing | items | list }}",
+			expected: "data=/dev/sdb logs=/dev/sdc []",
+		},
+		"join filter": {
+			source:   "{{ [1, 2, 3] | join }} {{ [1, 2, 3] | join(d='-') }} {{ 'abc' | join(',') }} {{ {'a': 1, 'b': 2} | join(',') }}",
+			expected: "123 1-2-3 a,b,c a,b",
+		},
+		"list filter": {
+			source:   "{{ 'ab' | list }} {{ ds.meta_data.disks | list }} {{ [1] | list }}",
+			expected: "['a', 'b'] ['data', 'logs'] [1]",
+		},
+		"lower and upper filters": {
+			source:   "{{ 'HeLLo' | lower }} {{ 'HeLLo' | upper }} {{ none | lower }}",
+			expected: "hello HELLO none",
+		},
+		"replace filter": {
+			source:   "{{ 'a-b-c' | replace('-', '+') }} {{ 'a-b-c' | replace('-', '+', 1) }} {{ 12 | replace('1', '3') }}",
+			expected: "a+b+c a+b-c 32",
+		},
+		"sort filter": {
+			source:   "{{ ['b', 'A', 'a', 'C'] | sort }} {{ ['b', 'A', 'a'] | sort(case_sensitive=true) }} {{ ['b', 'A', 'a'] | sort(true) }} {{ [2, 1.5, 3] | sort }} {{ ds.meta_data.disks | sort(reverse=true) }}",
+			expected: "['A', 'a', 'b', 'C'] ['A', 'a', 'b'] ['b', 'A', 'a'] [1.5, 2, 3] ['logs', 'data']",
+		},
+		"string filter": {
+			source:   "{{ (1 | string) ~ 2 }} {{ [1, 'a'] | string }} {{ none | string }}",
+			expected: "12 [1, 'a'] None",
+		},
+		"title filter": {
+			source:   "{{ \"it's a dog-eat-dog wORLD\" | title }} {{ '(hello) [x]' | title }} {{ 'foo1bar' | title }}",
+			expected: "It's A Dog-Eat-Dog World (Hello) [X] Foo1bar",
+		},
+		"tojson filter of a list": {
+			source:   "{{ [true, none, 'é', 1e20] | tojson }}",
+			expected: `[true, null, "\u00e9", 1e+20]`,
+		},
+		"trim filter": {
+			source:   "[{{ '  a b  ' | trim }}][{{ 'xxaxx' | trim('x') }}]",
+			expected: "[a b][a]",
+		},
+		"unique filter": {
+			source:   "{{ ['a', 'A', 'b', 'a'] | unique | list }} {{ ['a', 'A', 'b'] | unique(case_sensitive=true) | join(',') }} {{ [1, 1.0, true, 2] | unique | list }}",
+			expected: "['a', 'b'] a,A,b [1, 2]",
+		},
+		"type tests": {
+			source:   "{{ true is boolean }} {{ 1 is boolean }} {{ none is none }} {{ missing is none }} {{ 1 is integer }} {{ true is integer }} {{ 1.0 is float }} {{ 1 is number }} {{ ratio is number }} {{ true is number }} {{ '1' is number }} {{ 'a' is string }} {{ v1 is mapping }} {{ [] is mapping }}",
+			expected: "True False True False True False True True True True False True True False",
+		},
+		"true and false tests": {
+			source:   "{{ true is true }} {{ 1 is true }} {{ false is false }} {{ 0 is false }}",
+			expected: "True False True False",
+		},
+		"defined and undefined tests": {
+			source:   "{{ v1 is defined }} {{ missing is defined }} {{ missing is undefined }} {{ none is undefined }}",
+			expected: "True False True False",
+		},
+		"number tests": {
+			source:   "{{ 4 is even }} {{ 3 is odd }} {{ 3.0 is odd }} {{ 2.5 is even }} {{ -3 is odd }} {{ 9 is divisibleby 3 }} {{ 9 is divisibleby(2) }} {{ 7.5 is divisibleby 2.5 }}",
+			expected: "True True True False True True False True",
+		},
+		"iterable and sequence tests": {
+			source:   "{{ 'a' is iterable }} {{ [] is sequence }} {{ v1 is sequence }} {{ 1 is iterable }} {{ missing is iterable }} {{ missing is sequence }} {{ none is sequence }}",
+			expected: "True True True False True True False",
+		},
+		"lower and upper tests": {
+			source:   "{{ 'abc1' is lower }} {{ 'Abc' is lower }} {{ '123' is lower }} {{ 'ABC1' is upper }} {{ 'aBC' is upper }} {{ '' is upper }} {{ ['a'] is lower }}",
+			expected: "True False False True False False True",
+		},
+		"negated tests": {
+			source:   "{{ 1 is not string }} {{ v1.cpus is not divisibleby 3 }}",
+			expected: "True True",
+		},
+		"string methods": {
+			source:   "{{ 'Ab'.lower() }} {{ 'Ab'.upper() }} [{{ '  a  '.strip() }}][{{ '  a  '.lstrip() }}][{{ '  a  '.rstrip() }}] {{ 'xxaxx'.strip('x') }} {{ 'xxaxx'.lstrip('x') }} {{ 'xxaxx'.rstrip('x') }}",
+			expected: "ab AB [a][a  ][  a] a axx xxa",
+		},
+		"startswith and endswith methods": {
+			source:   "{{ v1.region.startswith('us-') }} {{ v1.region.endswith('-2') }}",
+			expected: "True False",
+		},
+		"split method": {
+			source:   "{{ 'a,b,,c'.split(',') }} {{ ' a  b c '.split() }} {{ 'a,b,c'.split(',', 1) }} {{ ' a  b c '.split(None, 1) }} {{ 'a b c'.split(maxsplit=1) }} {{ 'a,b'.split(sep=',') }}",
+			expected: "['a', 'b', '', 'c'] ['a', 'b', 'c'] ['a', 'b,c'] ['a', 'b c '] ['a', 'b c'] ['a', 'b']",
+		},
+		"replace method": {
+			source:   "{{ 'a-b-c'.replace('-', '') }} {{ 'a-b-c'.replace('-', '+', 1) }}",
+			expected: "abc a+b-c",
+		},
+		"mapping methods": {
+			source:   "{{ v1.get('region') }} {{ v1.get('zone') }} {{ v1.get('zone', 'none') }} {{ ds.meta_data.disks.keys() | list }} {{ ds.meta_data.disks.values() | list }}",
+			expected: "us-east-1 None none ['data', 'logs'] ['/dev/sdb', '/dev/sdc']",
+		},
+		"range": {
+			source:   "{{ range(3) | list }} {{ range(1, 4) | list }} {{ range(10, 0, -3) | list }} {{ range(0) | list }}",
+			expected: "[0, 1, 2] [1, 2, 3] [10, 7, 4, 1] []",
+		},
+		"arithmetic operators": {
+			source:   "{{ 1 + 2.5 }} {{ 5 - 7 }} {{ 2.5 * 2 }} {{ 4 / 2 }} {{ -7 // 2 }} {{ 7.5 // 2 }} {{ -7.5 % 2 }} {{ 2 ** -1 }} {{ 2 ** 0.5 }} {{ -2 ** 2 }} {{ 0.1 + 0.2 }}",
+			expected: "3.5 -2 5.0 2.0 -4 3.0 0.5 0.5 1.4142135623730951 4 0.30000000000000004",
+		},
+		"sequence operators": {
+			source:   "{{ [1] + [2] }} {{ 'ab' * 2 }} {{ 2 * 'ab' }} {{ [0] * 2 }} [{{ 'ab' * -1 }}] {{ 'a' ~ 1 ~ none }}",
+			expected: "[1, 2] abab abab [0, 0] [] a1None",
+		},
+		"comparisons": {
+			source:   "{{ 1 < 2 < 3 }} {{ 3 > 2 > 2 }} {{ 2 <= 2.0 }} {{ 'a' >= 'b' }} {{ 1 == 1.0 }} {{ '1' != 1 }} {{ true == 1 }} {{ [1, [2]] == [1, [2]] }} {{ {'a': 1} == {'a': 1.0} }} {{ missing == missing }}",
+			expected: "True False True False True True True True True True",
+		},
+		"in and not in": {
+			source:   "{{ 'east' in v1.region }} {{ 'region' in v1 }} {{ 'web' in ds.meta_data.tags }} {{ 'x' not in ds.meta_data.tags }} {{ 1 in [true] }} {{ 'a' in missing }}",
+			expected: "True True True True True False",
+		},
+		"logic operators": {
+			source:   "{{ 0 or 'x' }} {{ 'a' and 'b' }} [{{ '' and 'b' }}] {{ not 1 }} {{ not empty }} {{ none or [] }} {{ 1 and not 0 }}",
+			expected: "x b [] False True [] True",
+		},
+		"unary operators": {
+			source:   "{{ -v1.cpus }} {{ +ratio }} {{ - -1 }}",
+			expected: "-4 0.5 1",
+		},
+		"conditional expressions": {
+			source:   "[{{ 'yes' if false }}] {{ 'a' if v1.cpus > 8 else 'b' if v1.cpus > 2 else 'c' }}",
+			expected: "[] b",
+		},
+		"slices and indexes": {
+			source:   "{{ [1, 2, 3, 4][1:3] }} {{ 'abcdef'[::2] }} {{ 'abcdef'[-2:] }} {{ [1, 2, 3, 4][-1:0:-2] }} {{ ds.meta_data.tags[-1] }} [{{ ds.meta_data.tags[5] }}]",
+			expected: "[2, 3] ace ef [4, 2] prod []",
+		},
+		"truthiness, escapes and quoting": {
+			source:   `{{ 'yes' if [] or {} or 0.0 or none else 'no' }} {{ ["it's", 'say "hi"', 'a\tb', 'both \' and "'] }} {{ 'abc'[1] }}{{ 'abc'[-1] }} {{ "a\x41\u00e9" }}`,
+			expected: `no ["it's", 'say "hi"', 'a\tb', 'both \' and "'] bc aAé`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(tc.source, context, Options{TrimBlocks: true})
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRender_undefined(t *testing.T) {
+	var names []string
+
+	actual, err := Render("{{ v1.zone }} {{ missing ~ '!' }} {{ v1.zone is defined }}", map[string]any{"v1": map[string]any{}}, Options{
+		Undefined: func(name string) string {
+			names = append(names, name)
+			return "MISSING/" + name
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if expected := "MISSING/zone MISSING/missing! False"; actual != expected {
+		t.Errorf("expected %q, got %q", expected, actual)
+	}
+
+	if len(names) != 2 || names[0] != "zone" || names[1] != "missing" {
+		t.Errorf("expected the undefined names zone and missing, got %q", names)
+	}
+}
+
+func TestRender_invalid(t *testing.T) {
+	context := map[string]any{
+		"v1": map[string]any{"cpus": 4},
+	}
+
+	testCases := map[string]struct {
+		source   string
+		expected string
+	}{
+		"unclosed tag": {
+			source:   "a\n{{ v1.cpus",
+			expected: "line 2: unexpected end of template, the tag is not closed",
+		},
+		"unclosed block": {
+			source:   "{% if true %}\na\n{% for x in [1] %}\n{% endfor %}",
+			expected: "line 4: unexpected end of template, expected elif or else or endif",
+		},
+		"unexpected end tag": {
+			source:   "{% for x in [1] %}{% endif %}",
+			expected: `line 1: unexpected tag "endif", expected else or endfor`,
+		},
+		"unknown tag": {
+			source:   "{% foo %}",
+			expected: `line 1: unknown tag "foo"`,
+		},
+		"unsupported tag": {
+			source:   "{% macro foo() %}{% endmacro %}",
+			expected: "line 1: the macro tag is not supported",
+		},
+		"unknown filter": {
+			source:   "\n{{ v1.cpus | b64encode }}",
+			expected: `line 2: no filter named "b64encode"`,
+		},
+		"syntax error": {
+			source:   "{{ v1.cpus + }}",
+			expected: "line 1: unexpected end of expression",
+		},
+		"attribute of an undefined variable": {
+			source:   "{{ v1.zone.name }}",
+			expected: "line 1: 'zone' is undefined",
+		},
+		"name with a dash": {
+			source:   "{{ v1.local-hostname }}",
+			expected: "line 1: 'local' is undefined in local-hostname, which Jinja reads as a subtraction",
+		},
+		"unsupported operands": {
+			source:   "{{ 'a' + v1.cpus }}",
+			expected: "line 1: unsupported operand types for +: 'str' and 'int'",
+		},
+		"division by zero": {
+			source:   "{% if true %}\n{{ v1.cpus / 0 }}\n{% endif %}",
+			expected: "line 2: division by zero",
+		},
+		"unsupported filter argument": {
+			source:   "{{ [1] | join(',', attribute='x') }}",
+			expected: "line 1: the join filter got an unexpected keyword argument 'attribute'",
+		},
+		"too many filter arguments": {
+			source:   "{{ 'a' | upper(1) }}",
+			expected: "line 1: the upper filter expected at most 0 arguments, got 1",
+		},
+		"argument passed twice": {
+			source:   "{{ 1 | default(1, default_value=2) }}",
+			expected: "line 1: the default filter got multiple values for argument 'default_value'",
+		},
+		"repeated keyword argument": {
+			source:   "{{ 1 | default(default_value=1, default_value=2) }}",
+			expected: "line 1: keyword argument repeated: default_value",
+		},
+		"too many test arguments": {
+			source:   "{{ 1 is defined(1) }}",
+			expected: "line 1: the defined test expected at most 0 arguments, got 1",
+		},
+		"too many method arguments": {
+			source:   "{{ 'a'.startswith('a', 1) }}",
+			expected: "line 1: startswith expected at most 1 argument, got 2",
+		},
+		"keyword argument of a method": {
+			source:   "{{ ' a '.strip(chars=' ') }}",
+			expected: "line 1: strip takes no keyword arguments",
+		},
+		"abs of a string": {
+			source:   "{{ 'a' | abs }}",
+			expected: "line 1: bad operand type for abs(): 'str'",
+		},
+		"list of an integer": {
+			source:   "{{ 1 | list }}",
+			expected: "line 1: 'int' object is not iterable",
+		},
+		"length of an integer": {
+			source:   "{{ 1 | length }}",
+			expected: "line 1: object of type 'int' has no len()",
+		},
+		"items of a list": {
+			source:   "{{ [] | items | list }}",
+			expected: "line 1: can only get item pairs from a mapping, got list",
+		},
+		"tojson of an undefined variable": {
+			source:   "{{ missing | tojson }}",
+			expected: "line 1: 'missing' is undefined",
+		},
+		"odd undefined variable": {
+			source:   "{{ missing is odd }}",
+			expected: "line 1: 'missing' is undefined",
+		},
+		"divisible by zero": {
+			source:   "{{ v1.cpus is divisibleby 0 }}",
+			expected: "line 1: integer division or modulo by zero",
+		},
+		"ordering different types": {
+			source:   "{{ 1 < 'a' }}",
+			expected: "line 1: '<' not supported between instances of 'int' and 'str'",
+		},
+		"integer in a string": {
+			source:   "{{ 1 in 'abc' }}",
+			expected: "line 1: 'in <string>' requires string as left operand, not int",
+		},
+		"negative string": {
+			source:   "{{ -'a' }}",
+			expected: "line 1: bad operand type for unary -: 'str'",
+		},
+		"call of an integer": {
+			source:   "{{ v1.cpus() }}",
+			expected: "line 1: 'int' object is not callable",
+		},
+		"split with an empty separator": {
+			source:   "{{ 'a'.split('') }}",
+			expected: "line 1: empty separator",
+		},
+		"truncated escape": {
+			source:   `{{ 'a\x4' }}`,
+			expected: `line 1: truncated \x escape`,
+		},
+		"range with a zero step": {
+			source:   "{{ range(1, 2, 0) }}",
+			expected: "line 1: range step cannot be zero",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Render(tc.source, context, Options{TrimBlocks: true})
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,364 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package jinja
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type segmentKind int
+
+const (
+	segmentText segmentKind = iota
+
+	// segmentOutput is an expression written with {{ }}.
+	segmentOutput
+
+	// segmentStatement is a tag written with {% %}.
+	segmentStatement
+)
+
+// segment is a piece of text, expression or tag of a template. The text of
+// expressions and tags is without their delimiters.
+type segment struct {
+	kind segmentKind
+	text string
+	line int
+}
+
+// endRawPattern matches the tag which ends a raw block.
+var endRawPattern = regexp.MustCompile(`\{%(-?)\s*endraw\s*(-?)%\}`)
+
+// splitSegments splits the source of a template into text, expressions and
+// tags, applying whitespace control and dropping comments. The text of raw
+// blocks is kept as is.
+func splitSegments(source string, trimBlocks bool) ([]segment, error) {
+	var segments []segment
+
+	// stripNext is set by a tag ending with a minus sign, which removes the
+	// whitespace at the start of the following text.
+	stripNext := false
+
+	addText := func(text string, line int) {
+		if stripNext {
+			text = strings.TrimLeftFunc(text, unicode.IsSpace)
+			stripNext = false
+		}
+
+		if text != "" {
+			segments = append(segments, segment{kind: segmentText, text: text, line: line})
+		}
+	}
+
+	// endTag skips the newline after a block tag or comment with TrimBlocks.
+	endTag := func(pos int) int {
+		if trimBlocks && !stripNext && pos < len(source) && source[pos] == '\n' {
+			return pos + 1
+		}
+
+		return pos
+	}
+
+	pos := 0
+	for {
+		start := indexTagStart(source, pos)
+		if start < 0 {
+			addText(source[pos:], lineAt(source, pos))
+			return segments, nil
+		}
+
+		text := source[pos:start]
+		line := lineAt(source, start)
+		opener := source[start : start+2]
+
+		inner := start + 2
+		if inner < len(source) && source[inner] == '-' {
+			text = strings.TrimRightFunc(text, unicode.IsSpace)
+			inner++
+		}
+
+		addText(text, lineAt(source, pos))
+
+		var end int
+		switch opener {
+		case "{#":
+			end = strings.Index(source[inner:], "#}")
+			if end >= 0 {
+				end += inner
+			}
+		case "{{":
+			end = indexTagEnd(source, inner, "}}")
+		default:
+			end = indexTagEnd(source, inner, "%}")
+		}
+
+		if end < 0 {
+			return nil, &Error{Line: line, Message: "unexpected end of template, the tag is not closed"}
+		}
+
+		content := source[inner:end]
+		if strings.HasSuffix(content, "-") {
+			content = content[:len(content)-1]
+			stripNext = true
+		}
+
+		pos = end + 2
+
+		switch opener {
+		case "{#":
+			pos = endTag(pos)
+		case "{{":
+			segments = append(segments, segment{kind: segmentOutput, text: content, line: line})
+		default:
+			if strings.TrimSpace(content) != "raw" {
+				segments = append(segments, segment{kind: segmentStatement, text: content, line: line})
+				pos = endTag(pos)
+				continue
+			}
+
+			pos = endTag(pos)
+
+			match := endRawPattern.FindStringSubmatchIndex(source[pos:])
+			if match == nil {
+				return nil, &Error{Line: line, Message: "unexpected end of template, expected endraw"}
+			}
+
+			raw := source[pos : pos+match[0]]
+			if match[3] > match[2] {
+				raw = strings.TrimRightFunc(raw, unicode.IsSpace)
+			}
+
+			addText(raw, lineAt(source, pos))
+
+			stripNext = match[5] > match[4]
+			pos = endTag(pos + match[1])
+		}
+	}
+}
+
+// indexTagStart returns the index of the next {{, {% or {# in source from pos,
+// or -1.
+func indexTagStart(source string, pos int) int {
+	for i := pos; i < len(source)-1; i++ {
+		if source[i] == '{' && (source[i+1] == '{' || source[i+1] == '%' || source[i+1] == '#') {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// indexTagEnd returns the index of closer in source from pos, outside of string
+// literals, or -1.
+func indexTagEnd(source string, pos int, closer string) int {
+	var quote byte
+
+	for i := pos; i < len(source); i++ {
+		c := source[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case strings.HasPrefix(source[i:], closer):
+			return i
+		}
+	}
+
+	return -1
+}
+
+func lineAt(source string, pos int) int {
+	return strings.Count(source[:pos], "\n") + 1
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenString
+	tokenInteger
+	tokenFloat
+	tokenOperator
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	line  int
+}
+
+// operators are the operators of expressions, with the longer operators first.
+var operators = []string{
+	"//", "**", "==", "!=", "<=", ">=",
+	"+", "-", "*", "/", "%", "~", "<", ">", "=", "(", ")", "[", "]", "{", "}", ",", ".", ":", "|",
+}
+
+// tokenize splits the text of an expression or tag starting at line into
+// tokens, ending with a tokenEOF token.
+func tokenize(text string, line int) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t':
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(text) && (text[i] == '_' || isLetter(text[i]) || isDigit(text[i])) {
+				i++
+			}
+
+			tokens = append(tokens, token{kind: tokenName, value: text[start:i], line: line})
+		case isDigit(c):
+			start := i
+			kind := tokenInteger
+
+			for i < len(text) && isDigit(text[i]) {
+				i++
+			}
+
+			if i+1 < len(text) && text[i] == '.' && isDigit(text[i+1]) {
+				kind = tokenFloat
+				for i++; i < len(text) && isDigit(text[i]); i++ {
+				}
+			}
+
+			if i < len(text) && (text[i] == 'e' || text[i] == 'E') {
+				j := i + 1
+				if j < len(text) && (text[j] == '+' || text[j] == '-') {
+					j++
+				}
+
+				if j < len(text) && isDigit(text[j]) {
+					kind = tokenFloat
+					for i = j; i < len(text) && isDigit(text[i]); i++ {
+					}
+				}
+			}
+
+			tokens = append(tokens, token{kind: kind, value: text[start:i], line: line})
+		case c == '\'' || c == '"':
+			value, length, err := unquote(text[i:])
+			if err != nil {
+				return nil, &Error{Line: line, Message: err.Error()}
+			}
+
+			tokens = append(tokens, token{kind: tokenString, value: value, line: line})
+			line += strings.Count(text[i:i+length], "\n")
+			i += length
+		default:
+			operator := ""
+			for _, candidate := range operators {
+				if strings.HasPrefix(text[i:], candidate) {
+					operator = candidate
+					break
+				}
+			}
+
+			if operator == "" {
+				return nil, &Error{Line: line, Message: "unexpected character " + quoteString(string(c))}
+			}
+
+			tokens = append(tokens, token{kind: tokenOperator, value: operator, line: line})
+			i += len(operator)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, line: line}), nil
+}
+
+// unquote decodes the string literal at the start of text, returning its value
+// and its length in text. Escape sequences are decoded like in Python, as Jinja
+// does.
+func unquote(text string) (string, int, error) {
+	quote := text[0]
+
+	var value strings.Builder
+	for i := 1; i < len(text); i++ {
+		c := text[i]
+
+		switch {
+		case c == quote:
+			return value.String(), i + 1, nil
+		case c == '\\' && i+1 < len(text):
+			i++
+
+			switch escape := text[i]; escape {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			case 'a':
+				value.WriteByte('\a')
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'v':
+				value.WriteByte('\v')
+			case '\\', '\'', '"':
+				value.WriteByte(escape)
+			case '\n':
+				// A backslash at the end of a line continues the string.
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				end := i + 1
+				for end < len(text) && end < i+3 && text[end] >= '0' && text[end] <= '7' {
+					end++
+				}
+
+				code, _ := strconv.ParseUint(text[i:end], 8, 32)
+				value.WriteRune(rune(code))
+				i = end - 1
+			case 'x', 'u', 'U':
+				digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[escape]
+				if i+digits >= len(text) {
+					return "", 0, fmt.Errorf("truncated \\%c escape", escape)
+				}
+
+				code, err := strconv.ParseUint(text[i+1:i+1+digits], 16, 32)
+				if err != nil || code > unicode.MaxRune {
+					return "", 0, fmt.Errorf("truncated \\%c escape", escape)
+				}
+
+				value.WriteRune(rune(code))
+				i += digits
+			case 'N':
+				return "", 0, fmt.Errorf("\\N escapes are not supported")
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(text[i])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return "", 0, errUnterminatedString
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,1007 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package jinja
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var errUnterminatedString = errors.New("unterminated string literal")
+
+// unsupportedTags are Jinja tags which are not supported, as opposed to tags
+// which do not exist.
+var unsupportedTags = map[string]bool{
+	"autoescape": true,
+	"block":      true,
+	"call":       true,
+	"do":         true,
+	"extends":    true,
+	"filter":     true,
+	"from":       true,
+	"import":     true,
+	"include":    true,
+	"macro":      true,
+	"with":       true,
+}
+
+// Nodes of the body of a template.
+type (
+	textNode struct {
+		text string
+	}
+
+	outputNode struct {
+		expr expr
+		line int
+	}
+
+	ifNode struct {
+		branches []ifBranch
+		elseBody []node
+	}
+
+	ifBranch struct {
+		test expr
+		body []node
+		line int
+	}
+
+	forNode struct {
+		targets  []string
+		iterable expr
+		filter   expr
+		body     []node
+		elseBody []node
+		line     int
+	}
+
+	setNode struct {
+		targets []string
+		value   expr
+		line    int
+	}
+)
+
+type node interface{}
+
+// Nodes of expressions.
+type (
+	literalExpr struct {
+		value any
+	}
+
+	nameExpr struct {
+		name string
+	}
+
+	getattrExpr struct {
+		target expr
+		name   string
+	}
+
+	getitemExpr struct {
+		target expr
+		index  expr
+	}
+
+	sliceExpr struct {
+		target            expr
+		start, stop, step expr
+	}
+
+	listExpr struct {
+		items []expr
+	}
+
+	dictExpr struct {
+		keys   []expr
+		values []expr
+	}
+
+	unaryExpr struct {
+		operator string
+		operand  expr
+	}
+
+	binaryExpr struct {
+		operator    string
+		left, right expr
+	}
+
+	compareExpr struct {
+		first     expr
+		operators []string
+		operands  []expr
+	}
+
+	condExpr struct {
+		test, then, otherwise expr
+	}
+
+	filterExpr struct {
+		target expr
+		name   string
+		args   arguments
+	}
+
+	testExpr struct {
+		target  expr
+		name    string
+		args    arguments
+		negated bool
+	}
+
+	callExpr struct {
+		callee expr
+		args   arguments
+	}
+)
+
+type expr interface{}
+
+// arguments are the arguments of a call, filter or test.
+type arguments struct {
+	positional []expr
+	keywords   map[string]expr
+}
+
+type parser struct {
+	segments []segment
+	index    int
+
+	// tokens are the tokens of the expression or tag being parsed.
+	tokens []token
+	pos    int
+}
+
+func (p *parser) parseTemplate() ([]node, error) {
+	body, _, err := p.parseBody()
+	return body, err
+}
+
+// parseBody parses nodes until the end of the template or a tag in endTags,
+// whose name is returned with the tokens after it left to parse.
+func (p *parser) parseBody(endTags ...string) ([]node, string, error) {
+	var body []node
+
+	for ; p.index < len(p.segments); p.index++ {
+		segment := p.segments[p.index]
+
+		switch segment.kind {
+		case segmentText:
+			body = append(body, textNode{text: segment.text})
+			continue
+		case segmentOutput:
+			if err := p.startTag(segment); err != nil {
+				return nil, "", err
+			}
+
+			value, err := p.parseExpression()
+			if err != nil {
+				return nil, "", err
+			}
+
+			if err := p.expectEnd(); err != nil {
+				return nil, "", err
+			}
+
+			body = append(body, outputNode{expr: value, line: segment.line})
+			continue
+		}
+
+		if err := p.startTag(segment); err != nil {
+			return nil, "", err
+		}
+
+		name := p.next()
+		if name.kind != tokenName {
+			return nil, "", p.errorf("expected a tag name")
+		}
+
+		for _, endTag := range endTags {
+			if name.value == endTag {
+				return body, endTag, nil
+			}
+		}
+
+		var statement node
+		var err error
+
+		switch name.value {
+		case "if":
+			statement, err = p.parseIf(segment.line)
+		case "for":
+			statement, err = p.parseFor(segment.line)
+		case "set":
+			statement, err = p.parseSet(segment.line)
+		default:
+			switch {
+			case unsupportedTags[name.value]:
+				err = p.errorf("the %s tag is not supported", name.value)
+			case len(endTags) > 0:
+				err = p.errorf("unexpected tag %q, expected %s", name.value, strings.Join(endTags, " or "))
+			default:
+				err = p.errorf("unknown tag %q", name.value)
+			}
+		}
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		body = append(body, statement)
+	}
+
+	if len(endTags) > 0 {
+		line := 1
+		if len(p.segments) > 0 {
+			line = p.segments[len(p.segments)-1].line
+		}
+
+		return nil, "", &Error{Line: line, Message: fmt.Sprintf("unexpected end of template, expected %s", strings.Join(endTags, " or "))}
+	}
+
+	return body, "", nil
+}
+
+func (p *parser) parseIf(line int) (node, error) {
+	var statement ifNode
+
+	for {
+		test, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expectEnd(); err != nil {
+			return nil, err
+		}
+
+		p.index++
+
+		body, end, err := p.parseBody("elif", "else", "endif")
+		if err != nil {
+			return nil, err
+		}
+
+		statement.branches = append(statement.branches, ifBranch{test: test, body: body, line: line})
+		line = p.segments[p.index].line
+
+		switch end {
+		case "elif":
+			continue
+		case "else":
+			if err := p.expectEnd(); err != nil {
+				return nil, err
+			}
+
+			p.index++
+
+			statement.elseBody, _, err = p.parseBody("endif")
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return statement, p.expectEnd()
+	}
+}
+
+func (p *parser) parseFor(line int) (node, error) {
+	statement := forNode{line: line}
+
+	targets, err := p.parseTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	statement.targets = targets
+
+	if token := p.next(); token.kind != tokenName || token.value != "in" {
+		return nil, p.errorf("expected \"in\" after the loop variables")
+	}
+
+	// The iterable is parsed without conditional expressions, so that a
+	// trailing if filters the items.
+	statement.iterable, err = p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptName("if") {
+		statement.filter, err = p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expectEnd(); err != nil {
+		return nil, err
+	}
+
+	p.index++
+
+	body, end, err := p.parseBody("else", "endfor")
+	if err != nil {
+		return nil, err
+	}
+
+	statement.body = body
+
+	if end == "else" {
+		if err := p.expectEnd(); err != nil {
+			return nil, err
+		}
+
+		p.index++
+
+		statement.elseBody, _, err = p.parseBody("endfor")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return statement, p.expectEnd()
+}
+
+func (p *parser) parseSet(line int) (node, error) {
+	targets, err := p.parseTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.acceptOperator("=") {
+		return nil, p.errorf("expected \"=\" after the variables of set, block assignments are not supported")
+	}
+
+	value, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	return setNode{targets: targets, value: value, line: line}, p.expectEnd()
+}
+
+// parseTargets parses the comma separated names assigned by for and set.
+func (p *parser) parseTargets() ([]string, error) {
+	var targets []string
+
+	for {
+		name := p.next()
+		if name.kind != tokenName {
+			return nil, p.errorf("expected a variable name")
+		}
+
+		targets = append(targets, name.value)
+
+		if !p.acceptOperator(",") {
+			return targets, nil
+		}
+	}
+}
+
+func (p *parser) parseExpression() (expr, error) {
+	value, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptName("if") {
+		test, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		var otherwise expr
+		if p.acceptName("else") {
+			otherwise, err = p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		value = condExpr{test: test, then: value, otherwise: otherwise}
+	}
+
+	return value, nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptName("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpr{operator: "or", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptName("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpr{operator: "and", left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptName("not") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return unaryExpr{operator: "not", operand: operand}, nil
+	}
+
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (expr, error) {
+	first, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := compareExpr{first: first}
+
+	for {
+		token := p.peek()
+
+		var operator string
+		switch {
+		case token.kind == tokenOperator && (token.value == "==" || token.value == "!=" || token.value == "<" ||
+			token.value == "<=" || token.value == ">" || token.value == ">="):
+			operator = token.value
+			p.pos++
+		case token.kind == tokenName && token.value == "in":
+			operator = "in"
+			p.pos++
+		case token.kind == tokenName && token.value == "not" && p.peekAt(1).kind == tokenName && p.peekAt(1).value == "in":
+			operator = "not in"
+			p.pos += 2
+		}
+
+		if operator == "" {
+			break
+		}
+
+		operand, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+
+		comparison.operators = append(comparison.operators, operator)
+		comparison.operands = append(comparison.operands, operand)
+	}
+
+	if len(comparison.operators) == 0 {
+		return first, nil
+	}
+
+	return comparison, nil
+}
+
+// binaryLevels are the binary operators from the lowest to the highest
+// precedence, after comparisons.
+var binaryLevels = [][]string{
+	{"+", "-"},
+	{"~"},
+	{"*", "/", "//", "%"},
+	{"**"},
+}
+
+func (p *parser) parseBinary(level int) (expr, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary(true)
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		token := p.peek()
+		if token.kind != tokenOperator || !contains(binaryLevels[level], token.value) {
+			return left, nil
+		}
+
+		p.pos++
+
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpr{operator: token.value, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary(withFilter bool) (expr, error) {
+	var value expr
+	var err error
+
+	if token := p.peek(); token.kind == tokenOperator && (token.value == "-" || token.value == "+") {
+		p.pos++
+
+		operand, err := p.parseUnary(false)
+		if err != nil {
+			return nil, err
+		}
+
+		value = unaryExpr{operator: token.value, operand: operand}
+	} else {
+		value, err = p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+
+		value, err = p.parsePostfix(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if withFilter {
+		return p.parseFilters(value)
+	}
+
+	return value, nil
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	token := p.next()
+
+	switch token.kind {
+	case tokenName:
+		switch token.value {
+		case "true", "True":
+			return literalExpr{value: true}, nil
+		case "false", "False":
+			return literalExpr{value: false}, nil
+		case "none", "None":
+			return literalExpr{value: nil}, nil
+		}
+
+		return nameExpr{name: token.value}, nil
+	case tokenString:
+		value := token.value
+		for p.peek().kind == tokenString {
+			value += p.next().value
+		}
+
+		return literalExpr{value: value}, nil
+	case tokenInteger:
+		value, err := strconv.ParseInt(token.value, 10, 64)
+		if err != nil {
+			return nil, p.errorf("invalid integer %s", token.value)
+		}
+
+		return literalExpr{value: value}, nil
+	case tokenFloat:
+		value, err := strconv.ParseFloat(token.value, 64)
+		if err != nil {
+			return nil, p.errorf("invalid float %s", token.value)
+		}
+
+		return literalExpr{value: value}, nil
+	case tokenOperator:
+		switch token.value {
+		case "(":
+			items, err := p.parseItems(")")
+			if err != nil {
+				return nil, err
+			}
+
+			// A single expression in parentheses is only grouped, while a
+			// tuple is rendered as a list.
+			if len(items.items) == 1 && !items.trailingComma {
+				return items.items[0], nil
+			}
+
+			return listExpr{items: items.items}, nil
+		case "[":
+			items, err := p.parseItems("]")
+			if err != nil {
+				return nil, err
+			}
+
+			return listExpr{items: items.items}, nil
+		case "{":
+			return p.parseDict()
+		}
+	case tokenEOF:
+		return nil, p.errorf("unexpected end of expression")
+	}
+
+	return nil, p.errorf("unexpected %s", quoteString(token.value))
+}
+
+type parsedItems struct {
+	items         []expr
+	trailingComma bool
+}
+
+// parseItems parses comma separated expressions up to closer.
+func (p *parser) parseItems(closer string) (parsedItems, error) {
+	var items parsedItems
+
+	for !p.acceptOperator(closer) {
+		if len(items.items) > 0 {
+			if !p.acceptOperator(",") {
+				return items, p.errorf("expected \",\" or %q", closer)
+			}
+
+			items.trailingComma = true
+
+			if p.acceptOperator(closer) {
+				break
+			}
+		}
+
+		item, err := p.parseExpression()
+		if err != nil {
+			return items, err
+		}
+
+		items.items = append(items.items, item)
+		items.trailingComma = false
+	}
+
+	return items, nil
+}
+
+func (p *parser) parseDict() (expr, error) {
+	var dict dictExpr
+
+	for !p.acceptOperator("}") {
+		if len(dict.keys) > 0 {
+			if !p.acceptOperator(",") {
+				return nil, p.errorf("expected \",\" or \"}\"")
+			}
+
+			if p.acceptOperator("}") {
+				break
+			}
+		}
+
+		key, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.acceptOperator(":") {
+			return nil, p.errorf("expected \":\" after a key")
+		}
+
+		value, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		dict.keys = append(dict.keys, key)
+		dict.values = append(dict.values, value)
+	}
+
+	return dict, nil
+}
+
+func (p *parser) parsePostfix(value expr) (expr, error) {
+	for {
+		switch {
+		case p.acceptOperator("."):
+			token := p.next()
+
+			switch token.kind {
+			case tokenName:
+				value = getattrExpr{target: value, name: token.value}
+			case tokenInteger:
+				index, err := strconv.ParseInt(token.value, 10, 64)
+				if err != nil {
+					return nil, p.errorf("invalid integer %s", token.value)
+				}
+
+				value = getitemExpr{target: value, index: literalExpr{value: index}}
+			default:
+				return nil, p.errorf("expected an attribute name after \".\"")
+			}
+		case p.acceptOperator("["):
+			subscript, err := p.parseSubscript(value)
+			if err != nil {
+				return nil, err
+			}
+
+			value = subscript
+		case p.acceptOperator("("):
+			args, err := p.parseArguments()
+			if err != nil {
+				return nil, err
+			}
+
+			value = callExpr{callee: value, args: args}
+		default:
+			return value, nil
+		}
+	}
+}
+
+// parseSubscript parses an index or slice after "[".
+func (p *parser) parseSubscript(target expr) (expr, error) {
+	var parts [3]expr
+
+	count := 0
+	for {
+		if token := p.peek(); token.kind != tokenOperator || (token.value != ":" && token.value != "]") {
+			part, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			parts[count] = part
+		}
+
+		if p.acceptOperator("]") {
+			break
+		}
+
+		if count == 2 || !p.acceptOperator(":") {
+			return nil, p.errorf("expected \"]\"")
+		}
+
+		count++
+	}
+
+	if count == 0 {
+		if parts[0] == nil {
+			return nil, p.errorf("expected an index")
+		}
+
+		return getitemExpr{target: target, index: parts[0]}, nil
+	}
+
+	return sliceExpr{target: target, start: parts[0], stop: parts[1], step: parts[2]}, nil
+}
+
+// parseArguments parses the arguments of a call after "(".
+func (p *parser) parseArguments() (arguments, error) {
+	var args arguments
+
+	for !p.acceptOperator(")") {
+		if len(args.positional)+len(args.keywords) > 0 {
+			if !p.acceptOperator(",") {
+				return args, p.errorf("expected \",\" or \")\"")
+			}
+
+			if p.acceptOperator(")") {
+				break
+			}
+		}
+
+		if token := p.peek(); token.kind == tokenName && p.peekAt(1).kind == tokenOperator && p.peekAt(1).value == "=" {
+			p.pos += 2
+
+			value, err := p.parseExpression()
+			if err != nil {
+				return args, err
+			}
+
+			if _, ok := args.keywords[token.value]; ok {
+				return args, p.errorf("keyword argument repeated: %s", token.value)
+			}
+
+			if args.keywords == nil {
+				args.keywords = make(map[string]expr)
+			}
+
+			args.keywords[token.value] = value
+			continue
+		}
+
+		if len(args.keywords) > 0 {
+			return args, p.errorf("positional argument follows keyword argument")
+		}
+
+		value, err := p.parseExpression()
+		if err != nil {
+			return args, err
+		}
+
+		args.positional = append(args.positional, value)
+	}
+
+	return args, nil
+}
+
+// parseFilters parses the filters and tests applied to value.
+func (p *parser) parseFilters(value expr) (expr, error) {
+	for {
+		switch {
+		case p.acceptOperator("|"):
+			name, err := p.parseFilterName()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := filters[name]; !ok {
+				return nil, p.errorf("no filter named %q", name)
+			}
+
+			var args arguments
+			if p.acceptOperator("(") {
+				args, err = p.parseArguments()
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			if err := checkArguments("the "+name+" filter", filterParameters[name], true, args.positional, args.keywords); err != nil {
+				return nil, p.errorf("%s", err)
+			}
+
+			value = filterExpr{target: value, name: name, args: args}
+		case p.acceptName("is"):
+			negated := p.acceptName("not")
+
+			name, err := p.parseFilterName()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := tests[name]; !ok {
+				return nil, p.errorf("no test named %q", name)
+			}
+
+			var args arguments
+
+			token := p.peek()
+			switch {
+			case token.kind == tokenOperator && token.value == "(":
+				p.pos++
+
+				args, err = p.parseArguments()
+				if err != nil {
+					return nil, err
+				}
+			case token.kind == tokenString || token.kind == tokenInteger || token.kind == tokenFloat ||
+				token.kind == tokenName && !contains([]string{"and", "or", "else", "if", "is", "in", "not"}, token.value):
+				arg, err := p.parseUnary(false)
+				if err != nil {
+					return nil, err
+				}
+
+				args.positional = []expr{arg}
+			}
+
+			if err := checkArguments("the "+name+" test", testParameters[name], true, args.positional, args.keywords); err != nil {
+				return nil, p.errorf("%s", err)
+			}
+
+			value = testExpr{target: value, name: name, args: args, negated: negated}
+		default:
+			return value, nil
+		}
+	}
+}
+
+// parseFilterName parses the name of a filter or test, which may be dotted.
+func (p *parser) parseFilterName() (string, error) {
+	token := p.next()
+	if token.kind != tokenName {
+		return "", p.errorf("expected a filter or test name")
+	}
+
+	name := token.value
+	for p.peek().kind == tokenOperator && p.peek().value == "." && p.peekAt(1).kind == tokenName {
+		p.pos++
+		name += "." + p.next().value
+	}
+
+	return name, nil
+}
+
+// startTag starts parsing the expression or tag of segment.
+func (p *parser) startTag(segment segment) error {
+	tokens, err := tokenize(segment.text, segment.line)
+	if err != nil {
+		return err
+	}
+
+	p.tokens = tokens
+	p.pos = 0
+
+	return nil
+}
+
+func (p *parser) expectEnd() error {
+	if token := p.peek(); token.kind != tokenEOF {
+		return p.errorf("unexpected %s", quoteString(token.value))
+	}
+
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.peekAt(0)
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() token {
+	token := p.peek()
+	if p.pos < len(p.tokens)-1 {
+		p.pos++
+	}
+
+	return token
+}
+
+func (p *parser) acceptName(name string) bool {
+	if token := p.peek(); token.kind == tokenName && token.value == name {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) acceptOperator(operator string) bool {
+	if token := p.peek(); token.kind == tokenOperator && token.value == operator {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	line := p.peek().line
+	if p.pos > 0 && p.peek().kind == tokenEOF {
+		line = p.tokens[p.pos-1].line
+	}
+
+	return &Error{Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quoteString quotes s like Python does.
+func quoteString(s string) string {
+	return pyRepr(s)
+}
@@ -0,0 +1,226 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+// Package networkconfig renders the network configuration cloud-init reads
+// from the network-config file of a NoCloud or ConfigDrive seed, in the
+// version 1 format or the netplan style version 2 format.
+package networkconfig
+
+import (
+	"bytes"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	// Version1 is the version 1 format, a list of typed entries.
+	Version1 = 1
+
+	// Version2 is the version 2 format, which follows netplan.
+	Version2 = 2
+
+	// DefaultRoute is the destination of a route which matches every address
+	// of the family of its gateway.
+	DefaultRoute = "default"
+)
+
+// BondModes are the modes of the Linux bonding driver.
+var BondModes = []string{
+	"balance-rr",
+	"active-backup",
+	"balance-xor",
+	"broadcast",
+	"802.3ad",
+	"balance-tlb",
+	"balance-alb",
+}
+
+// Config is a network configuration.
+type Config struct {
+	Ethernets []Ethernet
+	Bonds     []Bond
+	Bridges   []Bridge
+	VLANs     []VLAN
+}
+
+// Interface holds the settings every kind of interface has.
+type Interface struct {
+	Name          string
+	Addresses     []string
+	DHCP4         bool
+	DHCP6         bool
+	MTU           int64
+	Nameservers   []string
+	SearchDomains []string
+	Routes        []Route
+}
+
+// Route is a static route of an interface.
+type Route struct {
+	// To is the destination network, such as "10.0.0.0/8", or DefaultRoute.
+	To     string
+	Via    string
+	Metric int64
+}
+
+// Ethernet is a physical interface, matched by MACAddress when it is set.
+type Ethernet struct {
+	Interface
+	MACAddress string
+}
+
+// Bond is a bond of ethernets.
+type Bond struct {
+	Interface
+	Interfaces         []string
+	Mode               string
+	MIIMonitorInterval int64
+	Primary            string
+}
+
+// Bridge is a bridge of ethernets, bonds or VLANs.
+type Bridge struct {
+	Interface
+	Interfaces []string
+	STP        *bool
+}
+
+// VLAN is a VLAN on top of the interface Link.
+type VLAN struct {
+	Interface
+	ID   int64
+	Link string
+}
+
+// macAddress matches a MAC address written like netplan and cloud-init expect.
+var macAddress = regexp.MustCompile(`^[0-9A-Fa-f]{2}(:[0-9A-Fa-f]{2}){5}$`)
+
+// ValidateInterfaceName checks that name can be the name of a Linux network
+// interface.
+func ValidateInterfaceName(name string) error {
+	switch {
+	case name == "" || name == "." || name == "..":
+		return fmt.Errorf("expected an interface name")
+	case len(name) > 15:
+		return fmt.Errorf("expected at most 15 characters, as Linux allows")
+	case strings.ContainsAny(name, "/: \t\n"):
+		return fmt.Errorf("expected no slashes, colons or whitespace")
+	}
+
+	return nil
+}
+
+// ValidateMACAddress checks that address is a MAC address such as
+// 52:54:00:12:34:56.
+func ValidateMACAddress(address string) error {
+	if !macAddress.MatchString(address) {
+		return fmt.Errorf("expected six pairs of hexadecimal digits separated by colons, such as 52:54:00:12:34:56")
+	}
+
+	return nil
+}
+
+// ParseAddress parses an address of an interface with its prefix length, such
+// as 192.0.2.10/24 or 2001:db8::10/64.
+func ParseAddress(address string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(address)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("expected an address with a prefix length, such as 192.0.2.10/24 or 2001:db8::10/64")
+	}
+
+	return prefix, nil
+}
+
+// ParseIP parses an IPv4 or IPv6 address without a prefix length, such as the
+// gateway of a route or a nameserver.
+func ParseIP(address string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil || addr.Zone() != "" {
+		return netip.Addr{}, fmt.Errorf("expected an IPv4 or IPv6 address, such as 192.0.2.1 or 2001:db8::1")
+	}
+
+	return addr, nil
+}
+
+// ParseDestination parses the destination of a route, a network such as
+// 10.0.0.0/8 without host bits set. DefaultRoute is the default network of the
+// family of via.
+func ParseDestination(destination string, via netip.Addr) (netip.Prefix, error) {
+	if destination == DefaultRoute {
+		if via.Is4() {
+			return netip.PrefixFrom(netip.IPv4Unspecified(), 0), nil
+		}
+
+		return netip.PrefixFrom(netip.IPv6Unspecified(), 0), nil
+	}
+
+	prefix, err := netip.ParsePrefix(destination)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("expected %q or a network, such as 10.0.0.0/8 or 2001:db8::/32", DefaultRoute)
+	}
+
+	if prefix != prefix.Masked() {
+		return netip.Prefix{}, fmt.Errorf("expected a network without host bits set, such as %s", prefix.Masked())
+	}
+
+	if prefix.Addr().Is4() != via.Is4() {
+		return netip.Prefix{}, fmt.Errorf("expected a network of the same address family as the gateway %s", via)
+	}
+
+	return prefix, nil
+}
+
+// Marshal writes config as a YAML document of the given version, without the
+// top-level network key, as the network-config file of a seed holds it.
+// Interfaces keep their order within each kind.
+func Marshal(config Config, version int) ([]byte, error) {
+	var document any
+	var err error
+
+	switch version {
+	case Version1:
+		document, err = version1(config)
+	case Version2:
+		document, err = version2(config)
+	default:
+		return nil, fmt.Errorf("unsupported version %d", version)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// routeDestination returns the destination and gateway of route.
+func routeDestination(route Route) (netip.Prefix, netip.Addr, error) {
+	via, err := ParseIP(route.Via)
+	if err != nil {
+		return netip.Prefix{}, netip.Addr{}, fmt.Errorf("invalid gateway %q: %w", route.Via, err)
+	}
+
+	destination, err := ParseDestination(route.To, via)
+	if err != nil {
+		return netip.Prefix{}, netip.Addr{}, fmt.Errorf("invalid destination %q: %w", route.To, err)
+	}
+
+	return destination, via, nil
+}
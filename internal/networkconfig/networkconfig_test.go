@@ -0,0 +1,414 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package networkconfig
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	stp := false
+
+	testCases := map[string]struct {
+		config   Config
+		version  int
+		expected string
+	}{
+		"empty version 2": {
+			version:  Version2,
+			expected: "version: 2\n",
+		},
+		"empty version 1": {
+			version:  Version1,
+			expected: "version: 1\nconfig: []\n",
+		},
+		"ethernet version 2": {
+			config: Config{
+				Ethernets: []Ethernet{
+					{
+						Interface: Interface{
+							Name:          "eth0",
+							Addresses:     []string{"192.0.2.10/24", "2001:db8::10/64"},
+							Nameservers:   []string{"192.0.2.53"},
+							SearchDomains: []string{"example.com"},
+							Routes: []Route{
+								{To: DefaultRoute, Via: "192.0.2.1", Metric: 100},
+								{To: "2001:db8:1::/48", Via: "2001:db8::1"},
+							},
+						},
+						MACAddress: "52:54:00:12:34:56",
+					},
+					{Interface: Interface{Name: "eth1", DHCP4: true, DHCP6: true, MTU: 9000}},
+				},
+			},
+			version: Version2,
+			expected: `version: 2
+ethernets:
+  eth0:
+    match:
+      macaddress: "52:54:00:12:34:56"
+    set-name: eth0
+    addresses:
+      - 192.0.2.10/24
+      - 2001:db8::10/64
+    nameservers:
+      addresses:
+        - 192.0.2.53
+      search:
+        - example.com
+    routes:
+      - to: 0.0.0.0/0
+        via: 192.0.2.1
+        metric: 100
+      - to: 2001:db8:1::/48
+        via: 2001:db8::1
+  eth1:
+    dhcp4: true
+    dhcp6: true
+    mtu: 9000
+`,
+		},
+		"ethernet version 1": {
+			config: Config{
+				Ethernets: []Ethernet{
+					{
+						Interface: Interface{
+							Name:          "eth0",
+							Addresses:     []string{"192.0.2.10/24", "2001:db8::10/64"},
+							Nameservers:   []string{"192.0.2.53"},
+							SearchDomains: []string{"example.com"},
+							Routes: []Route{
+								{To: DefaultRoute, Via: "192.0.2.1", Metric: 100},
+								{To: "2001:db8:1::/48", Via: "2001:db8::1"},
+							},
+						},
+						MACAddress: "52:54:00:12:34:56",
+					},
+					{Interface: Interface{Name: "eth1", DHCP4: true, DHCP6: true, MTU: 9000}},
+					{Interface: Interface{Name: "eth2", Routes: []Route{{To: "10.0.0.0/8", Via: "192.0.2.254"}}}},
+				},
+			},
+			version: Version1,
+			expected: `version: 1
+config:
+  - type: physical
+    name: eth0
+    mac_address: "52:54:00:12:34:56"
+    subnets:
+      - type: static
+        address: 192.0.2.10/24
+        dns_nameservers:
+          - 192.0.2.53
+        dns_search:
+          - example.com
+        routes:
+          - network: 0.0.0.0
+            netmask: 0.0.0.0
+            gateway: 192.0.2.1
+            metric: 100
+          - network: '2001:db8:1::'
+            prefix: 48
+            gateway: 2001:db8::1
+      - type: static6
+        address: 2001:db8::10/64
+  - type: physical
+    name: eth1
+    mtu: 9000
+    subnets:
+      - type: dhcp4
+      - type: dhcp6
+  - type: physical
+    name: eth2
+    subnets:
+      - type: manual
+        routes:
+          - network: 10.0.0.0
+            netmask: 255.0.0.0
+            gateway: 192.0.2.254
+`,
+		},
+		"bonds, bridges and vlans version 2": {
+			config: Config{
+				Ethernets: []Ethernet{
+					{Interface: Interface{Name: "eth0"}},
+					{Interface: Interface{Name: "eth1"}},
+				},
+				Bonds: []Bond{
+					{
+						Interface:          Interface{Name: "bond0"},
+						Interfaces:         []string{"eth0", "eth1"},
+						Mode:               "active-backup",
+						MIIMonitorInterval: 100,
+						Primary:            "eth0",
+					},
+				},
+				Bridges: []Bridge{
+					{
+						Interface:  Interface{Name: "br0", DHCP4: true},
+						Interfaces: []string{"vlan10"},
+						STP:        &stp,
+					},
+				},
+				VLANs: []VLAN{
+					{Interface: Interface{Name: "vlan10"}, ID: 10, Link: "bond0"},
+				},
+			},
+			version: Version2,
+			expected: `version: 2
+ethernets:
+  eth0: {}
+  eth1: {}
+bonds:
+  bond0:
+    interfaces:
+      - eth0
+      - eth1
+    parameters:
+      mode: active-backup
+      mii-monitor-interval: 100
+      primary: eth0
+bridges:
+  br0:
+    interfaces:
+      - vlan10
+    parameters:
+      stp: false
+    dhcp4: true
+vlans:
+  vlan10:
+    id: 10
+    link: bond0
+`,
+		},
+		"bonds, bridges and vlans version 1": {
+			config: Config{
+				Ethernets: []Ethernet{
+					{Interface: Interface{Name: "eth0"}},
+					{Interface: Interface{Name: "eth1"}},
+				},
+				Bonds: []Bond{
+					{
+						Interface:          Interface{Name: "bond0"},
+						Interfaces:         []string{"eth0", "eth1"},
+						Mode:               "active-backup",
+						MIIMonitorInterval: 100,
+						Primary:            "eth0",
+					},
+				},
+				Bridges: []Bridge{
+					{
+						Interface:  Interface{Name: "br0", DHCP4: true},
+						Interfaces: []string{"vlan10"},
+						STP:        &stp,
+					},
+				},
+				VLANs: []VLAN{
+					{Interface: Interface{Name: "vlan10"}, ID: 10, Link: "bond0"},
+				},
+			},
+			version: Version1,
+			expected: `version: 1
+config:
+  - type: physical
+    name: eth0
+  - type: physical
+    name: eth1
+  - type: bond
+    name: bond0
+    bond_interfaces:
+      - eth0
+      - eth1
+    params:
+      bond-miimon: 100
+      bond-mode: active-backup
+      bond-primary: eth0
+  - type: vlan
+    name: vlan10
+    vlan_link: bond0
+    vlan_id: 10
+  - type: bridge
+    name: br0
+    bridge_interfaces:
+      - vlan10
+    params:
+      bridge_stp: false
+    subnets:
+      - type: dhcp4
+`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Marshal(tc.config, tc.version)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if string(actual) != tc.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMarshal_invalid(t *testing.T) {
+	testCases := map[string]struct {
+		config   Config
+		version  int
+		expected string
+	}{
+		"unsupported version": {
+			version:  3,
+			expected: "unsupported version 3",
+		},
+		"invalid address": {
+			config: Config{
+				Ethernets: []Ethernet{{Interface: Interface{Name: "eth0", Addresses: []string{"192.0.2.10"}}}},
+			},
+			version:  Version1,
+			expected: `invalid address "192.0.2.10": expected an address with a prefix length, such as 192.0.2.10/24 or 2001:db8::10/64`,
+		},
+		"invalid gateway": {
+			config: Config{
+				Ethernets: []Ethernet{{Interface: Interface{Name: "eth0", Routes: []Route{{To: DefaultRoute, Via: "gateway"}}}}},
+			},
+			version:  Version2,
+			expected: `invalid gateway "gateway": expected an IPv4 or IPv6 address, such as 192.0.2.1 or 2001:db8::1`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Marshal(tc.config, tc.version)
+			if err == nil {
+				t.Fatalf("expected error %q", tc.expected)
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateInterfaceName(t *testing.T) {
+	testCases := map[string]struct {
+		name     string
+		expected string
+	}{
+		"valid":      {name: "enp0s3"},
+		"valid vlan": {name: "bond0.10"},
+		"empty": {
+			name:     "",
+			expected: "expected an interface name",
+		},
+		"too long": {
+			name:     "a-very-long-name",
+			expected: "expected at most 15 characters, as Linux allows",
+		},
+		"colon": {
+			name:     "eth0:1",
+			expected: "expected no slashes, colons or whitespace",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateInterfaceName(tc.name)
+
+			if tc.expected == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %s", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateMACAddress(t *testing.T) {
+	testCases := map[string]bool{
+		"52:54:00:12:34:56": true,
+		"52:54:00:AB:cd:EF": true,
+		"52-54-00-12-34-56": false,
+		"5254.0012.3456":    false,
+		"52:54:00:12:34":    false,
+		"52:54:00:12:34:5g": false,
+	}
+
+	for address, valid := range testCases {
+		t.Run(address, func(t *testing.T) {
+			if err := ValidateMACAddress(address); (err == nil) != valid {
+				t.Errorf("expected valid %t for %q, got %v", valid, address, err)
+			}
+		})
+	}
+}
+
+func TestParseDestination(t *testing.T) {
+	testCases := map[string]struct {
+		destination string
+		via         string
+		expected    string
+		err         string
+	}{
+		"default IPv4": {
+			destination: DefaultRoute,
+			via:         "192.0.2.1",
+			expected:    "0.0.0.0/0",
+		},
+		"default IPv6": {
+			destination: DefaultRoute,
+			via:         "2001:db8::1",
+			expected:    "::/0",
+		},
+		"network": {
+			destination: "10.0.0.0/8",
+			via:         "192.0.2.1",
+			expected:    "10.0.0.0/8",
+		},
+		"host bits set": {
+			destination: "10.0.0.1/8",
+			via:         "192.0.2.1",
+			err:         "expected a network without host bits set, such as 10.0.0.0/8",
+		},
+		"address family": {
+			destination: "10.0.0.0/8",
+			via:         "2001:db8::1",
+			err:         "expected a network of the same address family as the gateway 2001:db8::1",
+		},
+		"not a network": {
+			destination: "10.0.0.0",
+			via:         "192.0.2.1",
+			err:         `expected "default" or a network, such as 10.0.0.0/8 or 2001:db8::/32`,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseDestination(tc.destination, netip.MustParseAddr(tc.via))
+
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if actual.String() != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+}
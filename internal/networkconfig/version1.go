@@ -0,0 +1,178 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package networkconfig
+
+import (
+	"fmt"
+	"net"
+)
+
+type v1Document struct {
+	Version int       `yaml:"version"`
+	Config  []v1Entry `yaml:"config"`
+}
+
+// v1Entry is an interface of the version 1 format. The fields of the other
+// types of entries are left empty.
+type v1Entry struct {
+	Type             string         `yaml:"type"`
+	Name             string         `yaml:"name"`
+	MACAddress       string         `yaml:"mac_address,omitempty"`
+	MTU              int64          `yaml:"mtu,omitempty"`
+	BondInterfaces   []string       `yaml:"bond_interfaces,omitempty"`
+	BridgeInterfaces []string       `yaml:"bridge_interfaces,omitempty"`
+	VLANLink         string         `yaml:"vlan_link,omitempty"`
+	VLANID           int64          `yaml:"vlan_id,omitempty"`
+	Params           map[string]any `yaml:"params,omitempty"`
+	Subnets          []v1Subnet     `yaml:"subnets,omitempty"`
+}
+
+type v1Subnet struct {
+	Type           string    `yaml:"type"`
+	Address        string    `yaml:"address,omitempty"`
+	DNSNameservers []string  `yaml:"dns_nameservers,omitempty"`
+	DNSSearch      []string  `yaml:"dns_search,omitempty"`
+	Routes         []v1Route `yaml:"routes,omitempty"`
+}
+
+type v1Route struct {
+	Network string `yaml:"network"`
+	Netmask string `yaml:"netmask,omitempty"`
+	Prefix  *int   `yaml:"prefix,omitempty"`
+	Gateway string `yaml:"gateway"`
+	Metric  int64  `yaml:"metric,omitempty"`
+}
+
+// version1 returns config as a version 1 document. cloud-init reads the entries
+// in order, so every entry follows the interfaces it uses: bonds use ethernets,
+// VLANs use ethernets or bonds, and bridges use any of them.
+func version1(config Config) (*v1Document, error) {
+	document := &v1Document{Version: Version1, Config: []v1Entry{}}
+
+	add := func(entry v1Entry, iface Interface) error {
+		entry.Name = iface.Name
+		entry.MTU = iface.MTU
+
+		subnets, err := v1Subnets(iface)
+		if err != nil {
+			return err
+		}
+
+		entry.Subnets = subnets
+		document.Config = append(document.Config, entry)
+
+		return nil
+	}
+
+	for _, ethernet := range config.Ethernets {
+		if err := add(v1Entry{Type: "physical", MACAddress: ethernet.MACAddress}, ethernet.Interface); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, bond := range config.Bonds {
+		params := map[string]any{}
+
+		if bond.Mode != "" {
+			params["bond-mode"] = bond.Mode
+		}
+
+		if bond.MIIMonitorInterval != 0 {
+			params["bond-miimon"] = bond.MIIMonitorInterval
+		}
+
+		if bond.Primary != "" {
+			params["bond-primary"] = bond.Primary
+		}
+
+		if err := add(v1Entry{Type: "bond", BondInterfaces: bond.Interfaces, Params: params}, bond.Interface); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, vlan := range config.VLANs {
+		if err := add(v1Entry{Type: "vlan", VLANLink: vlan.Link, VLANID: vlan.ID}, vlan.Interface); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, bridge := range config.Bridges {
+		params := map[string]any{}
+
+		if bridge.STP != nil {
+			params["bridge_stp"] = *bridge.STP
+		}
+
+		if err := add(v1Entry{Type: "bridge", BridgeInterfaces: bridge.Interfaces, Params: params}, bridge.Interface); err != nil {
+			return nil, err
+		}
+	}
+
+	return document, nil
+}
+
+// v1Subnets returns the subnets of an interface, one for each of DHCP4, DHCP6
+// and the static addresses. The version 1 format has no nameservers or routes
+// of an interface, so they are set on its first subnet, which is added as a
+// manual subnet if there is none.
+func v1Subnets(iface Interface) ([]v1Subnet, error) {
+	var subnets []v1Subnet
+
+	if iface.DHCP4 {
+		subnets = append(subnets, v1Subnet{Type: "dhcp4"})
+	}
+
+	if iface.DHCP6 {
+		subnets = append(subnets, v1Subnet{Type: "dhcp6"})
+	}
+
+	for _, address := range iface.Addresses {
+		prefix, err := ParseAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", address, err)
+		}
+
+		subnetType := "static"
+		if prefix.Addr().Is6() {
+			subnetType = "static6"
+		}
+
+		subnets = append(subnets, v1Subnet{Type: subnetType, Address: address})
+	}
+
+	if len(iface.Nameservers) == 0 && len(iface.SearchDomains) == 0 && len(iface.Routes) == 0 {
+		return subnets, nil
+	}
+
+	if len(subnets) == 0 {
+		subnets = append(subnets, v1Subnet{Type: "manual"})
+	}
+
+	subnets[0].DNSNameservers = iface.Nameservers
+	subnets[0].DNSSearch = iface.SearchDomains
+
+	for _, route := range iface.Routes {
+		destination, via, err := routeDestination(route)
+		if err != nil {
+			return nil, err
+		}
+
+		v1 := v1Route{
+			Network: destination.Addr().String(),
+			Gateway: via.String(),
+			Metric:  route.Metric,
+		}
+
+		if destination.Addr().Is4() {
+			v1.Netmask = net.IP(net.CIDRMask(destination.Bits(), 32)).String()
+		} else {
+			bits := destination.Bits()
+			v1.Prefix = &bits
+		}
+
+		subnets[0].Routes = append(subnets[0].Routes, v1)
+	}
+
+	return subnets, nil
+}
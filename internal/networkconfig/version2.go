@@ -0,0 +1,204 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package networkconfig
+
+import (
+	"go.yaml.in/yaml/v3"
+)
+
+// v2Interface is an interface of the version 2 format. The fields of the other
+// kinds of interfaces are left empty.
+type v2Interface struct {
+	Match       *v2Match       `yaml:"match,omitempty"`
+	SetName     string         `yaml:"set-name,omitempty"`
+	Interfaces  []string       `yaml:"interfaces,omitempty"`
+	ID          int64          `yaml:"id,omitempty"`
+	Link        string         `yaml:"link,omitempty"`
+	Parameters  *v2Parameters  `yaml:"parameters,omitempty"`
+	Addresses   []string       `yaml:"addresses,omitempty"`
+	DHCP4       bool           `yaml:"dhcp4,omitempty"`
+	DHCP6       bool           `yaml:"dhcp6,omitempty"`
+	MTU         int64          `yaml:"mtu,omitempty"`
+	Nameservers *v2Nameservers `yaml:"nameservers,omitempty"`
+	Routes      []v2Route      `yaml:"routes,omitempty"`
+}
+
+type v2Match struct {
+	MACAddress string `yaml:"macaddress"`
+}
+
+type v2Parameters struct {
+	Mode               string `yaml:"mode,omitempty"`
+	MIIMonitorInterval int64  `yaml:"mii-monitor-interval,omitempty"`
+	Primary            string `yaml:"primary,omitempty"`
+	STP                *bool  `yaml:"stp,omitempty"`
+}
+
+type v2Nameservers struct {
+	Addresses []string `yaml:"addresses,omitempty"`
+	Search    []string `yaml:"search,omitempty"`
+}
+
+type v2Route struct {
+	To     string `yaml:"to"`
+	Via    string `yaml:"via"`
+	Metric int64  `yaml:"metric,omitempty"`
+}
+
+// version2 returns config as a version 2 document. The interfaces of each kind
+// are written as a mapping by name, in the order they are given.
+func version2(config Config) (*yaml.Node, error) {
+	document := &yaml.Node{Kind: yaml.MappingNode}
+	document.Content = append(document.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "version"},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "2"},
+	)
+
+	addInterfaces := func(key string, names []string, interfaces []v2Interface) error {
+		if len(interfaces) == 0 {
+			return nil
+		}
+
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+
+		for i, iface := range interfaces {
+			value := &yaml.Node{}
+			if err := value.Encode(iface); err != nil {
+				return err
+			}
+
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: names[i]}, value)
+		}
+
+		document.Content = append(document.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, mapping)
+
+		return nil
+	}
+
+	var names []string
+	var interfaces []v2Interface
+
+	for _, ethernet := range config.Ethernets {
+		iface, err := v2Common(ethernet.Interface)
+		if err != nil {
+			return nil, err
+		}
+
+		if ethernet.MACAddress != "" {
+			iface.Match = &v2Match{MACAddress: ethernet.MACAddress}
+			iface.SetName = ethernet.Name
+		}
+
+		names = append(names, ethernet.Name)
+		interfaces = append(interfaces, iface)
+	}
+
+	if err := addInterfaces("ethernets", names, interfaces); err != nil {
+		return nil, err
+	}
+
+	names, interfaces = nil, nil
+
+	for _, bond := range config.Bonds {
+		iface, err := v2Common(bond.Interface)
+		if err != nil {
+			return nil, err
+		}
+
+		iface.Interfaces = bond.Interfaces
+
+		if bond.Mode != "" || bond.MIIMonitorInterval != 0 || bond.Primary != "" {
+			iface.Parameters = &v2Parameters{
+				Mode:               bond.Mode,
+				MIIMonitorInterval: bond.MIIMonitorInterval,
+				Primary:            bond.Primary,
+			}
+		}
+
+		names = append(names, bond.Name)
+		interfaces = append(interfaces, iface)
+	}
+
+	if err := addInterfaces("bonds", names, interfaces); err != nil {
+		return nil, err
+	}
+
+	names, interfaces = nil, nil
+
+	for _, bridge := range config.Bridges {
+		iface, err := v2Common(bridge.Interface)
+		if err != nil {
+			return nil, err
+		}
+
+		iface.Interfaces = bridge.Interfaces
+
+		if bridge.STP != nil {
+			iface.Parameters = &v2Parameters{STP: bridge.STP}
+		}
+
+		names = append(names, bridge.Name)
+		interfaces = append(interfaces, iface)
+	}
+
+	if err := addInterfaces("bridges", names, interfaces); err != nil {
+		return nil, err
+	}
+
+	names, interfaces = nil, nil
+
+	for _, vlan := range config.VLANs {
+		iface, err := v2Common(vlan.Interface)
+		if err != nil {
+			return nil, err
+		}
+
+		iface.ID = vlan.ID
+		iface.Link = vlan.Link
+
+		names = append(names, vlan.Name)
+		interfaces = append(interfaces, iface)
+	}
+
+	if err := addInterfaces("vlans", names, interfaces); err != nil {
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// v2Common returns the settings every kind of interface has.
+func v2Common(iface Interface) (v2Interface, error) {
+	result := v2Interface{
+		Addresses: iface.Addresses,
+		DHCP4:     iface.DHCP4,
+		DHCP6:     iface.DHCP6,
+		MTU:       iface.MTU,
+	}
+
+	if len(iface.Nameservers) > 0 || len(iface.SearchDomains) > 0 {
+		result.Nameservers = &v2Nameservers{
+			Addresses: iface.Nameservers,
+			Search:    iface.SearchDomains,
+		}
+	}
+
+	for _, route := range iface.Routes {
+		// The default route is written as a network, which older releases of
+		// netplan and the renderers of cloud-init for other distributions
+		// understand as well.
+		destination, _, err := routeDestination(route)
+		if err != nil {
+			return v2Interface{}, err
+		}
+
+		result.Routes = append(result.Routes, v2Route{
+			To:     destination.String(),
+			Via:    route.Via,
+			Metric: route.Metric,
+		})
+	}
+
+	return result, nil
+}
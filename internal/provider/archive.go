@@ -0,0 +1,169 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+)
+
+// archiveContentType is the content type of cloud-config-archive parts, whose
+// entries cloud-init handles as parts of their own.
+const archiveContentType = "text/cloud-config-archive"
+
+// isArchive returns whether the part is a cloud-config-archive document.
+func (p configPartModel) isArchive() bool {
+	mediaType, _, err := mime.ParseMediaType(p.ContentType.ValueString())
+	if err != nil {
+		mediaType = p.ContentType.ValueString()
+	}
+
+	return strings.EqualFold(mediaType, archiveContentType)
+}
+
+// expandsArchiveParts returns whether cloud-config-archive parts are expanded
+// into a part per entry. The archive output format always expands them, as
+// cloud-init does not expand an archive within an archive.
+func (c configModel) expandsArchiveParts() bool {
+	return c.ExpandArchiveParts.ValueBool() || c.outputFormat() == outputFormatArchive
+}
+
+// expandArchiveParts replaces each cloud-config-archive part with a part per
+// entry, as cloud-init does when it reads the user data. Other parts are kept
+// as they are.
+func expandArchiveParts(parts []configPartModel) ([]configPartModel, error) {
+	expanded := make([]configPartModel, 0, len(parts))
+
+	for i, part := range parts {
+		if !part.isArchive() {
+			expanded = append(expanded, part)
+			continue
+		}
+
+		body, err := part.body()
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", i, err)
+		}
+
+		entries, err := cloudconfig.ParseArchive(body)
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", i, err)
+		}
+
+		for _, entry := range entries {
+			expanded = append(expanded, archiveEntryPart(entry))
+		}
+	}
+
+	return expanded, nil
+}
+
+// archiveEntryPart returns the part of an entry of a cloud-config-archive
+// document. Binary content is kept base64 encoded, like content_base64.
+func archiveEntryPart(entry cloudconfig.ArchiveEntry) configPartModel {
+	part := configPartModel{
+		ContentType:   types.StringValue(archiveEntryType(entry)),
+		Content:       types.StringNull(),
+		ContentBase64: types.StringNull(),
+		CloudConfig:   types.StringNull(),
+		Include:       types.ObjectNull(configPartIncludeAttrTypes),
+		FileName:      types.StringNull(),
+		MergeType:     types.StringNull(),
+		ContentSize:   types.Int64Value(int64(len(entry.Content))),
+		launchIndex:   entry.LaunchIndex,
+	}
+
+	if utf8.Valid(entry.Content) {
+		part.Content = types.StringValue(string(entry.Content))
+	} else {
+		part.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(entry.Content))
+	}
+
+	if entry.Filename != "" {
+		part.FileName = types.StringValue(entry.Filename)
+	}
+
+	if entry.MergeType != "" {
+		part.MergeType = types.StringValue(entry.MergeType)
+	}
+
+	return part
+}
+
+// archiveEntryType returns the content type of an entry. Like cloud-init, an
+// entry without a type is inferred from its content, and taken as cloud-config
+// when it has no marker.
+func archiveEntryType(entry cloudconfig.ArchiveEntry) string {
+	if entry.Type != "" {
+		return entry.Type
+	}
+
+	if contentType := inferContentType(entry.Content); contentType != "text/plain" {
+		return contentType
+	}
+
+	return "text/cloud-config"
+}
+
+// validateArchiveParts checks that cloud-config-archive parts which are expanded
+// are documents which can be expanded, and the merge types of their entries.
+func (c configModel) validateArchiveParts(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.Parts.IsNull() || c.Parts.IsUnknown() || c.ExpandArchiveParts.IsUnknown() || c.OutputFormat.IsUnknown() || !c.expandsArchiveParts() {
+		return diags
+	}
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, part := range configParts {
+		if part.ContentType.IsUnknown() || !part.isArchive() || !part.bodyKnown() {
+			continue
+		}
+
+		body, err := part.body()
+		if err != nil {
+			continue
+		}
+
+		entries, err := cloudconfig.ParseArchive(body)
+		if err != nil {
+			diags.AddAttributeError(
+				part.contentPath(i),
+				"Invalid cloud-config-archive Content",
+				endSentence(fmt.Sprintf("The content of part %d is not a valid cloud-config-archive document: %s", i, c.contentDetail(err.Error()))),
+			)
+			continue
+		}
+
+		for j, entry := range entries {
+			if entry.MergeType == "" {
+				continue
+			}
+
+			if _, err := parseMergers(entry.MergeType); err != nil {
+				diags.AddAttributeError(
+					part.contentPath(i),
+					"Invalid cloud-config-archive Content",
+					endSentence(fmt.Sprintf("Entry %d of the content of part %d has an invalid X-Merge-Type: %s", j, i, c.contentDetail(fmt.Sprintf("%q: %s", entry.MergeType, err)))),
+				)
+			}
+		}
+	}
+
+	return diags
+}
@@ -0,0 +1,158 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+)
+
+// autoBoundaryValue is the boundary value which selects a boundary derived
+// from the parts, see autoBoundary.
+const autoBoundaryValue = "auto"
+
+// mimeBoundary returns the boundary to render the parts with, resolving the
+// auto boundary value.
+func (c configModel) mimeBoundary(parts []configPartModel) string {
+	if c.Boundary.ValueString() == autoBoundaryValue {
+		return autoBoundary(parts)
+	}
+
+	return c.Boundary.ValueString()
+}
+
+// autoBoundary derives a boundary from a hash of the parts, so that it is
+// stable across plans. In the unlikely case that a part collides with it, the
+// hash is hashed again until no part does.
+func autoBoundary(parts []configPartModel) string {
+	hash := sha256.New()
+
+	for _, part := range parts {
+		// Invalid content is reported by validate, and fails the render.
+		body, _ := part.body()
+
+		for _, field := range [][]byte{
+			[]byte(part.ContentType.ValueString()),
+			[]byte(part.FileName.ValueString()),
+			[]byte(part.MergeType.ValueString()),
+			body,
+		} {
+			fmt.Fprintf(hash, "%d:", len(field))
+			hash.Write(field)
+		}
+	}
+
+	sum := hash.Sum(nil)
+
+	for {
+		boundary := "MIMEBOUNDARY-" + hex.EncodeToString(sum[:16])
+		if collidingPart(parts, boundary) < 0 {
+			return boundary
+		}
+
+		next := sha256.Sum256(sum)
+		sum = next[:]
+	}
+}
+
+// collidingPart returns the index of the first part whose content contains
+// the boundary delimiter at the start of a line, or -1 if there is none.
+// Parts written base64 encoded cannot collide, as "-" is not part of the
+// base64 alphabet.
+func collidingPart(parts []configPartModel, boundary string) int {
+	for i, part := range parts {
+		if !part.ContentBase64.IsNull() {
+			continue
+		}
+
+		body, err := part.body()
+		if err != nil {
+			continue
+		}
+
+		if containsBoundary(body, boundary) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// containsBoundary returns whether a line of body starts with the delimiter
+// of boundary, which RFC 2046 forbids as it would end the part early.
+func containsBoundary(body []byte, boundary string) bool {
+	delimiter := []byte("--" + boundary)
+
+	for {
+		if bytes.HasPrefix(body, delimiter) {
+			return true
+		}
+
+		i := bytes.IndexByte(body, '\n')
+		if i < 0 {
+			return false
+		}
+
+		body = body[i+1:]
+	}
+}
+
+// validateBoundary checks that no part collides with the configured boundary,
+// which would make cloud-init split the part in the wrong place. The entries of
+// cloud-config-archive parts which are expanded are checked as the parts they
+// are expanded into.
+func (c configModel) validateBoundary(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !c.isKnown() || c.Boundary.ValueString() == autoBoundaryValue || c.outputFormat() != outputFormatMIME {
+		return diags
+	}
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	boundary := c.Boundary.ValueString()
+
+	for i, part := range configParts {
+		if !c.expandsArchiveParts() || !part.isArchive() {
+			if collidingPart([]configPartModel{part}, boundary) >= 0 {
+				diags.AddAttributeError(part.contentPath(i), "MIME Boundary Collision", boundaryCollisionDetail(i, boundary))
+				return diags
+			}
+			continue
+		}
+
+		// Invalid archives are reported by validateArchiveParts.
+		entries, err := expandArchiveParts([]configPartModel{part})
+		if err != nil {
+			continue
+		}
+
+		if j := collidingPart(entries, boundary); j >= 0 {
+			diags.AddAttributeError(
+				part.contentPath(i),
+				"MIME Boundary Collision",
+				fmt.Sprintf("Entry %d of the cloud-config-archive in part %d has a line starting with %q, the delimiter of the MIME boundary, "+
+					"which would end the part it is expanded into early. Set boundary to a value which does not occur in the content, or to "+
+					"\"auto\" to derive one from the parts.", j, i, "--"+boundary),
+			)
+			return diags
+		}
+	}
+
+	return diags
+}
+
+func boundaryCollisionDetail(i int, boundary string) string {
+	return fmt.Sprintf("The content of part %d has a line starting with %q, the delimiter of the MIME boundary, which would end "+
+		"the part early. Set boundary to a value which does not occur in the content, or to \"auto\" to derive one from the parts.", i, "--"+boundary)
+}
@@ -4,39 +4,120 @@
 package provider
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/textproto"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
 	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
 )
 
 // Model and functionality of data source and resource are equivalent.
 type configModel struct {
-	ID           types.String `tfsdk:"id"`
-	Parts        types.List   `tfsdk:"part"` // configPartModel
-	Gzip         types.Bool   `tfsdk:"gzip"`
-	Base64Encode types.Bool   `tfsdk:"base64_encode"`
-	Boundary     types.String `tfsdk:"boundary"`
-	Rendered     types.String `tfsdk:"rendered"`
+	ID                    types.String `tfsdk:"id"`
+	Parts                 types.List   `tfsdk:"part"` // configPartModel
+	Gzip                  types.Bool   `tfsdk:"gzip"`
+	Base64Encode          types.Bool   `tfsdk:"base64_encode"`
+	Boundary              types.String `tfsdk:"boundary"`
+	CloudInitVersion      types.String `tfsdk:"cloud_init_version"`
+	CloudConfigValidation types.String `tfsdk:"cloud_config_validation"`
+	TargetPlatform        types.String `tfsdk:"target_platform"`
+	CustomContentTypes    types.List   `tfsdk:"custom_content_types"` // types.String
+	OutputFormat          types.String `tfsdk:"output_format"`
+	ExpandArchiveParts    types.Bool   `tfsdk:"expand_archive_parts"`
+	SensitiveOutput       types.Bool   `tfsdk:"sensitive_output"`
+	Rendered              types.String `tfsdk:"rendered"`
+	RenderedSensitive     types.String `tfsdk:"rendered_sensitive"`
+	RenderedSHA256        types.String `tfsdk:"rendered_sha256"`
+	RenderedSHA512        types.String `tfsdk:"rendered_sha512"`
+	RenderedMD5           types.String `tfsdk:"rendered_md5"`
+	MIMESHA256            types.String `tfsdk:"mime_sha256"`
+	MIMESize              types.Int64  `tfsdk:"mime_size"`
+	CompressedSize        types.Int64  `tfsdk:"compressed_size"`
+	RenderedSize          types.Int64  `tfsdk:"rendered_size"`
 }
 
 type configPartModel struct {
-	ContentType types.String `tfsdk:"content_type"`
-	Content     types.String `tfsdk:"content"`
-	FileName    types.String `tfsdk:"filename"`
-	MergeType   types.String `tfsdk:"merge_type"`
+	ContentType   types.String `tfsdk:"content_type"`
+	Content       types.String `tfsdk:"content"`
+	ContentBase64 types.String `tfsdk:"content_base64"`
+	CloudConfig   types.String `tfsdk:"cloud_config"`
+	Include       types.Object `tfsdk:"include"` // configPartIncludeAttrTypes
+	FileName      types.String `tfsdk:"filename"`
+	MergeType     types.String `tfsdk:"merge_type"`
+	ContentSize   types.Int64  `tfsdk:"content_size"`
+
+	// launchIndex is the launch-index of a part expanded from a
+	// cloud-config-archive part, which is not part of the schema.
+	launchIndex *int
+}
+
+// configPartAttrTypes mirrors configPartModel, for building part lists outside
+// of a schema, such as in provider-defined functions.
+var configPartAttrTypes = map[string]attr.Type{
+	"content_type":   types.StringType,
+	"content":        types.StringType,
+	"content_base64": types.StringType,
+	"cloud_config":   types.StringType,
+	"include":        types.ObjectType{AttrTypes: configPartIncludeAttrTypes},
+	"filename":       types.StringType,
+	"merge_type":     types.StringType,
+	"content_size":   types.Int64Type,
+}
+
+// body returns the content of the part, decoding content_base64 if it is set.
+func (p configPartModel) body() ([]byte, error) {
+	if !p.ContentBase64.IsNull() {
+		return decodeBase64(p.ContentBase64.ValueString())
+	}
+
+	if !p.CloudConfig.IsNull() {
+		return cloudconfig.MarshalJSON([]byte(p.CloudConfig.ValueString()))
+	}
+
+	if !p.Include.IsNull() {
+		return p.includeBody(), nil
+	}
+
+	return []byte(p.Content.ValueString()), nil
+}
+
+// bodyKnown returns whether the content of the part is known, whichever
+// attribute or block it is set with.
+func (p configPartModel) bodyKnown() bool {
+	return !p.Content.IsUnknown() && !p.ContentBase64.IsUnknown() && !p.CloudConfig.IsUnknown() && includeKnown(p.Include)
+}
+
+// contentPath returns the path of the attribute holding the content of the
+// part at index i, for diagnostics about its content.
+func (p configPartModel) contentPath(i int) path.Path {
+	switch {
+	case !p.ContentBase64.IsNull():
+		return path.Root("part").AtListIndex(i).AtName("content_base64")
+	case !p.CloudConfig.IsNull():
+		return path.Root("part").AtListIndex(i).AtName("cloud_config")
+	case !p.Include.IsNull():
+		return path.Root("part").AtListIndex(i).AtName("include")
+	}
+
+	return path.Root("part").AtListIndex(i).AtName("content")
 }
 
 func (c *configModel) setDefaults(ctx context.Context) diag.Diagnostics {
@@ -64,7 +145,14 @@ func (c *configModel) setDefaults(ctx context.Context) diag.Diagnostics {
 
 	for i, part := range configParts {
 		if part.ContentType.IsNull() || part.ContentType.ValueString() == "" {
-			configParts[i].ContentType = types.StringValue("text/plain")
+			if !part.bodyKnown() {
+				continue
+			}
+
+			// Invalid base64 or JSON content is reported by validate, so it is
+			// treated as having no marker here.
+			body, _ := part.body()
+			configParts[i].ContentType = types.StringValue(inferContentType(body))
 		}
 	}
 
@@ -98,6 +186,207 @@ func (c configModel) validate(ctx context.Context) diag.Diagnostics {
 		)
 	}
 
+	diags.Append(c.validateContentTypes(ctx)...)
+	diags.Append(c.validatePartBodies(ctx)...)
+	diags.Append(c.validateCloudConfigParts(ctx)...)
+	diags.Append(c.validateMergeTypes(ctx)...)
+	diags.Append(c.validateArchiveParts(ctx)...)
+	diags.Append(c.validateIncludeParts(ctx)...)
+	diags.Append(c.validateBoundary(ctx)...)
+	diags.Append(c.validateOutputFormat(ctx)...)
+
+	return diags
+}
+
+// isKnown returns whether every value which affects the rendered output is known.
+func (c configModel) isKnown() bool {
+	if c.Parts.IsUnknown() || c.Boundary.IsUnknown() || c.TargetPlatform.IsUnknown() || c.OutputFormat.IsUnknown() || c.ExpandArchiveParts.IsUnknown() {
+		return false
+	}
+
+	for _, element := range c.Parts.Elements() {
+		part, ok := element.(types.Object)
+		if !ok || part.IsUnknown() {
+			return false
+		}
+
+		for _, value := range part.Attributes() {
+			if value.IsUnknown() {
+				return false
+			}
+
+			if include, ok := value.(types.Object); ok && !includeKnown(include) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// validatePartBodies checks that content_base64 is base64 encoded and that
+// cloud_config is a JSON object, which is only used for text/cloud-config parts.
+func (c configModel) validatePartBodies(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.Parts.IsNull() || c.Parts.IsUnknown() {
+		return diags
+	}
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, part := range configParts {
+		if !part.ContentBase64.IsNull() && !part.ContentBase64.IsUnknown() {
+			if _, err := part.body(); err != nil {
+				diags.AddAttributeError(
+					path.Root("part").AtListIndex(i).AtName("content_base64"),
+					"Invalid Attribute Value",
+					fmt.Sprintf("Expected content_base64 to be base64 encoded: %s.", err),
+				)
+			}
+		}
+
+		if part.CloudConfig.IsNull() || part.CloudConfig.IsUnknown() {
+			continue
+		}
+
+		if _, err := part.body(); err != nil {
+			diags.AddAttributeError(
+				path.Root("part").AtListIndex(i).AtName("cloud_config"),
+				"Invalid Attribute Value",
+				fmt.Sprintf("Expected cloud_config to be a JSON object, such as the result of jsonencode: %s.", err),
+			)
+			continue
+		}
+
+		if !part.ContentType.IsUnknown() && part.ContentType.ValueString() != "text/cloud-config" {
+			diags.AddAttributeError(
+				path.Root("part").AtListIndex(i).AtName("content_type"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("Expected content_type to be \"text/cloud-config\" when cloud_config is set, got %q.", part.ContentType.ValueString()),
+			)
+		}
+	}
+
+	return diags
+}
+
+const (
+	// cloudConfigValidationWarning reports problems with the content of
+	// text/cloud-config parts as warnings, like cloud-init does on boot, the
+	// default.
+	cloudConfigValidationWarning = "warning"
+
+	// cloudConfigValidationError reports values which do not match the
+	// cloud-config schema as errors.
+	cloudConfigValidationError = "error"
+
+	// cloudConfigValidationNone skips validating the content of
+	// text/cloud-config parts.
+	cloudConfigValidationNone = "none"
+)
+
+// cloudConfigValidations returns the supported values of cloud_config_validation.
+func cloudConfigValidations() []string {
+	return []string{cloudConfigValidationWarning, cloudConfigValidationError, cloudConfigValidationNone}
+}
+
+// cloudConfigValidation returns how problems with cloud-config content are
+// reported, which defaults to warnings.
+func (c configModel) cloudConfigValidation() string {
+	if c.CloudConfigValidation.IsNull() {
+		return cloudConfigValidationWarning
+	}
+
+	return c.CloudConfigValidation.ValueString()
+}
+
+// validateCloudConfigParts checks the content of text/cloud-config parts against
+// the cloud-config schema of the configured cloud-init version. Parts which are
+// Jinja templates are skipped, as they are only valid YAML once rendered on boot.
+//
+// Keys the schema does not have are always reported as warnings, as the bundled
+// schemas only cover the most common modules and cloud-init ignores keys no
+// module handles.
+func (c configModel) validateCloudConfigParts(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.Parts.IsNull() || c.Parts.IsUnknown() || c.CloudInitVersion.IsUnknown() || c.CloudConfigValidation.IsUnknown() {
+		return diags
+	}
+
+	if c.cloudConfigValidation() == cloudConfigValidationNone {
+		return diags
+	}
+
+	version := cloudconfig.LatestVersion
+	if !c.CloudInitVersion.IsNull() {
+		version = c.CloudInitVersion.ValueString()
+	}
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, part := range configParts {
+		if part.ContentType.ValueString() != "text/cloud-config" || !part.bodyKnown() {
+			continue
+		}
+
+		body, err := part.body()
+		if err != nil || bytes.HasPrefix(body, []byte("## template: jinja")) {
+			continue
+		}
+
+		contentPath := part.contentPath(i)
+
+		problems, err := cloudconfig.Validate(string(body), version)
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("cloud_init_version"),
+				"Invalid Attribute Value",
+				err.Error(),
+			)
+			return diags
+		}
+
+		for _, problem := range problems {
+			switch {
+			case problem.Kind == cloudconfig.DiagnosticDeprecated:
+				diags.AddAttributeWarning(
+					contentPath,
+					"Deprecated cloud-config Content",
+					fmt.Sprintf("The content of part %d uses a deprecated cloud-config key in cloud-init %s.\n\n%s", i, version, c.contentProblem(problem)),
+				)
+			case problem.Kind == cloudconfig.DiagnosticUnknownKey:
+				diags.AddAttributeWarning(
+					contentPath,
+					"Unknown cloud-config Key",
+					fmt.Sprintf("The content of part %d has a key which is not in the cloud-config schema of cloud-init %s bundled with the provider. "+
+						"The bundled schema only covers the most common modules, and cloud-init ignores keys which no module handles.\n\n%s", i, version, c.contentProblem(problem)),
+				)
+			case c.cloudConfigValidation() == cloudConfigValidationError:
+				diags.AddAttributeError(
+					contentPath,
+					"Invalid cloud-config Content",
+					fmt.Sprintf("The content of part %d does not match the cloud-config schema of cloud-init %s.\n\n%s", i, version, c.contentProblem(problem)),
+				)
+			default:
+				diags.AddAttributeWarning(
+					contentPath,
+					"Invalid cloud-config Content",
+					fmt.Sprintf("The content of part %d does not match the cloud-config schema of cloud-init %s.\n\n%s", i, version, c.contentProblem(problem)),
+				)
+			}
+		}
+	}
+
 	return diags
 }
 
@@ -119,21 +408,48 @@ func (c *configModel) update(ctx context.Context) diag.Diagnostics {
 		return diags
 	}
 
-	if c.Gzip.ValueBool() {
-		gzipWriter := gzip.NewWriter(&buffer)
+	var mimeBuffer bytes.Buffer
 
-		err = renderPartsToWriter(ctx, c.Boundary.ValueString(), configParts, gzipWriter)
+	err = c.renderDocumentToWriter(ctx, configParts, &mimeBuffer)
+	if err != nil {
+		diags.AddError("Unable to render cloudinit config to MIME multi-part file", err.Error())
+		return diags
+	}
 
-		gzipWriter.Close()
-	} else {
-		err = renderPartsToWriter(ctx, c.Boundary.ValueString(), configParts, &buffer)
+	for i, part := range configParts {
+		// Invalid content fails the render above.
+		body, _ := part.body()
+		configParts[i].ContentSize = types.Int64Value(int64(len(body)))
 	}
 
-	if err != nil {
-		diags.AddError("Unable to render cloudinit config to MIME multi-part file", err.Error())
+	partsList, convertDiags := types.ListValueFrom(ctx, c.Parts.ElementType(ctx), configParts)
+	diags.Append(convertDiags...)
+	if diags.HasError() {
 		return diags
 	}
 
+	c.Parts = partsList
+	c.MIMESize = types.Int64Value(int64(mimeBuffer.Len()))
+	c.CompressedSize = types.Int64Null()
+
+	if c.Gzip.ValueBool() {
+		gzipWriter := gzip.NewWriter(&buffer)
+
+		_, err = gzipWriter.Write(mimeBuffer.Bytes())
+		if err == nil {
+			err = gzipWriter.Close()
+		}
+
+		if err != nil {
+			diags.AddError("Unable to gzip cloudinit config", err.Error())
+			return diags
+		}
+
+		c.CompressedSize = types.Int64Value(int64(buffer.Len()))
+	} else {
+		buffer.Write(mimeBuffer.Bytes())
+	}
+
 	output := ""
 	if c.Base64Encode.ValueBool() {
 		output = base64.StdEncoding.EncodeToString(buffer.Bytes())
@@ -142,7 +458,21 @@ func (c *configModel) update(ctx context.Context) diag.Diagnostics {
 	}
 
 	c.ID = types.StringValue(strconv.Itoa(hashcode.String(output)))
-	c.Rendered = types.StringValue(output)
+	if c.SensitiveOutput.ValueBool() {
+		c.Rendered = types.StringNull()
+		c.RenderedSensitive = types.StringValue(output)
+	} else {
+		c.Rendered = types.StringValue(output)
+		c.RenderedSensitive = types.StringNull()
+	}
+
+	c.RenderedSHA256 = types.StringValue(hashcode.SHA256(output))
+	c.RenderedSHA512 = types.StringValue(hashcode.SHA512(output))
+	c.RenderedMD5 = types.StringValue(hashcode.MD5(output))
+	c.MIMESHA256 = types.StringValue(hashcode.SHA256(mimeBuffer.String()))
+	c.RenderedSize = types.Int64Value(int64(len(output)))
+
+	diags.Append(c.checkPlatformLimit(buffer.Len(), len(output))...)
 
 	return diags
 }
@@ -172,12 +502,25 @@ func renderPartsToWriter(ctx context.Context, mimeBoundary string, parts []confi
 		return err
 	}
 
-	for _, part := range parts {
+	for i, part := range parts {
 		header := textproto.MIMEHeader{}
 
+		body, err := part.body()
+		if err != nil {
+			return fmt.Errorf("part %d: %w", i, err)
+		}
+
+		// Binary content is only safe to transfer base64 encoded
+		transferEncoding := "7bit"
+		if !part.ContentBase64.IsNull() {
+			transferEncoding, body = "base64", encodeBase64Lines(body)
+		} else if containsBoundary(body, mimeBoundary) {
+			return errors.New(boundaryCollisionDetail(i, mimeBoundary))
+		}
+
 		header.Set("Content-Type", part.ContentType.ValueString())
 		header.Set("MIME-Version", "1.0")
-		header.Set("Content-Transfer-Encoding", "7bit")
+		header.Set("Content-Transfer-Encoding", transferEncoding)
 
 		if part.FileName.ValueString() != "" {
 			header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, part.FileName.ValueString()))
@@ -187,12 +530,16 @@ func renderPartsToWriter(ctx context.Context, mimeBoundary string, parts []confi
 			header.Set("X-Merge-Type", part.MergeType.ValueString())
 		}
 
+		if part.launchIndex != nil {
+			header.Set("Launch-Index", strconv.Itoa(*part.launchIndex))
+		}
+
 		partWriter, err := mimeWriter.CreatePart(header)
 		if err != nil {
 			return err
 		}
 
-		_, err = partWriter.Write([]byte(part.Content.ValueString()))
+		_, err = partWriter.Write(body)
 		if err != nil {
 			return err
 		}
@@ -200,3 +547,143 @@ func renderPartsToWriter(ctx context.Context, mimeBoundary string, parts []confi
 
 	return nil
 }
+
+// encodeBase64Lines base64 encodes data with lines wrapped at 76 characters,
+// as required for the base64 Content-Transfer-Encoding by RFC 2045.
+func encodeBase64Lines(data []byte) []byte {
+	const lineLength = 76
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	var buffer bytes.Buffer
+	for len(encoded) > lineLength {
+		buffer.WriteString(encoded[:lineLength])
+		buffer.WriteString("\r\n")
+		encoded = encoded[lineLength:]
+	}
+	buffer.WriteString(encoded)
+
+	return buffer.Bytes()
+}
+
+// decodeBase64 decodes standard base64, ignoring any line breaks or other
+// whitespace in the encoded string.
+func decodeBase64(encoded string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+}
+
+// decodeRendered reverses update, returning the parts of a rendered cloud-init
+// config. Base64 encoding and gzip compression are detected automatically. User
+// data which is not a multi-part MIME document is returned as a single part.
+func decodeRendered(rendered string) ([]configPartModel, error) {
+	data := []byte(rendered)
+
+	if !isGzip(data) {
+		if decoded, err := decodeBase64(rendered); err == nil && len(decoded) > 0 {
+			if isGzip(decoded) || utf8.Valid(decoded) {
+				data = decoded
+			}
+		}
+	}
+
+	if isGzip(data) {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		data, err = io.ReadAll(gzipReader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(data))).ReadMIMEHeader()
+	if err != nil && !errors.Is(err, io.EOF) {
+		header = nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/mixed" || params["boundary"] == "" {
+		return []configPartModel{
+			{
+				ContentType:   types.StringValue(inferContentType(data)),
+				Content:       types.StringValue(string(data)),
+				ContentBase64: types.StringNull(),
+				CloudConfig:   types.StringNull(),
+				Include:       types.ObjectNull(configPartIncludeAttrTypes),
+				FileName:      types.StringNull(),
+				MergeType:     types.StringNull(),
+				ContentSize:   types.Int64Value(int64(len(data))),
+			},
+		}, nil
+	}
+
+	return readPartsFromReader(bytes.NewReader(data), params["boundary"])
+}
+
+func readPartsFromReader(reader io.Reader, mimeBoundary string) ([]configPartModel, error) {
+	var parts []configPartModel
+
+	mimeReader := multipart.NewReader(reader, mimeBoundary)
+
+	for {
+		part, err := mimeReader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+
+		configPart := configPartModel{
+			ContentType:   types.StringValue(part.Header.Get("Content-Type")),
+			Content:       types.StringValue(string(content)),
+			ContentBase64: types.StringNull(),
+			CloudConfig:   types.StringNull(),
+			Include:       types.ObjectNull(configPartIncludeAttrTypes),
+			FileName:      types.StringNull(),
+			MergeType:     types.StringNull(),
+		}
+
+		// Base64 encoded parts may hold binary content, which is not a valid
+		// string, so they are returned as content_base64 like they were given.
+		if strings.EqualFold(part.Header.Get("Content-Transfer-Encoding"), "base64") {
+			decoded, err := decodeBase64(string(content))
+			if err != nil {
+				return nil, fmt.Errorf("decoding part %d: %w", len(parts), err)
+			}
+
+			configPart.Content = types.StringNull()
+			configPart.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(decoded))
+			content = decoded
+		}
+
+		configPart.ContentSize = types.Int64Value(int64(len(content)))
+
+		if part.Header.Get("Content-Type") == "" {
+			configPart.ContentType = types.StringValue(inferContentType(content))
+		}
+
+		if _, dispositionParams, err := mime.ParseMediaType(part.Header.Get("Content-Disposition")); err == nil && dispositionParams["filename"] != "" {
+			configPart.FileName = types.StringValue(dispositionParams["filename"])
+		}
+
+		if mergeType := part.Header.Get("X-Merge-Type"); mergeType != "" {
+			configPart.MergeType = types.StringValue(mergeType)
+		}
+
+		parts = append(parts, configPart)
+	}
+
+	return parts, nil
+}
+
+func isGzip(data []byte) bool {
+	return len(data) > 2 && data[0] == 0x1f && data[1] == 0x8b
+}
@@ -0,0 +1,122 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+)
+
+// Descriptions of the attributes shared by the data sources, resources and
+// ephemeral resource rendering parts, whose schemas are built with the schema
+// types of each.
+const (
+	partContentTypeDescription = "A MIME-style content type to report in the header for the part. Must be a content type handled by cloud-init, " +
+		"or listed in `custom_content_types`. When omitted, the content type is inferred from the first line of the content " +
+		"like cloud-init does, such as `text/cloud-config` for `#cloud-config` or `text/x-shellscript` for `#!`, and " +
+		"defaults to `text/plain`."
+	partContentDescription       = "Body content for the part."
+	partContentBase64Description = "Base64 encoded body content for the part, for binary content such as archives. The part is written with " +
+		"`Content-Transfer-Encoding: base64`."
+	partCloudConfigDescription = "A cloud-config document for the part as a JSON string, such as the result of `jsonencode` of an object. " +
+		"It takes a string rather than an object, as attributes of any type are not supported in blocks such as `part`. It is written " +
+		"as YAML with the `#cloud-config` header, sorted keys and block scalars for multi-line strings, and the content type " +
+		"defaults to `text/cloud-config`."
+	partIncludeDescription = "A list of URLs for cloud-init to fetch on boot and handle like user data of their own, written as the " +
+		"content of the part after an `#include` line, or `#include-once` when `once` is set. The content type defaults to " +
+		"`text/x-include-url`, or `text/x-include-once-url` when `once` is set."
+	partIncludeURLsDescription = "The URLs to fetch, in order. At least one must be set, and each must be an absolute `https`, `http` " +
+		"or `file` URL. Plaintext `http` URLs are reported with a warning, as their content can be tampered with in transit."
+	partIncludeOnceDescription = "Set to `true` for cloud-init to fetch each URL only once per instance, and use the " +
+		"content it cached on later boots. Defaults to `false`."
+	partFilenameDescription  = "A filename to report in the header for the part."
+	partMergeTypeDescription = "A value for the `X-Merge-Type` header of the part, to control " +
+		"[cloud-init merging behavior](https://cloudinit.readthedocs.io/en/latest/reference/merging.html), such as " +
+		"`list(append)+dict(recurse_array)+str()`. Must only use the `dict`, `list` and `str` mergers and the options they support."
+	partContentSizeDescription = "The size in bytes of the content of the part, after decoding `content_base64` or rendering `cloud_config` or `include`."
+
+	partBlockDescription = "A nested block type which adds a file to the generated cloud-init configuration. Use multiple " +
+		"`part` blocks to specify multiple files, which will be included in order of declaration in the final MIME document."
+
+	gzipDescription         = "Specify whether or not to gzip the `rendered` output. Defaults to `true`."
+	base64EncodeDescription = "Specify whether or not to base64 encode the `rendered` output. Defaults to `true`, and cannot be disabled if gzip is `true`."
+	boundaryDescription     = "Specify the Writer's default boundary separator. Defaults to `MIMEBOUNDARY`. The content of a part must " +
+		"not have a line starting with `--` followed by the boundary. Set to `auto` to derive a boundary from a hash of the parts " +
+		"which does not occur in their content, and stays the same as long as the parts do."
+	cloudInitVersionDescription = "The cloud-init release whose [cloud-config schema](https://cloudinit.readthedocs.io/en/latest/reference/modules.html) " +
+		"is used to validate the content of `text/cloud-config` parts. Defaults to the most recent release known to the provider, currently `" + cloudconfig.LatestVersion + "`."
+	cloudConfigValidationDescription = "How problems found by validating the content of `text/cloud-config` parts against the cloud-config schema " +
+		"of `cloud_init_version` are reported. `warning` reports them as warnings, like cloud-init does on boot, `error` fails validation " +
+		"on values which do not match the schema, and `none` skips the validation. Keys the schema does not have are always reported as " +
+		"warnings, as the schemas bundled with the provider only cover the most common modules. Defaults to `warning`."
+	targetPlatformDescription = "The platform the `rendered` output is intended for, one of `aws`, `azure`, `gcp`, `libvirt`, `openstack` or `vmware`. " +
+		"When set, the size of the `rendered` output is checked against the user data limit of the platform, such as 16 KiB for Amazon EC2."
+	customContentTypesDescription = "Additional content types to allow for parts, such as those handled by a custom " +
+		"[part-handler](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#part-handler). By default only the " +
+		"content types handled by cloud-init itself are allowed."
+	outputFormatDescription = "The format of the document holding the parts, before gzip compression and base64 encoding. " +
+		"`mime` renders a multi-part MIME message. `raw` renders the content of the only part as is, for platforms and images " +
+		"which do not handle multi-part MIME, and requires exactly one part whose content starts with the marker of its " +
+		"content type, such as `#cloud-config` or `#!`. `archive` renders a " +
+		"[cloud-config-archive](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#cloud-config-archive) document. " +
+		"Parts with `text/cloud-config-archive` content are expanded into a part per entry in the `archive` format, and in the " +
+		"`mime` format when `expand_archive_parts` is `true`. Defaults to `mime`."
+	expandArchivePartsDescription = "Set to `true` to expand parts with `text/cloud-config-archive` content into a part per entry in the " +
+		"`mime` output format, like cloud-init does when it reads the archive, so that the entries are checked like parts. The `archive` " +
+		"output format always expands them, as cloud-init does not expand an archive within an archive. Defaults to `false`."
+	sensitiveOutputDescription = "Set to `true` when the content of a part holds secrets, such as tokens or private keys. The output is " +
+		"then set in `rendered_sensitive`, which Terraform hides in plan output, in place of `rendered`, and validation errors " +
+		"leave out details which may quote the content. Defaults to `false`."
+	renderedDescription          = "The final rendered multi-part cloud-init config. `null` when `sensitive_output` is `true`."
+	renderedSensitiveDescription = "The final rendered multi-part cloud-init config when `sensitive_output` is `true`, and `null` otherwise."
+	mimeSizeDescription          = "The size in bytes of the multi-part MIME message, or the document of `output_format`, before it is gzip compressed and base64 encoded."
+	compressedSizeDescription    = "The size in bytes of the gzip compressed multi-part MIME message, or the document of `output_format`, before it is base64 encoded. " +
+		"`null` when `gzip` is `false`."
+	renderedSizeDescription = "The size in bytes of `rendered`, which is what most platforms check against their user data limit. " +
+		"Useful in `precondition` and `check` blocks."
+	renderedSHA256Description = "Hex encoded SHA-256 digest of `rendered`, the same as `sha256(rendered)`."
+	renderedSHA512Description = "Hex encoded SHA-512 digest of `rendered`, the same as `sha512(rendered)`."
+	renderedMD5Description    = "Hex encoded MD5 digest of `rendered`, the same as `md5(rendered)`. Prefer `rendered_sha256` unless a system requires MD5."
+	mimeSHA256Description     = "Hex encoded SHA-256 digest of the multi-part MIME message, or the document of `output_format`, before it is gzip compressed and base64 encoded, " +
+		"which stays the same whether or not `gzip` and `base64_encode` are set."
+	configIDDescription = "[CRC-32](https://pkg.go.dev/hash/crc32) checksum of `rendered` cloud-init config. Use `rendered_sha256` to detect changes or verify the output."
+
+	configDescriptionDetails = "Cloud-init is a commonly-used startup configuration utility for cloud compute instances. It accepts configuration via provider-specific " +
+		"user data mechanisms, such as `user_data` for Amazon EC2 instances. Multi-part MIME is one of the data formats it accepts. For more information, " +
+		"see [User-Data Formats](https://cloudinit.readthedocs.io/en/latest/explanation/format.html) in the cloud-init manual.\n\n" +
+		"This is not a generalized utility for producing multi-part MIME messages. Its feature set is specialized for cloud-init multi-part MIME messages."
+	configDescription = "Renders a [multi-part MIME configuration](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#mime-multi-part-archive) " +
+		"for use with [cloud-init](https://cloudinit.readthedocs.io/en/latest/).\n\n" + configDescriptionDetails
+)
+
+// partContentAttributes are the attributes and blocks setting the content of
+// a part, of which exactly one must be set.
+var partContentAttributes = []string{"content", "content_base64", "cloud_config", "include"}
+
+// exactlyOneOfDescription returns the sentence noting that exactly one of the
+// attributes must be set, to end their descriptions with.
+func exactlyOneOfDescription(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "`" + name + "`"
+	}
+
+	return "Exactly one of " + strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1] + " must be set."
+}
+
+// exactlyOneOfValidator returns the validator of the first of the attributes,
+// requiring exactly one of them to be set in a part.
+func exactlyOneOfValidator(names []string) validator.String {
+	expressions := make([]path.Expression, 0, len(names)-1)
+	for _, name := range names[1:] {
+		expressions = append(expressions, path.MatchRelative().AtParent().AtName(name))
+	}
+
+	return stringvalidator.ExactlyOneOf(expressions...)
+}
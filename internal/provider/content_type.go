@@ -0,0 +1,190 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+)
+
+// knownContentTypes are the part content types handled by cloud-init itself.
+// Compressed parts are decompressed, and text/x-not-multipart and
+// application/octet-stream parts are typed by their content like text/plain.
+// See https://cloudinit.readthedocs.io/en/latest/explanation/format.html
+var knownContentTypes = []string{
+	"application/gzip",
+	"application/octet-stream",
+	"application/x-gzip",
+	"text/cloud-boothook",
+	"text/cloud-config",
+	"text/cloud-config-archive",
+	"text/cloud-config-jsonp",
+	"text/jinja2",
+	"text/part-handler",
+	"text/plain",
+	"text/x-include-once-url",
+	"text/x-include-url",
+	"text/x-not-multipart",
+	"text/x-shellscript",
+	"text/x-shellscript-per-boot",
+	"text/x-shellscript-per-instance",
+	"text/x-shellscript-per-once",
+}
+
+// contentTypeMarkers are the markers cloud-init recognizes at the start of a
+// part without a content type, and the content type it handles the part as.
+// Longer markers come first, so that #include-once is not taken for #include.
+var contentTypeMarkers = []struct {
+	marker      string
+	contentType string
+}{
+	{"#cloud-config-archive", "text/cloud-config-archive"},
+	{"#cloud-config-jsonp", "text/cloud-config-jsonp"},
+	{"## template: jinja", "text/jinja2"},
+	{"#cloud-boothook", "text/cloud-boothook"},
+	{"#include-once", "text/x-include-once-url"},
+	{"#cloud-config", "text/cloud-config"},
+	{"#part-handler", "text/part-handler"},
+	{"#include", "text/x-include-url"},
+	{"#!", "text/x-shellscript"},
+}
+
+// inferContentType returns the content type cloud-init handles content as
+// based on its leading marker, or text/plain if there is none. Like cloud-init,
+// the marker is matched case-insensitively after leading whitespace.
+func inferContentType(content []byte) string {
+	content = bytes.TrimLeft(content, " \t\r\n")
+	start := strings.ToLower(string(content[:min(len(content), 64)]))
+
+	for _, m := range contentTypeMarkers {
+		if strings.HasPrefix(start, m.marker) {
+			return m.contentType
+		}
+	}
+
+	return "text/plain"
+}
+
+// validateContentTypes checks that the content type of every part is handled by
+// cloud-init, or has been allowed with custom_content_types for use with a
+// custom part-handler.
+func (c configModel) validateContentTypes(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.Parts.IsNull() || c.Parts.IsUnknown() || c.CustomContentTypes.IsUnknown() {
+		return diags
+	}
+
+	var customContentTypes []string
+	if !c.CustomContentTypes.IsNull() {
+		diags.Append(c.CustomContentTypes.ElementsAs(ctx, &customContentTypes, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	contentTypes := append(append([]string{}, knownContentTypes...), customContentTypes...)
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, part := range configParts {
+		if part.ContentType.IsNull() || part.ContentType.IsUnknown() {
+			continue
+		}
+
+		contentType := part.ContentType.ValueString()
+
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			mediaType = contentType
+		}
+
+		if containsFold(contentTypes, mediaType) {
+			continue
+		}
+
+		detail := fmt.Sprintf("Unknown content type %q", contentType)
+		if suggestion := cloudconfig.Suggest(strings.ToLower(mediaType), contentTypes); suggestion != "" {
+			detail += fmt.Sprintf(", did you mean %q?", suggestion)
+		} else {
+			detail += "."
+		}
+
+		detail += " cloud-init ignores parts with content types it does not handle. Content types handled by a " +
+			"custom part-handler can be allowed with custom_content_types."
+
+		diags.AddAttributeError(
+			path.Root("part").AtListIndex(i).AtName("content_type"),
+			"Invalid Attribute Value",
+			detail,
+		)
+	}
+
+	return diags
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var _ planmodifier.String = inferContentTypeModifier{}
+
+// inferContentTypeModifier plans the content type of a part without one as the
+// type inferred from its content, like setDefaults does for the data source.
+// The content type of existing resources is kept from state, so that parts
+// created with the text/plain earlier versions defaulted to are not replaced.
+type inferContentTypeModifier struct{}
+
+func (m inferContentTypeModifier) Description(ctx context.Context) string {
+	return "Infers the content type from the leading marker of the content when unset."
+}
+
+func (m inferContentTypeModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m inferContentTypeModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	if !req.ConfigValue.IsNull() || !req.PlanValue.IsUnknown() {
+		return
+	}
+
+	var part configPartModel
+
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, req.Path.ParentPath().AtName("content"), &part.Content)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, req.Path.ParentPath().AtName("content_base64"), &part.ContentBase64)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, req.Path.ParentPath().AtName("cloud_config"), &part.CloudConfig)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, req.Path.ParentPath().AtName("include"), &part.Include)...)
+
+	// Write-only content of the resource is only available in the configuration
+	if part.Content.IsNull() && part.ContentBase64.IsNull() && part.CloudConfig.IsNull() && part.Include.IsNull() {
+		resp.Diagnostics.Append(req.Config.GetAttribute(ctx, req.Path.ParentPath().AtName("content_wo"), &part.Content)...)
+	}
+
+	if resp.Diagnostics.HasError() || !part.bodyKnown() {
+		return
+	}
+
+	body, _ := part.body()
+	resp.PlanValue = types.StringValue(inferContentType(body))
+}
@@ -5,12 +5,16 @@ package provider
 
 import (
 	"context"
+	"maps"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
 )
 
 var (
@@ -37,84 +41,210 @@ func (d *configDataSource) ValidateConfig(ctx context.Context, req datasource.Va
 func (d *configDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Blocks: map[string]schema.Block{
-			"part": schema.ListNestedBlock{
-				Validators: []validator.List{
-					listvalidator.IsRequired(),
-				},
-				NestedObject: schema.NestedBlockObject{
+			"part": configPartBlock(partBlockDescription),
+		},
+		Attributes:          configAttributes(),
+		MarkdownDescription: configDescription,
+	}
+}
+
+func (d *configDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var cloudinitConfig configModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cloudinitConfig)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(cloudinitConfig.update(ctx)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, cloudinitConfig)...)
+}
+
+// configAttributes returns the attributes of the cloudinit_config data source,
+// which the ephemeral resource shares.
+func configAttributes() map[string]schema.Attribute {
+	return configRenderAttributes(map[string]schema.Attribute{
+		"gzip": schema.BoolAttribute{
+			Optional:            true,
+			Computed:            true,
+			MarkdownDescription: gzipDescription,
+		},
+		"base64_encode": schema.BoolAttribute{
+			Optional:            true,
+			Computed:            true,
+			MarkdownDescription: base64EncodeDescription,
+		},
+		"target_platform": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.OneOf(targetPlatforms()...),
+			},
+			Optional:            true,
+			MarkdownDescription: targetPlatformDescription,
+		},
+		"output_format": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.OneOf(outputFormats()...),
+			},
+			Optional:            true,
+			MarkdownDescription: outputFormatDescription,
+		},
+		"expand_archive_parts": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: expandArchivePartsDescription,
+		},
+		"sensitive_output": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: sensitiveOutputDescription,
+		},
+		"rendered": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: renderedDescription,
+		},
+		"rendered_sensitive": schema.StringAttribute{
+			Computed:            true,
+			Sensitive:           true,
+			MarkdownDescription: renderedSensitiveDescription,
+		},
+		"mime_size": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: mimeSizeDescription,
+		},
+		"compressed_size": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: compressedSizeDescription,
+		},
+		"rendered_size": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: renderedSizeDescription,
+		},
+		"rendered_sha256": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: renderedSHA256Description,
+		},
+		"rendered_sha512": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: renderedSHA512Description,
+		},
+		"rendered_md5": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: renderedMD5Description,
+		},
+		"mime_sha256": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: mimeSHA256Description,
+		},
+		"id": schema.StringAttribute{
+			Computed:            true,
+			MarkdownDescription: configIDDescription,
+		},
+	})
+}
+
+// configPartBlock returns the part block of the data sources rendering parts.
+func configPartBlock(description string) schema.ListNestedBlock {
+	oneOf := " " + exactlyOneOfDescription(partContentAttributes)
+
+	return schema.ListNestedBlock{
+		Validators: []validator.List{
+			listvalidator.IsRequired(),
+		},
+		NestedObject: schema.NestedBlockObject{
+			Blocks: map[string]schema.Block{
+				"include": schema.SingleNestedBlock{
 					Attributes: map[string]schema.Attribute{
-						"content_type": schema.StringAttribute{
-							Validators: []validator.String{
-								stringvalidator.LengthAtLeast(1),
-							},
+						"urls": schema.ListAttribute{
+							ElementType:         types.StringType,
 							Optional:            true,
-							Computed:            true,
-							MarkdownDescription: "A MIME-style content type to report in the header for the part. Defaults to `text/plain`",
+							MarkdownDescription: partIncludeURLsDescription,
 						},
-						"content": schema.StringAttribute{
-							Required:            true,
-							MarkdownDescription: "Body content for the part.",
-						},
-						"filename": schema.StringAttribute{
+						"once": schema.BoolAttribute{
 							Optional:            true,
-							MarkdownDescription: "A filename to report in the header for the part.",
-						},
-						"merge_type": schema.StringAttribute{
-							Optional: true,
-							MarkdownDescription: "A value for the `X-Merge-Type` header of the part, to control " +
-								"[cloud-init merging behavior](https://cloudinit.readthedocs.io/en/latest/reference/merging.html).",
+							MarkdownDescription: partIncludeOnceDescription,
 						},
 					},
+					MarkdownDescription: partIncludeDescription + oneOf,
 				},
-				MarkdownDescription: "A nested block type which adds a file to the generated cloud-init configuration. Use multiple " +
-					"`part` blocks to specify multiple files, which will be included in order of declaration in the final MIME document.",
 			},
-		},
-		Attributes: map[string]schema.Attribute{
-			"gzip": schema.BoolAttribute{
-				Optional:            true,
-				Computed:            true,
-				MarkdownDescription: "Specify whether or not to gzip the `rendered` output. Defaults to `true`.",
+			Attributes: map[string]schema.Attribute{
+				"content_type": schema.StringAttribute{
+					Validators: []validator.String{
+						stringvalidator.LengthAtLeast(1),
+					},
+					Optional:            true,
+					Computed:            true,
+					MarkdownDescription: partContentTypeDescription,
+				},
+				"content": schema.StringAttribute{
+					Validators: []validator.String{
+						exactlyOneOfValidator(partContentAttributes),
+					},
+					Optional:            true,
+					MarkdownDescription: partContentDescription + oneOf,
+				},
+				"content_base64": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: partContentBase64Description + oneOf,
+				},
+				"cloud_config": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: partCloudConfigDescription + oneOf,
+				},
+				"filename": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: partFilenameDescription,
+				},
+				"merge_type": schema.StringAttribute{
+					Optional:            true,
+					MarkdownDescription: partMergeTypeDescription,
+				},
+				"content_size": schema.Int64Attribute{
+					Computed:            true,
+					MarkdownDescription: partContentSizeDescription,
+				},
 			},
-			"base64_encode": schema.BoolAttribute{
-				Optional:            true,
-				Computed:            true,
-				MarkdownDescription: "Specify whether or not to base64 encode the `rendered` output. Defaults to `true`, and cannot be disabled if gzip is `true`.",
+		},
+		MarkdownDescription: description,
+	}
+}
+
+// configRenderAttributes returns the attributes configuring how the parts are
+// rendered, which the data sources rendering parts share, along with the given
+// attributes of the data source.
+func configRenderAttributes(attributes map[string]schema.Attribute) map[string]schema.Attribute {
+	common := map[string]schema.Attribute{
+		"boundary": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
 			},
-			"boundary": schema.StringAttribute{
-				Validators: []validator.String{
-					stringvalidator.LengthAtLeast(1),
-				},
-				Optional:            true,
-				Computed:            true,
-				MarkdownDescription: "Specify the Writer's default boundary separator. Defaults to `MIMEBOUNDARY`.",
+			Optional:            true,
+			Computed:            true,
+			MarkdownDescription: boundaryDescription,
+		},
+		"cloud_init_version": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.OneOf(cloudconfig.Versions()...),
 			},
-			"rendered": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "The final rendered multi-part cloud-init config.",
+			Optional:            true,
+			MarkdownDescription: cloudInitVersionDescription,
+		},
+		"cloud_config_validation": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.OneOf(cloudConfigValidations()...),
 			},
-			"id": schema.StringAttribute{
-				Computed:            true,
-				MarkdownDescription: "[CRC-32](https://pkg.go.dev/hash/crc32) checksum of `rendered` cloud-init config.",
+			Optional:            true,
+			MarkdownDescription: cloudConfigValidationDescription,
+		},
+		"custom_content_types": schema.ListAttribute{
+			ElementType: types.StringType,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
 			},
+			Optional:            true,
+			MarkdownDescription: customContentTypesDescription,
 		},
-		MarkdownDescription: "Renders a [multi-part MIME configuration](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#mime-multi-part-archive) " +
-			"for use with [cloud-init](https://cloudinit.readthedocs.io/en/latest/).\n\n" +
-			"Cloud-init is a commonly-used startup configuration utility for cloud compute instances. It accepts configuration via provider-specific " +
-			"user data mechanisms, such as `user_data` for Amazon EC2 instances. Multi-part MIME is one of the data formats it accepts. For more information, " +
-			"see [User-Data Formats](https://cloudinit.readthedocs.io/en/latest/explanation/format.html) in the cloud-init manual.\n\n" +
-			"This is not a generalized utility for producing multi-part MIME messages. Its feature set is specialized for cloud-init multi-part MIME messages.",
 	}
-}
 
-func (d *configDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
-	var cloudinitConfig configModel
-
-	resp.Diagnostics.Append(req.Config.Get(ctx, &cloudinitConfig)...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
+	maps.Copy(common, attributes)
 
-	resp.Diagnostics.Append(cloudinitConfig.update(ctx)...)
-	resp.Diagnostics.Append(resp.State.Set(ctx, cloudinitConfig)...)
+	return common
 }
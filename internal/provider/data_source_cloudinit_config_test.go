@@ -169,6 +169,233 @@ func TestConfigDataSourceRender(t *testing.T) {
 			}`,
 			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\nabc\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/plain\r\nMime-Version: 1.0\r\n\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n\r\n--MIMEBOUNDARY--\r\n",
 		},
+		{
+			"no gzip or b64 - valid cloud-config for cloud_init_version",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				cloud_init_version = "22.4"
+
+				part {
+					content_type = "text/cloud-config"
+					content = "#cloud-config\nubuntu_advantage:\n  token: abc\n"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\nubuntu_advantage:\n  token: abc\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - jinja cloud-config is not validated",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					content_type = "text/cloud-config"
+					content = "## template: jinja\n#cloud-config\nhostname: {{ v1.local_hostname }}\n"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n## template: jinja\n#cloud-config\nhostname: {{ v1.local_hostname }}\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - content type typed by its content",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					content_type = "text/x-not-multipart"
+					content = "#!/bin/sh\necho hello\n"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-not-multipart\r\nMime-Version: 1.0\r\n\r\n#!/bin/sh\necho hello\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - custom content type",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				custom_content_types = ["text/x-my-handler"]
+
+				part {
+					content_type = "text/x-my-handler"
+					content = "abc"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-my-handler\r\nMime-Version: 1.0\r\n\r\nabc\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - cloud_config",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					cloud_config = jsonencode({
+						packages    = ["git"]
+						write_files = [{ path = "/etc/motd", content = "hello\nworld\n" }]
+					})
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\npackages:\n  - git\nwrite_files:\n  - content: |\n      hello\n      world\n    path: /etc/motd\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - include",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					include {
+						urls = ["https://example.com/cloud-config.yaml", "https://example.com/setup.sh"]
+					}
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-include-url\r\nMime-Version: 1.0\r\n\r\n#include\nhttps://example.com/cloud-config.yaml\nhttps://example.com/setup.sh\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - include once over plaintext HTTP only warns",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					include {
+						urls = ["http://169.254.169.254/user-data"]
+						once = true
+					}
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-include-once-url\r\nMime-Version: 1.0\r\n\r\n#include-once\nhttp://169.254.169.254/user-data\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - invalid cloud-config value only warns",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					content_type = "text/cloud-config"
+					content = "#cloud-config\npackage_update: \"yes\"\n"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\npackage_update: \"yes\"\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - unknown cloud-config key only warns",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				cloud_init_version = "22.4"
+				cloud_config_validation = "error"
+
+				part {
+					content_type = "text/cloud-config"
+					content = "#cloud-config\nubuntu_pro:\n  token: abc\n"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\nubuntu_pro:\n  token: abc\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - base64 content wrapped at 76 columns",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					content_type = "text/plain"
+					content_base64 = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUmJygpKissLS4vMDEyMzQ1Njc4OTo7PD0+P0BBQkNERUZHSElKS0xNTk9QUVJTVFVWV1hZWltcXV5fYGFiYw=="
+					filename = "blob.bin"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Disposition: attachment; filename=\"blob.bin\"\r\nContent-Transfer-Encoding: base64\r\nContent-Type: text/plain\r\nMime-Version: 1.0\r\n\r\nAAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUmJygpKissLS4vMDEyMzQ1Njc4\r\nOTo7PD0+P0BBQkNERUZHSElKS0xNTk9QUVJTVFVWV1hZWltcXV5fYGFiYw==\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - auto boundary avoids the content",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				boundary = "auto"
+
+				part {
+					content = "--MIMEBOUNDARY\nfoo"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY-c68863baf11743dca9750cc02671f5b6\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY-c68863baf11743dca9750cc02671f5b6\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/plain\r\nMime-Version: 1.0\r\n\r\n--MIMEBOUNDARY\nfoo\r\n--MIMEBOUNDARY-c68863baf11743dca9750cc02671f5b6--\r\n",
+		},
+		{
+			"no gzip or b64 - raw output format",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				output_format = "raw"
+
+				part {
+					content = "#!/bin/sh\necho hello\n"
+				}
+			}`,
+			"#!/bin/sh\necho hello\n",
+		},
+		{
+			"no gzip or b64 - archive output format",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				output_format = "archive"
+
+				part {
+					cloud_config = jsonencode({ packages = ["git"] })
+				}
+
+				part {
+					content_type = "text/x-shellscript"
+					content      = "echo hello"
+					filename     = "hello.sh"
+				}
+			}`,
+			"#cloud-config-archive\n- type: text/cloud-config\n  content: |\n    #cloud-config\n    packages:\n      - git\n- type: text/x-shellscript\n  filename: hello.sh\n  content: echo hello\n",
+		},
+		{
+			"no gzip or b64 - archive part rendered as is",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					content = "#cloud-config-archive\n- type: text/x-shellscript\n  content: echo hello\n"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config-archive\r\nMime-Version: 1.0\r\n\r\n#cloud-config-archive\n- type: text/x-shellscript\n  content: echo hello\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - archive part expanded into MIME parts",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				expand_archive_parts = true
+
+				part {
+					content = "#cloud-config-archive\n- type: text/x-shellscript\n  launch-index: 0\n  content: echo hello\n- \"#cloud-config\\npackages: [git]\\n\"\n"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-shellscript\r\nLaunch-Index: 0\r\nMime-Version: 1.0\r\n\r\necho hello\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\npackages: [git]\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - archive part in archive output format",
+			`data "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+				output_format = "archive"
+
+				part {
+					content = "#cloud-config-archive\n- type: text/x-shellscript\n  launch-index: 0\n  content: echo hello\n"
+				}
+
+				part {
+					content = "#!/bin/sh\necho world\n"
+				}
+			}`,
+			"#cloud-config-archive\n- type: text/x-shellscript\n  launch-index: 0\n  content: echo hello\n- type: text/x-shellscript\n  content: |\n    #!/bin/sh\n    echo world\n",
+		},
 	}
 
 	for _, tt := range testCases {
@@ -214,6 +441,276 @@ func TestConfigDataSourceRender_handleErrors(t *testing.T) {
 			}`,
 			regexp.MustCompile("part must have a configuration value"),
 		},
+		{
+			"invalid cloud-config value",
+			`data "cloudinit_config" "foo" {
+				cloud_config_validation = "error"
+
+				part {
+					content_type = "text/cloud-config"
+					content      = "#cloud-config\npackage_update: \"yes\"\n"
+				}
+			}`,
+			regexp.MustCompile(`line 2, column 17: package_update: expected boolean, got string`),
+		},
+		{
+			"unsupported cloud_init_version",
+			`data "cloudinit_config" "foo" {
+				cloud_init_version = "1.0"
+
+				part {
+					content = "abc"
+				}
+			}`,
+			regexp.MustCompile(`Attribute cloud_init_version value must be one of`),
+		},
+		{
+			"rendered output exceeds the target_platform limit",
+			`data "cloudinit_config" "foo" {
+				gzip            = false
+				base64_encode   = false
+				target_platform = "aws"
+
+				part {
+					content = format("%020000d", 0)
+				}
+			}`,
+			regexp.MustCompile(`exceeds the 16384 byte user data limit of aws`),
+		},
+		{
+			"content and content_base64 are mutually exclusive",
+			`data "cloudinit_config" "foo" {
+				part {
+					content        = "abc"
+					content_base64 = "YWJj"
+				}
+			}`,
+			regexp.MustCompile(`2 attributes specified when one \(and only one\) of`),
+		},
+		{
+			"content or content_base64 is required",
+			`data "cloudinit_config" "foo" {
+				part {
+					filename = "abc"
+				}
+			}`,
+			regexp.MustCompile(`No attribute specified when one \(and only one\) of`),
+		},
+		{
+			"content_base64 must be base64 encoded",
+			`data "cloudinit_config" "foo" {
+				part {
+					content_base64 = "abc!"
+				}
+			}`,
+			regexp.MustCompile(`Expected content_base64 to be base64 encoded`),
+		},
+		{
+			"content collides with the boundary",
+			`data "cloudinit_config" "foo" {
+				part {
+					content = "--MIMEBOUNDARY\nfoo"
+				}
+			}`,
+			regexp.MustCompile(`MIME Boundary Collision`),
+		},
+		{
+			"cloud-config-archive entry collides with the boundary",
+			`data "cloudinit_config" "foo" {
+				expand_archive_parts = true
+
+				part {
+					content = "#cloud-config-archive\n- content: |\n    --MIMEBOUNDARY\n    foo\n"
+				}
+			}`,
+			regexp.MustCompile(`Entry 0 of the cloud-config-archive in part 0 has a line starting with "--MIMEBOUNDARY"`),
+		},
+		{
+			"misspelled content_type",
+			`data "cloudinit_config" "foo" {
+				part {
+					content_type = "text/x-shellscipt"
+					content      = "#!/bin/sh"
+				}
+			}`,
+			regexp.MustCompile(`did you mean "text/x-shellscript"\?`),
+		},
+		{
+			"unknown merge_type merger",
+			`data "cloudinit_config" "foo" {
+				part {
+					content    = "abc"
+					merge_type = "lists(append)+dict()"
+				}
+			}`,
+			regexp.MustCompile(`unknown merger "lists", did you mean "list"\?`),
+		},
+		{
+			"unknown merge_type option",
+			`data "cloudinit_config" "foo" {
+				part {
+					content    = "abc"
+					merge_type = "list(apend)+dict()"
+				}
+			}`,
+			regexp.MustCompile(`unknown option "apend" for the list merger`),
+		},
+		{
+			"malformed merge_type",
+			`data "cloudinit_config" "foo" {
+				part {
+					content    = "abc"
+					merge_type = "list+dict()"
+				}
+			}`,
+			regexp.MustCompile(`merger "list" is not in the format name\(option,...\)`),
+		},
+		{
+			"unknown inline merge_how option",
+			`data "cloudinit_config" "foo" {
+				part {
+					content_type = "text/cloud-config"
+					content      = "#cloud-config\nmerge_how: list(append)+dict(recurse_arrays)\n"
+				}
+			}`,
+			regexp.MustCompile(`unknown option "recurse_arrays" for the dict merger`),
+		},
+		{
+			"invalid cloud-config-archive content",
+			`data "cloudinit_config" "foo" {
+				expand_archive_parts = true
+
+				part {
+					content = "#cloud-config-archive\ntype: text/x-shellscript\n"
+				}
+			}`,
+			regexp.MustCompile(`The content of part 0 is not a valid cloud-config-archive document`),
+		},
+		{
+			"unknown merge type of a cloud-config-archive entry",
+			`data "cloudinit_config" "foo" {
+				expand_archive_parts = true
+
+				part {
+					content = "#cloud-config-archive\n- content: \"#cloud-config\\n\"\n  X-Merge-Type: list(append)+dict(recurse_arrays)\n"
+				}
+			}`,
+			regexp.MustCompile(`Entry 0 of the content of part 0 has an invalid X-Merge-Type`),
+		},
+		{
+			"cloud_config must be a JSON object",
+			`data "cloudinit_config" "foo" {
+				part {
+					cloud_config = jsonencode(["git"])
+				}
+			}`,
+			regexp.MustCompile(`Expected cloud_config to be a JSON object`),
+		},
+		{
+			"cloud_config requires the text/cloud-config content_type",
+			`data "cloudinit_config" "foo" {
+				part {
+					content_type = "text/x-shellscript"
+					cloud_config = jsonencode({ packages = ["git"] })
+				}
+			}`,
+			regexp.MustCompile(`Expected content_type to be "text/cloud-config" when cloud_config is set`),
+		},
+		{
+			"include URL without a scheme",
+			`data "cloudinit_config" "foo" {
+				part {
+					include {
+						urls = ["example.com/cloud-config.yaml"]
+					}
+				}
+			}`,
+			regexp.MustCompile(`URL 0 of the include of part 0 is not valid`),
+		},
+		{
+			"include URL with an unsupported scheme",
+			`data "cloudinit_config" "foo" {
+				part {
+					include {
+						urls = ["https://example.com/a.yaml", "ftp://example.com/b.yaml"]
+					}
+				}
+			}`,
+			regexp.MustCompile(`URL 1 of the include of part 0 is not valid`),
+		},
+		{
+			"include without urls",
+			`data "cloudinit_config" "foo" {
+				part {
+					include {
+						once = true
+					}
+				}
+			}`,
+			regexp.MustCompile(`Expected urls in the include of part 0 to have at least one URL`),
+		},
+		{
+			"include requires a matching content_type",
+			`data "cloudinit_config" "foo" {
+				part {
+					content_type = "text/x-include-url"
+
+					include {
+						urls = ["https://example.com/cloud-config.yaml"]
+						once = true
+					}
+				}
+			}`,
+			regexp.MustCompile(`Expected content_type to be "text/x-include-once-url" when include is set`),
+		},
+		{
+			"cloud_config is validated against the cloud-config schema",
+			`data "cloudinit_config" "foo" {
+				cloud_config_validation = "error"
+
+				part {
+					cloud_config = jsonencode({ package_update = "yes" })
+				}
+			}`,
+			regexp.MustCompile(`package_update: expected boolean, got string`),
+		},
+		{
+			"unknown content_type",
+			`data "cloudinit_config" "foo" {
+				part {
+					content_type = "application/x-my-handler"
+					content      = "abc"
+				}
+			}`,
+			regexp.MustCompile(`Unknown content type "application/x-my-handler"\.`),
+		},
+		{
+			"raw output format with more than one part",
+			`data "cloudinit_config" "foo" {
+				output_format = "raw"
+
+				part {
+					content = "#!/bin/sh\necho hello\n"
+				}
+
+				part {
+					content = "#!/bin/sh\necho world\n"
+				}
+			}`,
+			regexp.MustCompile(`Expected exactly one part when output_format is "raw"`),
+		},
+		{
+			"raw output format with a content type cloud-init cannot infer",
+			`data "cloudinit_config" "foo" {
+				output_format = "raw"
+
+				part {
+					content_type = "text/x-shellscript"
+					content      = "echo hello"
+				}
+			}`,
+			regexp.MustCompile(`gives "text/plain" rather than "text/x-shellscript"`),
+		},
 	}
 
 	for _, tt := range testCases {
@@ -231,6 +728,227 @@ func TestConfigDataSourceRender_handleErrors(t *testing.T) {
 	}
 }
 
+func TestConfigDataSourceRender_targetPlatform(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Block string
+	}{
+		{
+			"gzip compressed output is within the target_platform limit",
+			`data "cloudinit_config" "foo" {
+				target_platform = "aws"
+
+				part {
+					content = format("%020000d", 0)
+				}
+			}`,
+		},
+		{
+			"soft target_platform limit only warns",
+			`data "cloudinit_config" "foo" {
+				gzip            = false
+				base64_encode   = false
+				target_platform = "vmware"
+
+				part {
+					content = format("%070000d", 0)
+				}
+			}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.Block,
+						Check: r.ComposeTestCheckFunc(
+							r.TestCheckResourceAttrSet("data.cloudinit_config.foo", "rendered"),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestConfigDataSourceRender_digests(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `data "cloudinit_config" "gzip" {
+					part {
+						content = "#!/bin/sh\necho hello\n"
+					}
+				}
+
+				data "cloudinit_config" "base64" {
+					gzip = false
+
+					part {
+						content = "#!/bin/sh\necho hello\n"
+					}
+				}
+
+				data "cloudinit_config" "plain" {
+					gzip          = false
+					base64_encode = false
+
+					part {
+						content = "#!/bin/sh\necho hello\n"
+					}
+				}
+
+				output "rendered" {
+					value = alltrue([
+						for c in [data.cloudinit_config.gzip, data.cloudinit_config.base64, data.cloudinit_config.plain] :
+						c.rendered_sha256 == sha256(c.rendered) && c.rendered_sha512 == sha512(c.rendered) && c.rendered_md5 == md5(c.rendered)
+					])
+				}
+
+				output "mime" {
+					value = alltrue([
+						data.cloudinit_config.plain.mime_sha256 == sha256(data.cloudinit_config.plain.rendered),
+						data.cloudinit_config.base64.mime_sha256 == data.cloudinit_config.plain.mime_sha256,
+						data.cloudinit_config.gzip.mime_sha256 == data.cloudinit_config.plain.mime_sha256,
+					])
+				}`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckOutput("rendered", "true"),
+					r.TestCheckOutput("mime", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestConfigDataSourceRender_sizes(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `data "cloudinit_config" "gzip" {
+					part {
+						content = "#!/bin/sh\necho hello\n"
+					}
+				}
+
+				data "cloudinit_config" "plain" {
+					gzip          = false
+					base64_encode = false
+
+					part {
+						content = "#!/bin/sh\necho hello\n"
+					}
+
+					part {
+						content_base64 = "AAEC"
+					}
+				}
+
+				output "sizes" {
+					value = alltrue([
+						data.cloudinit_config.plain.mime_size == length(data.cloudinit_config.plain.rendered),
+						data.cloudinit_config.plain.rendered_size == data.cloudinit_config.plain.mime_size,
+						data.cloudinit_config.plain.compressed_size == null,
+						data.cloudinit_config.gzip.rendered_size == length(data.cloudinit_config.gzip.rendered),
+						data.cloudinit_config.gzip.rendered_size == ceil(data.cloudinit_config.gzip.compressed_size / 3) * 4,
+					])
+				}`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckOutput("sizes", "true"),
+					r.TestCheckResourceAttr("data.cloudinit_config.plain", "part.0.content_size", "21"),
+					r.TestCheckResourceAttr("data.cloudinit_config.plain", "part.1.content_size", "3"),
+				),
+			},
+		},
+	})
+}
+
+func TestConfigDataSourceRender_sensitiveOutput(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `data "cloudinit_config" "foo" {
+					gzip             = false
+					base64_encode    = false
+					sensitive_output = true
+
+					part {
+						content_type = "text/x-shellscript"
+						content      = "baz"
+					}
+				}`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckNoResourceAttr("data.cloudinit_config.foo", "rendered"),
+					r.TestCheckResourceAttr("data.cloudinit_config.foo", "rendered_sensitive", "Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-shellscript\r\nMime-Version: 1.0\r\n\r\nbaz\r\n--MIMEBOUNDARY--\r\n"),
+				),
+			},
+			{
+				Config: `data "cloudinit_config" "foo" {
+					sensitive_output = true
+
+					part {
+						content_type = "text/cloud-config"
+						content      = "#cloud-config\npackage_update: \"secret\"\n"
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`package_update: details are hidden`),
+			},
+		},
+	})
+}
+
+func TestConfigDataSourceRender_inferContentType(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `data "cloudinit_config" "foo" {
+					gzip          = false
+					base64_encode = false
+
+					part {
+						content = "#cloud-config\npackages:\n  - git\n"
+					}
+
+					part {
+						content = "#!/bin/sh\necho hello\n"
+					}
+
+					part {
+						content = "#include-once\nhttps://example.com/cloud-config.yaml\n"
+					}
+
+					part {
+						content = "## template: jinja\n#cloud-config\nhostname: {{ v1.local_hostname }}\n"
+					}
+
+					part {
+						content_base64 = base64encode("#cloud-boothook\necho hello\n")
+					}
+
+					part {
+						content = "hello"
+					}
+				}`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("data.cloudinit_config.foo", "part.0.content_type", "text/cloud-config"),
+					r.TestCheckResourceAttr("data.cloudinit_config.foo", "part.1.content_type", "text/x-shellscript"),
+					r.TestCheckResourceAttr("data.cloudinit_config.foo", "part.2.content_type", "text/x-include-once-url"),
+					r.TestCheckResourceAttr("data.cloudinit_config.foo", "part.3.content_type", "text/jinja2"),
+					r.TestCheckResourceAttr("data.cloudinit_config.foo", "part.4.content_type", "text/cloud-boothook"),
+					r.TestCheckResourceAttr("data.cloudinit_config.foo", "part.5.content_type", "text/plain"),
+				),
+			},
+		},
+	})
+}
+
 func TestConfigDataSource_UpgradeFromVersion2_2_0(t *testing.T) {
 	testCases := []struct {
 		Name            string
@@ -0,0 +1,224 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
+)
+
+var (
+	_ datasource.DataSourceWithValidateConfig = (*jinjaPreviewDataSource)(nil)
+)
+
+type jinjaPreviewDataSource struct{}
+
+type jinjaPreviewModel struct {
+	ID           types.String `tfsdk:"id"`
+	InstanceData types.String `tfsdk:"instance_data"`
+	Parts        types.List   `tfsdk:"part"` // jinjaPreviewPartModel
+}
+
+type jinjaPreviewPartModel struct {
+	Content            types.String `tfsdk:"content"`
+	Rendered           types.String `tfsdk:"rendered"`
+	UndefinedVariables types.List   `tfsdk:"undefined_variables"` // types.String
+}
+
+func (d *jinjaPreviewDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_jinja_preview"
+}
+
+func (d *jinjaPreviewDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var preview jinjaPreviewModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &preview)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Undefined variables are only reported on read, so that the warnings are
+	// not repeated.
+	_, diags := preview.render(ctx)
+	for _, d := range diags {
+		if d.Severity() == diag.SeverityError {
+			resp.Diagnostics.Append(d)
+		}
+	}
+}
+
+func (d *jinjaPreviewDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Blocks: map[string]schema.Block{
+			"part": schema.ListNestedBlock{
+				Validators: []validator.List{
+					listvalidator.IsRequired(),
+				},
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"content": schema.StringAttribute{
+							Required: true,
+							MarkdownDescription: "Body content for the part, such as the `content` of a `part` of the `cloudinit_config` data " +
+								"source. Content whose first line is `## template: jinja` is rendered as a Jinja template.",
+						},
+						"rendered": schema.StringAttribute{
+							Computed: true,
+							MarkdownDescription: "The content of the part after cloud-init renders it, without the `## template: jinja` " +
+								"line. Undefined variables are written as `CI_MISSING_JINJA_VAR/` followed by their name, like cloud-init " +
+								"does. Content which is not a Jinja template is left as is.",
+						},
+						"undefined_variables": schema.ListAttribute{
+							ElementType: types.StringType,
+							Computed:    true,
+							MarkdownDescription: "The names of the variables the template of the part uses which are not in " +
+								"`instance_data`, in order of first use. A warning is also reported when there are any.",
+						},
+					},
+				},
+				MarkdownDescription: "A nested block type which adds a part to preview. Use multiple `part` blocks to preview " +
+					"multiple parts, which are rendered independently.",
+			},
+		},
+		Attributes: map[string]schema.Attribute{
+			"instance_data": schema.StringAttribute{
+				Required: true,
+				MarkdownDescription: "Mock [instance data](https://cloudinit.readthedocs.io/en/latest/explanation/instancedata.html) " +
+					"as a JSON object, such as the result of `jsonencode`, in the format of `/run/cloud-init/instance-data.json`: " +
+					"standardized keys under `v1`, such as `v1.local_hostname` and `v1.region`, and datasource specific keys under " +
+					"`ds`, such as `ds.meta_data`. Like cloud-init, the keys under `v1` are also variables themselves, and keys with " +
+					"dashes or dots also have an alias with underscores, such as `ds.meta_data.local_hostname` for `local-hostname`.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 digest of the rendered parts.",
+			},
+		},
+		MarkdownDescription: "Renders [Jinja templates](https://cloudinit.readthedocs.io/en/latest/explanation/instancedata.html#using-instance-data) " +
+			"of user data parts against mock instance data, to preview what cloud-init renders on boot without launching an " +
+			"instance. Parts whose first line is `## template: jinja` are rendered, and the variables they use which are not " +
+			"in the instance data are reported.\n\n" +
+			"The templates are rendered by the provider rather than by Python, with the features of Jinja which cloud-init " +
+			"templates commonly use: the `if`, `for`, `set` and `raw` tags; the `abs`, `capitalize`, `count`, `d`, `default`, " +
+			"`first`, `float`, `indent`, `int`, `items`, `join`, `last`, `length`, `list`, `lower`, `replace`, `sort`, `string`, " +
+			"`title`, `tojson`, `trim`, `unique` and `upper` filters, without the `attribute` argument of `join`, `sort` and " +
+			"`unique` or the `indent` argument of `tojson`; the `boolean`, `defined`, `divisibleby`, `even`, `false`, `float`, " +
+			"`integer`, `iterable`, `lower`, `mapping`, `none`, `number`, `odd`, `sequence`, `string`, `true`, `undefined` and " +
+			"`upper` tests; the `endswith`, `lower`, `lstrip`, `replace`, `rstrip`, `split`, `startswith`, `strip` and `upper` " +
+			"string methods; the `get`, `items`, `keys` and `values` mapping methods; and the `range` function. Templates using " +
+			"other features are reported as invalid rather than rendered differently from cloud-init. Mappings are iterated in " +
+			"the order of their keys, which is the order `jsonencode` writes them in.",
+	}
+}
+
+func (d *jinjaPreviewDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var preview jinjaPreviewModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &preview)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(preview.update(ctx)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, preview)...)
+}
+
+// render renders the parts against instance_data, returning nil when either is
+// not known yet.
+func (m jinjaPreviewModel) render(ctx context.Context) ([]jinjaPreviewPartModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if m.InstanceData.IsUnknown() || m.Parts.IsNull() || m.Parts.IsUnknown() {
+		return nil, diags
+	}
+
+	variables, err := cloudconfig.JinjaVariables([]byte(m.InstanceData.ValueString()))
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("instance_data"),
+			"Invalid Attribute Value",
+			endSentence(fmt.Sprintf("Expected instance_data to be a JSON object, such as the result of jsonencode: %s", err)),
+		)
+		return nil, diags
+	}
+
+	var parts []jinjaPreviewPartModel
+	diags.Append(m.Parts.ElementsAs(ctx, &parts, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	for i, part := range parts {
+		if part.Content.IsUnknown() {
+			return nil, diags
+		}
+
+		content := part.Content.ValueString()
+		contentPath := path.Root("part").AtListIndex(i).AtName("content")
+
+		var undefined []string
+		if cloudconfig.IsJinjaTemplate(content) {
+			content, undefined, err = cloudconfig.RenderJinjaTemplate(content, variables)
+			if err != nil {
+				diags.AddAttributeError(
+					contentPath,
+					"Invalid Jinja Template",
+					endSentence(fmt.Sprintf("The content of part %d cannot be rendered: %s", i, err)),
+				)
+				continue
+			}
+		}
+
+		if len(undefined) > 0 {
+			diags.AddAttributeWarning(
+				contentPath,
+				"Undefined Jinja Variables",
+				fmt.Sprintf("The template of part %d uses variables which are not in instance_data: %s. cloud-init renders "+
+					"them as %s followed by their name.", i, strings.Join(undefined, ", "), cloudconfig.MissingJinjaVariablePrefix),
+			)
+		}
+
+		undefinedList, convertDiags := types.ListValueFrom(ctx, types.StringType, append([]string{}, undefined...))
+		diags.Append(convertDiags...)
+
+		parts[i].Rendered = types.StringValue(content)
+		parts[i].UndefinedVariables = undefinedList
+	}
+
+	return parts, diags
+}
+
+func (m *jinjaPreviewModel) update(ctx context.Context) diag.Diagnostics {
+	parts, diags := m.render(ctx)
+	if diags.HasError() || parts == nil {
+		return diags
+	}
+
+	partsList, convertDiags := types.ListValueFrom(ctx, m.Parts.ElementType(ctx), parts)
+	diags.Append(convertDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	rendered := make([]string, len(parts))
+	for i, part := range parts {
+		rendered[i] = part.Rendered.ValueString()
+	}
+
+	m.Parts = partsList
+	m.ID = types.StringValue(hashcode.SHA256(strings.Join(rendered, "\n")))
+
+	return diags
+}
@@ -0,0 +1,144 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestJinjaPreviewDataSourceRender(t *testing.T) {
+	testCases := []struct {
+		Name              string
+		DataSourceBlock   string
+		Expected          string
+		ExpectedUndefined []string
+	}{
+		{
+			"v1 and ds variables",
+			`data "cloudinit_jinja_preview" "foo" {
+				instance_data = jsonencode({
+					v1 = { local_hostname = "web-01" }
+					ds = { meta_data = { "instance-id" = "iid-local01" } }
+				})
+
+				part {
+					content = "## template: jinja\n#cloud-config\nhostname: {{ v1.local_hostname }}\nfqdn: {{ local_hostname }}.example.com\ninstance: {{ ds.meta_data.instance_id }}\n"
+				}
+			}`,
+			"#cloud-config\nhostname: web-01\nfqdn: web-01.example.com\ninstance: iid-local01\n",
+			nil,
+		},
+		{
+			"undefined variables",
+			`data "cloudinit_jinja_preview" "foo" {
+				instance_data = jsonencode({
+					v1 = { local_hostname = "web-01" }
+				})
+
+				part {
+					content = "## template: jinja\n#!/bin/sh\n{% if v1.region %}\necho {{ v1.region }}\n{% endif %}\nhostnamectl set-hostname {{ v1.local_hostname }}.{{ v1.domain }}\n"
+				}
+			}`,
+			"#!/bin/sh\nhostnamectl set-hostname web-01.CI_MISSING_JINJA_VAR/domain\n",
+			[]string{"domain"},
+		},
+		{
+			"not a template",
+			`data "cloudinit_jinja_preview" "foo" {
+				instance_data = jsonencode({})
+
+				part {
+					content = "#cloud-config\nhostname: {{ v1.local_hostname }}\n"
+				}
+			}`,
+			"#cloud-config\nhostname: {{ v1.local_hostname }}\n",
+			nil,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			checks := []r.TestCheckFunc{
+				r.TestCheckResourceAttr("data.cloudinit_jinja_preview.foo", "part.0.rendered", tt.Expected),
+				r.TestCheckResourceAttr("data.cloudinit_jinja_preview.foo", "part.0.undefined_variables.#", strconv.Itoa(len(tt.ExpectedUndefined))),
+			}
+
+			for i, name := range tt.ExpectedUndefined {
+				checks = append(checks, r.TestCheckResourceAttr("data.cloudinit_jinja_preview.foo", "part.0.undefined_variables."+strconv.Itoa(i), name))
+			}
+
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.DataSourceBlock,
+						Check:  r.ComposeTestCheckFunc(checks...),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestJinjaPreviewDataSourceRender_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		DataSourceBlock string
+		ErrorMatch      *regexp.Regexp
+	}{
+		{
+			"instance_data not a JSON object",
+			`data "cloudinit_jinja_preview" "foo" {
+				instance_data = jsonencode(["v1"])
+
+				part {
+					content = "## template: jinja\n#cloud-config\n"
+				}
+			}`,
+			regexp.MustCompile(`Expected instance_data to be a JSON object`),
+		},
+		{
+			"unclosed block",
+			`data "cloudinit_jinja_preview" "foo" {
+				instance_data = jsonencode({})
+
+				part {
+					content = "## template: jinja\n#cloud-config\n{% if v1.region %}\nregion: {{ v1.region }}\n"
+				}
+			}`,
+			regexp.MustCompile(`The content of part 0 cannot be rendered: line 4: unexpected end of template`),
+		},
+		{
+			"key with a dash",
+			`data "cloudinit_jinja_preview" "foo" {
+				instance_data = jsonencode({
+					ds = { meta_data = { "local-hostname" = "web-01" } }
+				})
+
+				part {
+					content = "## template: jinja\n#cloud-config\nhostname: {{ ds.meta_data.local-hostname }}\n"
+				}
+			}`,
+			regexp.MustCompile(`'local' is undefined in local-hostname, which Jinja reads as a subtraction`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.DataSourceBlock,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
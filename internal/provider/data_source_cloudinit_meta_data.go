@@ -0,0 +1,175 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
+)
+
+var (
+	_ datasource.DataSourceWithValidateConfig = (*metaDataDataSource)(nil)
+)
+
+type metaDataDataSource struct{}
+
+type metaDataModel struct {
+	ID            types.String `tfsdk:"id"`
+	InstanceID    types.String `tfsdk:"instance_id"`
+	UserData      types.String `tfsdk:"user_data"`
+	LocalHostname types.String `tfsdk:"local_hostname"`
+	Extra         types.String `tfsdk:"extra"`
+	Rendered      types.String `tfsdk:"rendered"`
+}
+
+func (d *metaDataDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_meta_data"
+}
+
+func (d *metaDataDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var metaData metaDataModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &metaData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(metaData.validate()...)
+}
+
+func (d *metaDataDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"instance_id": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "The `instance-id` of the instance. cloud-init runs per-instance modules, such as those " +
+					"creating users and running `runcmd`, again when it changes. When omitted, it is derived from `user_data`. " +
+					"Exactly one of `instance_id` or `user_data` must be set.",
+			},
+			"user_data": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("instance_id")),
+				},
+				Optional: true,
+				MarkdownDescription: "The user data of the instance, such as the `rendered` output of the `cloudinit_config` " +
+					"data source, to derive `instance_id` from. `instance_id` is then `iid-` followed by the first 16 hex digits " +
+					"of the SHA-256 digest of `user_data`, so that cloud-init treats the instance as new on the next boot after " +
+					"the user data changes, and only then. Exactly one of `instance_id` or `user_data` must be set.",
+			},
+			"local_hostname": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "The `local-hostname` of the instance, such as `web-01` or `web-01.example.com`.",
+			},
+			"extra": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "Additional keys of the document as a JSON object, such as the result of `jsonencode`, " +
+					"for keys like `public-keys` or keys which Jinja templates read as `ds.meta_data`. Must not set " +
+					"`instance-id` or `local-hostname`.",
+			},
+			"rendered": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "The meta-data document as YAML, for the `meta-data` file of a NoCloud seed, such as the " +
+					"`meta_data` of `cloudinit_nocloud_seed`.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The same as `instance_id`.",
+			},
+		},
+		MarkdownDescription: "Renders the [meta-data](https://cloudinit.readthedocs.io/en/latest/reference/datasources/nocloud.html) " +
+			"document of an instance for NoCloud seeds, with its `instance-id`, `local-hostname` and any extra keys.\n\n" +
+			"cloud-init only runs per-instance modules on the first boot of an instance, which it recognizes by a new " +
+			"`instance-id`. Deriving `instance_id` from `user_data` makes a change of the user data run them again on the next " +
+			"boot, such as after the seed image of a virtual machine is replaced.",
+	}
+}
+
+func (d *metaDataDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var metaData metaDataModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &metaData)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(metaData.update()...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, metaData)...)
+}
+
+// validate checks local_hostname and that extra is a JSON object which does not
+// set the keys of the other attributes.
+func (m metaDataModel) validate() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !m.LocalHostname.IsNull() && !m.LocalHostname.IsUnknown() {
+		if err := cloudconfig.ValidateHostname(m.LocalHostname.ValueString()); err != nil {
+			diags.AddAttributeError(
+				path.Root("local_hostname"),
+				"Invalid Attribute Value",
+				endSentence(fmt.Sprintf("Invalid hostname %q: %s", m.LocalHostname.ValueString(), err)),
+			)
+		}
+	}
+
+	if !m.Extra.IsNull() && !m.Extra.IsUnknown() {
+		if _, err := cloudconfig.MarshalMetaData(cloudconfig.MetaData{Extra: []byte(m.Extra.ValueString())}); err != nil {
+			diags.AddAttributeError(
+				path.Root("extra"),
+				"Invalid Attribute Value",
+				endSentence(fmt.Sprintf("Expected extra to be a JSON object, such as the result of jsonencode, without the keys of instance_id and local_hostname: %s", err)),
+			)
+		}
+	}
+
+	return diags
+}
+
+func (m *metaDataModel) update() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if m.InstanceID.IsNull() {
+		m.InstanceID = types.StringValue(instanceIDFromUserData(m.UserData.ValueString()))
+	}
+
+	metaData := cloudconfig.MetaData{
+		InstanceID:    m.InstanceID.ValueString(),
+		LocalHostname: m.LocalHostname.ValueString(),
+	}
+
+	if !m.Extra.IsNull() {
+		metaData.Extra = []byte(m.Extra.ValueString())
+	}
+
+	rendered, err := cloudconfig.MarshalMetaData(metaData)
+	if err != nil {
+		diags.AddError("Unable to render meta-data", err.Error())
+		return diags
+	}
+
+	m.Rendered = types.StringValue(string(rendered))
+	m.ID = m.InstanceID
+
+	return diags
+}
+
+// instanceIDFromUserData returns an instance-id which only changes when the
+// user data does.
+func instanceIDFromUserData(userData string) string {
+	return "iid-" + hashcode.SHA256(userData)[:16]
+}
@@ -0,0 +1,115 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestMetaDataDataSourceRender(t *testing.T) {
+	testCases := []struct {
+		Name               string
+		DataSourceBlock    string
+		ExpectedInstanceID string
+		Expected           string
+	}{
+		{
+			"instance_id",
+			`data "cloudinit_meta_data" "foo" {
+				instance_id    = "iid-local01"
+				local_hostname = "web-01"
+			}`,
+			"iid-local01",
+			"instance-id: iid-local01\nlocal-hostname: web-01\n",
+		},
+		{
+			"instance_id derived from user_data",
+			`data "cloudinit_meta_data" "foo" {
+				user_data = "#cloud-config\n"
+			}`,
+			"iid-88c95955b024402a",
+			"instance-id: iid-88c95955b024402a\n",
+		},
+		{
+			"extra keys",
+			`data "cloudinit_meta_data" "foo" {
+				instance_id = "iid-local01"
+				extra       = jsonencode({
+					"public-keys" = ["ssh-ed25519 AAAA"]
+				})
+			}`,
+			"iid-local01",
+			"instance-id: iid-local01\npublic-keys:\n  - ssh-ed25519 AAAA\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.DataSourceBlock,
+						Check: r.ComposeTestCheckFunc(
+							r.TestCheckResourceAttr("data.cloudinit_meta_data.foo", "instance_id", tt.ExpectedInstanceID),
+							r.TestCheckResourceAttr("data.cloudinit_meta_data.foo", "rendered", tt.Expected),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestMetaDataDataSourceRender_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		DataSourceBlock string
+		ErrorMatch      *regexp.Regexp
+	}{
+		{
+			"instance_id and user_data",
+			`data "cloudinit_meta_data" "foo" {
+				instance_id = "iid-local01"
+				user_data   = "#cloud-config\n"
+			}`,
+			regexp.MustCompile(`2 attributes specified when one \(and only one\)`),
+		},
+		{
+			"invalid hostname",
+			`data "cloudinit_meta_data" "foo" {
+				instance_id    = "iid-local01"
+				local_hostname = "web_01"
+			}`,
+			regexp.MustCompile(`Invalid hostname "web_01"`),
+		},
+		{
+			"extra keys with instance-id",
+			`data "cloudinit_meta_data" "foo" {
+				instance_id = "iid-local01"
+				extra       = jsonencode({
+					"instance-id" = "iid-local02"
+				})
+			}`,
+			regexp.MustCompile(`unexpected key "instance-id"`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.DataSourceBlock,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
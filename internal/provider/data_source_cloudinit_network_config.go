@@ -0,0 +1,235 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"maps"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/networkconfig"
+)
+
+var (
+	_ datasource.DataSourceWithValidateConfig = (*networkConfigDataSource)(nil)
+)
+
+type networkConfigDataSource struct{}
+
+func (d *networkConfigDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_network_config"
+}
+
+func (d *networkConfigDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var networkConfig networkConfigModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &networkConfig)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(networkConfig.validate(ctx)...)
+}
+
+// networkInterfaceBlock returns the block of a kind of interface, with the
+// attributes every kind has and the given attributes only that kind has.
+func networkInterfaceBlock(attributes map[string]schema.Attribute, description string) schema.ListNestedBlock {
+	common := map[string]schema.Attribute{
+		"name": schema.StringAttribute{
+			Required:            true,
+			MarkdownDescription: "The name of the interface, such as `eth0`. Bonds, bridges and VLANs refer to interfaces by name.",
+		},
+		"addresses": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Static addresses of the interface with their prefix length, such as `192.0.2.10/24` or `2001:db8::10/64`.",
+		},
+		"dhcp4": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Set to `true` to configure the interface with DHCP for IPv4. Defaults to `false`.",
+		},
+		"dhcp6": schema.BoolAttribute{
+			Optional:            true,
+			MarkdownDescription: "Set to `true` to configure the interface with DHCP for IPv6. Defaults to `false`.",
+		},
+		"mtu": schema.Int64Attribute{
+			Validators: []validator.Int64{
+				int64validator.AtLeast(68),
+			},
+			Optional:            true,
+			MarkdownDescription: "The MTU of the interface in bytes, such as `9000` for jumbo frames.",
+		},
+		"nameservers": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "Addresses of the DNS servers of the interface, such as `192.0.2.53`.",
+		},
+		"search_domains": schema.ListAttribute{
+			ElementType:         types.StringType,
+			Optional:            true,
+			MarkdownDescription: "DNS search domains of the interface, such as `example.com`.",
+		},
+	}
+
+	maps.Copy(common, attributes)
+
+	return schema.ListNestedBlock{
+		NestedObject: schema.NestedBlockObject{
+			Attributes: common,
+			Blocks: map[string]schema.Block{
+				"route": schema.ListNestedBlock{
+					NestedObject: schema.NestedBlockObject{
+						Attributes: map[string]schema.Attribute{
+							"to": schema.StringAttribute{
+								Required: true,
+								MarkdownDescription: "The destination network of the route, such as `10.0.0.0/8`, or `default` for the default " +
+									"route of the address family of `via`.",
+							},
+							"via": schema.StringAttribute{
+								Required:            true,
+								MarkdownDescription: "The address of the gateway, such as `192.0.2.1`.",
+							},
+							"metric": schema.Int64Attribute{
+								Validators: []validator.Int64{
+									int64validator.AtLeast(0),
+								},
+								Optional:            true,
+								MarkdownDescription: "The metric of the route. Routes with lower metrics are preferred.",
+							},
+						},
+					},
+					MarkdownDescription: "A static route of the interface. Use multiple `route` blocks to specify multiple routes.",
+				},
+			},
+		},
+		MarkdownDescription: description,
+	}
+}
+
+func (d *networkConfigDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Blocks: map[string]schema.Block{
+			"ethernet": networkInterfaceBlock(
+				map[string]schema.Attribute{
+					"mac_address": schema.StringAttribute{
+						Optional: true,
+						MarkdownDescription: "The MAC address to match the interface by, such as `52:54:00:12:34:56`, which is then " +
+							"renamed to `name`. When omitted, the interface is matched by `name`.",
+					},
+				},
+				"A nested block type which adds a physical interface. Use multiple `ethernet` blocks to specify multiple interfaces.",
+			),
+			"bond": networkInterfaceBlock(
+				map[string]schema.Attribute{
+					"interfaces": schema.ListAttribute{
+						ElementType: types.StringType,
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+						Required:            true,
+						MarkdownDescription: "The names of the `ethernet` interfaces in the bond.",
+					},
+					"mode": schema.StringAttribute{
+						Validators: []validator.String{
+							stringvalidator.OneOf(networkconfig.BondModes...),
+						},
+						Optional: true,
+						MarkdownDescription: "The bonding mode, one of `balance-rr`, `active-backup`, `balance-xor`, `broadcast`, `802.3ad`, " +
+							"`balance-tlb` or `balance-alb`. Defaults to `balance-rr`, like the Linux bonding driver.",
+					},
+					"mii_monitor_interval": schema.Int64Attribute{
+						Validators: []validator.Int64{
+							int64validator.AtLeast(0),
+						},
+						Optional:            true,
+						MarkdownDescription: "The interval in milliseconds at which the links of the interfaces are checked.",
+					},
+					"primary": schema.StringAttribute{
+						Optional: true,
+						MarkdownDescription: "The interface of the bond which is preferred as the active interface in the `active-backup`, " +
+							"`balance-tlb` and `balance-alb` modes.",
+					},
+				},
+				"A nested block type which adds a bond of `ethernet` interfaces. Use multiple `bond` blocks to specify multiple bonds.",
+			),
+			"bridge": networkInterfaceBlock(
+				map[string]schema.Attribute{
+					"interfaces": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: "The names of the `ethernet`, `bond` or `vlan` interfaces in the bridge.",
+					},
+					"stp": schema.BoolAttribute{
+						Optional: true,
+						MarkdownDescription: "Set to `true` to enable the Spanning Tree Protocol on the bridge, or `false` to disable it. " +
+							"When omitted, the default of the network renderer of the image is used.",
+					},
+				},
+				"A nested block type which adds a bridge. Use multiple `bridge` blocks to specify multiple bridges.",
+			),
+			"vlan": networkInterfaceBlock(
+				map[string]schema.Attribute{
+					"id": schema.Int64Attribute{
+						Validators: []validator.Int64{
+							int64validator.Between(1, 4094),
+						},
+						Required:            true,
+						MarkdownDescription: "The VLAN ID, from 1 to 4094.",
+					},
+					"link": schema.StringAttribute{
+						Required:            true,
+						MarkdownDescription: "The name of the `ethernet` or `bond` interface the VLAN is on.",
+					},
+				},
+				"A nested block type which adds a VLAN. Use multiple `vlan` blocks to specify multiple VLANs.",
+			),
+		},
+		Attributes: map[string]schema.Attribute{
+			"version": schema.Int64Attribute{
+				Validators: []validator.Int64{
+					int64validator.OneOf(networkconfig.Version1, networkconfig.Version2),
+				},
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "The format of `rendered`, `2` for the netplan style version 2 format, or `1` for the version 1 " +
+					"format, for images with a cloud-init release or network renderer which does not read version 2. Defaults to `2`.",
+			},
+			"rendered": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "The network configuration as YAML, for the `network-config` file of a NoCloud seed, such as " +
+					"the `network_config` of `cloudinit_nocloud_seed`.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 digest of `rendered`.",
+			},
+		},
+		MarkdownDescription: "Renders a cloud-init [network configuration](https://cloudinit.readthedocs.io/en/latest/reference/network-config.html) " +
+			"in the [version 2](https://cloudinit.readthedocs.io/en/latest/reference/network-config-format-v2.html) or " +
+			"[version 1](https://cloudinit.readthedocs.io/en/latest/reference/network-config-format-v1.html) format from " +
+			"`ethernet`, `bond`, `bridge` and `vlan` blocks.\n\n" +
+			"The addresses, MAC addresses and routes of the interfaces are validated, as are the names bonds, bridges and VLANs " +
+			"refer to, which cloud-init and netplan otherwise only report on boot. The output has no top-level `network` key, " +
+			"as the `network-config` file of a NoCloud seed holds it.",
+	}
+}
+
+func (d *networkConfigDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var networkConfig networkConfigModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &networkConfig)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(networkConfig.update(ctx)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, networkConfig)...)
+}
@@ -0,0 +1,232 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestNetworkConfigDataSourceRender(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		DataSourceBlock string
+		Expected        string
+	}{
+		{
+			"ethernet with DHCP",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name        = "eth0"
+					mac_address = "52:54:00:12:34:56"
+					dhcp4       = true
+				}
+			}`,
+			"version: 2\nethernets:\n  eth0:\n    match:\n      macaddress: \"52:54:00:12:34:56\"\n    set-name: eth0\n    dhcp4: true\n",
+		},
+		{
+			"static addresses and routes",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name        = "eth0"
+					addresses   = ["192.0.2.10/24"]
+					nameservers = ["192.0.2.53"]
+
+					route {
+						to     = "default"
+						via    = "192.0.2.1"
+						metric = 100
+					}
+				}
+			}`,
+			"version: 2\nethernets:\n  eth0:\n    addresses:\n      - 192.0.2.10/24\n    nameservers:\n      addresses:\n        - 192.0.2.53\n    routes:\n      - to: 0.0.0.0/0\n        via: 192.0.2.1\n        metric: 100\n",
+		},
+		{
+			"static addresses and routes - version 1",
+			`data "cloudinit_network_config" "foo" {
+				version = 1
+
+				ethernet {
+					name        = "eth0"
+					addresses   = ["192.0.2.10/24"]
+					nameservers = ["192.0.2.53"]
+
+					route {
+						to  = "default"
+						via = "192.0.2.1"
+					}
+				}
+			}`,
+			"version: 1\nconfig:\n  - type: physical\n    name: eth0\n    subnets:\n      - type: static\n        address: 192.0.2.10/24\n        dns_nameservers:\n          - 192.0.2.53\n        routes:\n          - network: 0.0.0.0\n            netmask: 0.0.0.0\n            gateway: 192.0.2.1\n",
+		},
+		{
+			"bond with a vlan in a bridge",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name = "eth0"
+				}
+
+				ethernet {
+					name = "eth1"
+				}
+
+				bond {
+					name       = "bond0"
+					interfaces = ["eth0", "eth1"]
+					mode       = "active-backup"
+					primary    = "eth0"
+				}
+
+				vlan {
+					name = "vlan10"
+					id   = 10
+					link = "bond0"
+				}
+
+				bridge {
+					name       = "br0"
+					interfaces = ["vlan10"]
+					dhcp4      = true
+				}
+			}`,
+			"version: 2\nethernets:\n  eth0: {}\n  eth1: {}\nbonds:\n  bond0:\n    interfaces:\n      - eth0\n      - eth1\n    parameters:\n      mode: active-backup\n      primary: eth0\nbridges:\n  br0:\n    interfaces:\n      - vlan10\n    dhcp4: true\nvlans:\n  vlan10:\n    id: 10\n    link: bond0\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.DataSourceBlock,
+						Check: r.ComposeTestCheckFunc(
+							r.TestCheckResourceAttr("data.cloudinit_network_config.foo", "rendered", tt.Expected),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestNetworkConfigDataSourceRender_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		DataSourceBlock string
+		ErrorMatch      *regexp.Regexp
+	}{
+		{
+			"invalid MAC address",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name        = "eth0"
+					mac_address = "52-54-00-12-34-56"
+				}
+			}`,
+			regexp.MustCompile(`Invalid MAC address "52-54-00-12-34-56"`),
+		},
+		{
+			"address without a prefix length",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name      = "eth0"
+					addresses = ["192.0.2.10"]
+				}
+			}`,
+			regexp.MustCompile(`Invalid address "192.0.2.10"`),
+		},
+		{
+			"route destination with host bits set",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name = "eth0"
+
+					route {
+						to  = "10.0.0.1/8"
+						via = "192.0.2.1"
+					}
+				}
+			}`,
+			regexp.MustCompile(`without host bits set`),
+		},
+		{
+			"duplicate interface name",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name = "eth0"
+				}
+
+				bridge {
+					name = "eth0"
+				}
+			}`,
+			regexp.MustCompile(`Duplicate interface name "eth0"`),
+		},
+		{
+			"bond of an unknown interface",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name = "eth0"
+				}
+
+				bond {
+					name       = "bond0"
+					interfaces = ["eth1"]
+				}
+			}`,
+			regexp.MustCompile(`got "eth1", which is not the name of any interface`),
+		},
+		{
+			"vlan on a bridge",
+			`data "cloudinit_network_config" "foo" {
+				bridge {
+					name = "br0"
+				}
+
+				vlan {
+					name = "vlan10"
+					id   = 10
+					link = "br0"
+				}
+			}`,
+			regexp.MustCompile(`got "br0", which is a bridge`),
+		},
+		{
+			"interface in two bonds",
+			`data "cloudinit_network_config" "foo" {
+				ethernet {
+					name = "eth0"
+				}
+
+				bond {
+					name       = "bond0"
+					interfaces = ["eth0"]
+				}
+
+				bond {
+					name       = "bond1"
+					interfaces = ["eth0"]
+				}
+			}`,
+			regexp.MustCompile(`Interface "eth0" is already in "bond0"`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.DataSourceBlock,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
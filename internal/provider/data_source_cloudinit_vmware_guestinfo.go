@@ -0,0 +1,280 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
+)
+
+var (
+	_ datasource.DataSourceWithValidateConfig = (*vmwareGuestInfoDataSource)(nil)
+)
+
+// networkConfigEncodingNone embeds the network config in the meta-data as a
+// mapping rather than an encoded string.
+const networkConfigEncodingNone = "none"
+
+type vmwareGuestInfoDataSource struct{}
+
+type vmwareGuestInfoModel struct {
+	ID                    types.String `tfsdk:"id"`
+	Parts                 types.List   `tfsdk:"part"` // configPartModel
+	Gzip                  types.Bool   `tfsdk:"gzip"`
+	Boundary              types.String `tfsdk:"boundary"`
+	CloudInitVersion      types.String `tfsdk:"cloud_init_version"`
+	CloudConfigValidation types.String `tfsdk:"cloud_config_validation"`
+	CustomContentTypes    types.List   `tfsdk:"custom_content_types"` // types.String
+	OutputFormat          types.String `tfsdk:"output_format"`
+	ExpandArchiveParts    types.Bool   `tfsdk:"expand_archive_parts"`
+	MetaData              types.String `tfsdk:"meta_data"`
+	NetworkConfig         types.String `tfsdk:"network_config"`
+	NetworkConfigEncoding types.String `tfsdk:"network_config_encoding"`
+	GuestInfo             types.Map    `tfsdk:"guestinfo"` // types.String
+}
+
+func (d *vmwareGuestInfoDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_vmware_guestinfo"
+}
+
+func (d *vmwareGuestInfoDataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var guestInfo vmwareGuestInfoModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &guestInfo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(guestInfo.userDataConfig().validate(ctx)...)
+	resp.Diagnostics.Append(guestInfo.validate()...)
+}
+
+func (d *vmwareGuestInfoDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Blocks: map[string]schema.Block{
+			"part": configPartBlock("A nested block type which adds a file to the user data. Use multiple `part` blocks to specify " +
+				"multiple files, which will be included in order of declaration in the final MIME document."),
+		},
+		Attributes: configRenderAttributes(map[string]schema.Attribute{
+			"gzip": schema.BoolAttribute{
+				Optional: true,
+				Computed: true,
+				MarkdownDescription: "Specify whether or not to gzip the user data and meta-data before base64 encoding them, which " +
+					"sets their encoding to `gzip+base64` rather than `base64`. Defaults to `true`.",
+			},
+			"output_format": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormats()...),
+				},
+				Optional: true,
+				MarkdownDescription: "The format of the user data, before gzip compression and base64 encoding. `mime` renders a " +
+					"multi-part MIME message. `raw` renders the content of the only part as is, and requires exactly one part whose " +
+					"content starts with the marker of its content type, such as `#cloud-config` or `#!`. `archive` renders a " +
+					"[cloud-config-archive](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#cloud-config-archive) document. " +
+					"Parts with `text/cloud-config-archive` content are expanded into a part per entry in the `archive` format, and in the " +
+					"`mime` format when `expand_archive_parts` is `true`. Defaults to `mime`.",
+			},
+			"expand_archive_parts": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: expandArchivePartsDescription,
+			},
+			"meta_data": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "The meta-data of the instance as YAML or JSON, such as the `rendered` output of the " +
+					"`cloudinit_meta_data` data source, with the `instance-id` and `local-hostname` of the instance. When omitted " +
+					"along with `network_config`, the meta-data keys are left out of `guestinfo`, and cloud-init uses the UUID of the " +
+					"virtual machine as the `instance-id`.",
+			},
+			"network_config": schema.StringAttribute{
+				Optional: true,
+				MarkdownDescription: "A [network configuration](https://cloudinit.readthedocs.io/en/latest/reference/network-config.html) " +
+					"in version 1 or 2 format, such as the `rendered` output of the `cloudinit_network_config` data source. It is " +
+					"embedded in the meta-data under the `network` key, where the VMware datasource reads it from. `meta_data` must " +
+					"then not have the `network` or `network.encoding` keys.",
+			},
+			"network_config_encoding": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.OneOf(cloudconfig.EncodingBase64, cloudconfig.EncodingGzipBase64, networkConfigEncodingNone),
+				},
+				Optional: true,
+				MarkdownDescription: "How `network_config` is embedded in the meta-data. `base64` and `gzip+base64` set the `network` " +
+					"key to the encoded network configuration and the `network.encoding` key to the encoding. `none` sets the `network` " +
+					"key to the network configuration as YAML. Defaults to the encoding of the meta-data, following `gzip`.",
+			},
+			"guestinfo": schema.MapAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				MarkdownDescription: "The guestinfo variables to set on the virtual machine, such as with the `extra_config` of a " +
+					"`vsphere_virtual_machine`: `guestinfo.userdata` and `guestinfo.userdata.encoding`, and `guestinfo.metadata` and " +
+					"`guestinfo.metadata.encoding` unless both `meta_data` and `network_config` are omitted.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 digest of the encoded user data and meta-data.",
+			},
+		}),
+		MarkdownDescription: "Renders the guestinfo variables the [VMware datasource](https://cloudinit.readthedocs.io/en/latest/reference/datasources/vmware.html) " +
+			"of cloud-init reads on vSphere, with the user data rendered from the parts like the `cloudinit_config` data source " +
+			"does, and the meta-data with the network configuration embedded.\n\n" +
+			"The values are base64 encoded, and gzip compressed first unless `gzip` is `false`, and the `.encoding` variables are set " +
+			"to match, so that the map can be passed to the virtual machine as is.",
+	}
+}
+
+func (d *vmwareGuestInfoDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var guestInfo vmwareGuestInfoModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &guestInfo)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(guestInfo.update(ctx)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, guestInfo)...)
+}
+
+// userDataConfig returns the config the user data is rendered from, which is
+// always base64 encoded, as guestinfo variables are strings.
+func (g vmwareGuestInfoModel) userDataConfig() configModel {
+	return configModel{
+		Parts:                 g.Parts,
+		Gzip:                  g.Gzip,
+		Base64Encode:          types.BoolValue(true),
+		Boundary:              g.Boundary,
+		CloudInitVersion:      g.CloudInitVersion,
+		CloudConfigValidation: g.CloudConfigValidation,
+		TargetPlatform:        types.StringNull(),
+		CustomContentTypes:    g.CustomContentTypes,
+		OutputFormat:          g.OutputFormat,
+		ExpandArchiveParts:    g.ExpandArchiveParts,
+		SensitiveOutput:       types.BoolNull(),
+	}
+}
+
+// encoding returns the encoding of the user data and meta-data.
+func (g vmwareGuestInfoModel) encoding() string {
+	if g.Gzip.IsNull() || g.Gzip.ValueBool() {
+		return cloudconfig.EncodingGzipBase64
+	}
+
+	return cloudconfig.EncodingBase64
+}
+
+// networkConfigEncoding returns the encoding network_config is embedded in the
+// meta-data with, or "" to embed it as a mapping.
+func (g vmwareGuestInfoModel) networkConfigEncoding() string {
+	switch {
+	case g.NetworkConfigEncoding.IsNull():
+		return g.encoding()
+	case g.NetworkConfigEncoding.ValueString() == networkConfigEncodingNone:
+		return ""
+	default:
+		return g.NetworkConfigEncoding.ValueString()
+	}
+}
+
+// validate checks that network_config can be embedded in meta_data.
+func (g vmwareGuestInfoModel) validate() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if g.NetworkConfig.IsNull() || g.NetworkConfig.IsUnknown() || g.MetaData.IsUnknown() || g.NetworkConfigEncoding.IsUnknown() {
+		return diags
+	}
+
+	if _, err := cloudconfig.EmbedNetworkConfig([]byte(g.MetaData.ValueString()), []byte(g.NetworkConfig.ValueString()), g.networkConfigEncoding()); err != nil {
+		diags.AddAttributeError(
+			path.Root("network_config"),
+			"Invalid Attribute Value",
+			endSentence(fmt.Sprintf("Unable to embed the network config in the meta-data: %s", err)),
+		)
+	}
+
+	return diags
+}
+
+func (g *vmwareGuestInfoModel) update(ctx context.Context) diag.Diagnostics {
+	userDataConfig := g.userDataConfig()
+
+	diags := userDataConfig.update(ctx)
+	if diags.HasError() {
+		return diags
+	}
+
+	g.Parts = userDataConfig.Parts
+	g.Gzip = userDataConfig.Gzip
+	g.Boundary = userDataConfig.Boundary
+
+	variables := map[string]string{
+		cloudconfig.GuestInfoUserData:         userDataConfig.Rendered.ValueString(),
+		cloudconfig.GuestInfoUserDataEncoding: g.encoding(),
+	}
+
+	if !g.MetaData.IsNull() || !g.NetworkConfig.IsNull() {
+		metaData := []byte(g.MetaData.ValueString())
+
+		if !g.NetworkConfig.IsNull() {
+			var err error
+
+			metaData, err = cloudconfig.EmbedNetworkConfig(metaData, []byte(g.NetworkConfig.ValueString()), g.networkConfigEncoding())
+			if err != nil {
+				diags.AddAttributeError(path.Root("network_config"), "Unable to embed network config in meta-data", err.Error())
+				return diags
+			}
+		}
+
+		encoded, err := cloudconfig.EncodeGuestInfo(metaData, g.encoding())
+		if err != nil {
+			diags.AddError("Unable to encode meta-data", err.Error())
+			return diags
+		}
+
+		variables[cloudconfig.GuestInfoMetaData] = encoded
+		variables[cloudconfig.GuestInfoMetaDataEncoding] = g.encoding()
+	}
+
+	diags.Append(checkGuestInfoLimit(variables)...)
+
+	guestInfo, convertDiags := types.MapValueFrom(ctx, types.StringType, variables)
+	diags.Append(convertDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	g.GuestInfo = guestInfo
+	g.ID = types.StringValue(hashcode.SHA256(variables[cloudconfig.GuestInfoUserData] + "\n" + variables[cloudconfig.GuestInfoMetaData]))
+
+	return diags
+}
+
+// checkGuestInfoLimit warns about guestinfo variables larger than the default
+// limit of the host, which the operator can raise.
+func checkGuestInfoLimit(variables map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	limit := platformLimits["vmware"].Bytes
+
+	for _, key := range []string{cloudconfig.GuestInfoUserData, cloudconfig.GuestInfoMetaData} {
+		if size := len(variables[key]); size > limit {
+			diags.AddAttributeWarning(
+				path.Root("guestinfo"),
+				"Guestinfo Size Limit Exceeded",
+				fmt.Sprintf("The %s variable is %d bytes, which exceeds the %d byte default guestinfo limit of vSphere hosts. "+
+					"Consider enabling gzip, moving large files out of the user data, or raising the limit of the host.", key, size, limit),
+			)
+		}
+	}
+
+	return diags
+}
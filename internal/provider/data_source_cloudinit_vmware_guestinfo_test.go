@@ -0,0 +1,175 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestVMwareGuestInfoDataSourceRender(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		DataSourceBlock string
+		ExpectedCount   int
+		Expected        map[string]string
+	}{
+		{
+			"user data only",
+			`data "cloudinit_vmware_guestinfo" "foo" {
+				gzip          = false
+				output_format = "raw"
+
+				part {
+					content = "#cloud-config\n"
+				}
+			}`,
+			2,
+			map[string]string{
+				"guestinfo.userdata":          "I2Nsb3VkLWNvbmZpZwo=",
+				"guestinfo.userdata.encoding": "base64",
+			},
+		},
+		{
+			"meta-data",
+			`data "cloudinit_vmware_guestinfo" "foo" {
+				gzip          = false
+				output_format = "raw"
+				meta_data     = "instance-id: iid-local01\n"
+
+				part {
+					content = "#cloud-config\n"
+				}
+			}`,
+			4,
+			map[string]string{
+				"guestinfo.userdata":          "I2Nsb3VkLWNvbmZpZwo=",
+				"guestinfo.userdata.encoding": "base64",
+				"guestinfo.metadata":          "aW5zdGFuY2UtaWQ6IGlpZC1sb2NhbDAxCg==",
+				"guestinfo.metadata.encoding": "base64",
+			},
+		},
+		{
+			"network config embedded as YAML",
+			`data "cloudinit_vmware_guestinfo" "foo" {
+				gzip                    = false
+				output_format           = "raw"
+				meta_data               = "instance-id: iid-local01\n"
+				network_config          = "version: 2\nethernets:\n  eth0:\n    dhcp4: true\n"
+				network_config_encoding = "none"
+
+				part {
+					content = "#cloud-config\n"
+				}
+			}`,
+			4,
+			map[string]string{
+				"guestinfo.userdata":          "I2Nsb3VkLWNvbmZpZwo=",
+				"guestinfo.userdata.encoding": "base64",
+				"guestinfo.metadata":          "aW5zdGFuY2UtaWQ6IGlpZC1sb2NhbDAxCm5ldHdvcms6CiAgdmVyc2lvbjogMgogIGV0aGVybmV0czoKICAgIGV0aDA6CiAgICAgIGRoY3A0OiB0cnVlCg==",
+				"guestinfo.metadata.encoding": "base64",
+			},
+		},
+		{
+			"gzip",
+			`data "cloudinit_vmware_guestinfo" "foo" {
+				meta_data = "instance-id: iid-local01\n"
+
+				part {
+					content = "#cloud-config\n"
+				}
+			}`,
+			4,
+			// The compressed values depend on the Go release, so only the
+			// encodings are checked.
+			map[string]string{
+				"guestinfo.userdata.encoding": "gzip+base64",
+				"guestinfo.metadata.encoding": "gzip+base64",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			checks := []r.TestCheckFunc{
+				r.TestCheckResourceAttr("data.cloudinit_vmware_guestinfo.foo", "guestinfo.%", strconv.Itoa(tt.ExpectedCount)),
+			}
+
+			for key, value := range tt.Expected {
+				checks = append(checks, r.TestCheckResourceAttr("data.cloudinit_vmware_guestinfo.foo", "guestinfo."+key, value))
+			}
+
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.DataSourceBlock,
+						Check:  r.ComposeTestCheckFunc(checks...),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestVMwareGuestInfoDataSourceRender_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name            string
+		DataSourceBlock string
+		ErrorMatch      *regexp.Regexp
+	}{
+		{
+			"meta-data with a network key",
+			`data "cloudinit_vmware_guestinfo" "foo" {
+				meta_data      = "instance-id: iid-local01\nnetwork: {}\n"
+				network_config = "version: 2\n"
+
+				part {
+					content = "#cloud-config\n"
+				}
+			}`,
+			regexp.MustCompile(`unexpected key "network" in the meta-data`),
+		},
+		{
+			"network config not a mapping",
+			`data "cloudinit_vmware_guestinfo" "foo" {
+				network_config = "- eth0\n"
+
+				part {
+					content = "#cloud-config\n"
+				}
+			}`,
+			regexp.MustCompile(`parsing network config: expected a mapping`),
+		},
+		{
+			"invalid network_config_encoding",
+			`data "cloudinit_vmware_guestinfo" "foo" {
+				network_config          = "version: 2\n"
+				network_config_encoding = "gzip"
+
+				part {
+					content = "#cloud-config\n"
+				}
+			}`,
+			regexp.MustCompile(`Attribute network_config_encoding value must be one of`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.DataSourceBlock,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
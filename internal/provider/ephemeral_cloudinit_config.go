@@ -0,0 +1,146 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	datasourceschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+)
+
+var (
+	_ ephemeral.EphemeralResourceWithValidateConfig = (*configEphemeralResource)(nil)
+)
+
+type configEphemeralResource struct{}
+
+func (e *configEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_config"
+}
+
+func (e *configEphemeralResource) ValidateConfig(ctx context.Context, req ephemeral.ValidateConfigRequest, resp *ephemeral.ValidateConfigResponse) {
+	var cloudinitConfig configModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cloudinitConfig)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(cloudinitConfig.validate(ctx)...)
+}
+
+func (e *configEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	blocks, diags := ephemeralBlocks(map[string]datasourceschema.Block{
+		"part": configPartBlock(partBlockDescription),
+	})
+	resp.Diagnostics.Append(diags...)
+
+	attributes, diags := ephemeralAttributes(configAttributes())
+	resp.Diagnostics.Append(diags...)
+
+	resp.Schema = schema.Schema{
+		Blocks:     blocks,
+		Attributes: attributes,
+		MarkdownDescription: "Renders a [multi-part MIME configuration](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#mime-multi-part-archive) " +
+			"for use with [cloud-init](https://cloudinit.readthedocs.io/en/latest/), like the `cloudinit_config` data source, without " +
+			"storing the parts or the `rendered` output in the plan or state. Use it for user data holding secrets, such as bootstrap " +
+			"tokens or private keys, and pass `rendered` to write-only or ephemeral arguments of other resources. Requires Terraform 1.10 or later.\n\n" +
+			configDescriptionDetails,
+	}
+}
+
+func (e *configEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var cloudinitConfig configModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &cloudinitConfig)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(cloudinitConfig.update(ctx)...)
+	resp.Diagnostics.Append(resp.Result.Set(ctx, cloudinitConfig)...)
+}
+
+// ephemeralAttributes converts the attributes of the cloudinit_config data
+// source into those of the ephemeral resource, so that both share the same
+// schema. The attribute types of both packages have the same fields.
+func ephemeralAttributes(attributes map[string]datasourceschema.Attribute) (map[string]schema.Attribute, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	converted := make(map[string]schema.Attribute, len(attributes))
+
+	for name, attribute := range attributes {
+		switch a := attribute.(type) {
+		case datasourceschema.StringAttribute:
+			converted[name] = schema.StringAttribute(a)
+		case datasourceschema.BoolAttribute:
+			converted[name] = schema.BoolAttribute(a)
+		case datasourceschema.Int64Attribute:
+			converted[name] = schema.Int64Attribute(a)
+		case datasourceschema.ListAttribute:
+			converted[name] = schema.ListAttribute(a)
+		default:
+			diags.AddError("Unable to Build Schema", fmt.Sprintf("Unsupported type %T of the %s attribute.", attribute, name))
+		}
+	}
+
+	return converted, diags
+}
+
+// ephemeralBlocks converts the blocks of the cloudinit_config data source into
+// those of the ephemeral resource, like ephemeralAttributes.
+func ephemeralBlocks(blocks map[string]datasourceschema.Block) (map[string]schema.Block, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	converted := make(map[string]schema.Block, len(blocks))
+
+	for name, block := range blocks {
+		switch b := block.(type) {
+		case datasourceschema.ListNestedBlock:
+			attributes, attributeDiags := ephemeralAttributes(b.NestedObject.Attributes)
+			diags.Append(attributeDiags...)
+
+			nestedBlocks, blockDiags := ephemeralBlocks(b.NestedObject.Blocks)
+			diags.Append(blockDiags...)
+
+			converted[name] = schema.ListNestedBlock{
+				NestedObject: schema.NestedBlockObject{
+					Attributes: attributes,
+					Blocks:     nestedBlocks,
+					CustomType: b.NestedObject.CustomType,
+					Validators: b.NestedObject.Validators,
+				},
+				CustomType:          b.CustomType,
+				Description:         b.Description,
+				MarkdownDescription: b.MarkdownDescription,
+				DeprecationMessage:  b.DeprecationMessage,
+				Validators:          b.Validators,
+			}
+		case datasourceschema.SingleNestedBlock:
+			attributes, attributeDiags := ephemeralAttributes(b.Attributes)
+			diags.Append(attributeDiags...)
+
+			nestedBlocks, blockDiags := ephemeralBlocks(b.Blocks)
+			diags.Append(blockDiags...)
+
+			converted[name] = schema.SingleNestedBlock{
+				Attributes:          attributes,
+				Blocks:              nestedBlocks,
+				CustomType:          b.CustomType,
+				Description:         b.Description,
+				MarkdownDescription: b.MarkdownDescription,
+				DeprecationMessage:  b.DeprecationMessage,
+				Validators:          b.Validators,
+			}
+		default:
+			diags.AddError("Unable to Build Schema", fmt.Sprintf("Unsupported type %T of the %s block.", block, name))
+		}
+	}
+
+	return converted, diags
+}
@@ -0,0 +1,131 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/echoprovider"
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+var testEchoProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
+	"echo": echoprovider.NewProviderServer(),
+}
+
+func TestConfigEphemeralResourceRender(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		ResourceBlock string
+		Expected      string
+	}{
+		{
+			"no gzip or b64 - basic content",
+			`ephemeral "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					content_type = "text/x-shellscript"
+					content = "baz"
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-shellscript\r\nMime-Version: 1.0\r\n\r\nbaz\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - cloud_config content",
+			`ephemeral "cloudinit_config" "foo" {
+				gzip = false
+				base64_encode = false
+
+				part {
+					cloud_config = jsonencode({ packages = ["git"] })
+				}
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\npackages:\n  - git\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_10_0),
+				},
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				ProtoV6ProviderFactories: testEchoProtoV6ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.ResourceBlock + `
+
+						provider "echo" {
+							data = ephemeral.cloudinit_config.foo.rendered
+						}
+
+						resource "echo" "test" {}`,
+						ConfigStateChecks: []statecheck.StateCheck{
+							statecheck.ExpectKnownValue("echo.test", tfjsonpath.New("data"), knownvalue.StringExact(tt.Expected)),
+						},
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestConfigEphemeralResourceRender_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		ResourceBlock string
+		ErrorMatch    *regexp.Regexp
+	}{
+		{
+			"base64 can't be false when gzip is true",
+			`ephemeral "cloudinit_config" "foo" {
+				gzip = true
+				base64_encode = false
+
+				part {
+				  content = "abc"
+				}
+			}`,
+			regexp.MustCompile("Expected base64_encode to be set to true when gzip is true"),
+		},
+		{
+			"invalid cloud-config value",
+			`ephemeral "cloudinit_config" "foo" {
+				cloud_config_validation = "error"
+
+				part {
+					content_type = "text/cloud-config"
+					content      = "#cloud-config\npackage_update: \"yes\"\n"
+				}
+			}`,
+			regexp.MustCompile(`package_update: expected boolean, got string`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_10_0),
+				},
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.ResourceBlock,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
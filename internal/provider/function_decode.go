@@ -0,0 +1,67 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = (*decodeFunction)(nil)
+)
+
+type decodeFunction struct{}
+
+func (f *decodeFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "decode"
+}
+
+func (f *decodeFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Decode a rendered cloud-init configuration into its parts",
+		MarkdownDescription: "Decodes rendered cloud-init user data, such as the `rendered` attribute of the `cloudinit_config` data source, " +
+			"back into a list of parts with `content_type`, `filename`, `merge_type` and `content` attributes. Parts written with " +
+			"`Content-Transfer-Encoding: base64` are returned with `content_base64` in place of `content`. The `cloud_config` and `include` " +
+			"attributes are always `null`, and `content_size` is the size in bytes of the decoded content. Base64 encoding and gzip compression " +
+			"are detected automatically. User data which is not a multi-part MIME document is returned as a single part, with the " +
+			"content type inferred from its first line like cloud-init does.",
+		Parameters: []function.Parameter{
+			function.StringParameter{
+				Name:                "rendered",
+				MarkdownDescription: "The rendered cloud-init user data to decode.",
+			},
+		},
+		Return: function.ListReturn{
+			ElementType: types.ObjectType{
+				AttrTypes: configPartAttrTypes,
+			},
+		},
+	}
+}
+
+func (f *decodeFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var rendered string
+
+	resp.Error = req.Arguments.Get(ctx, &rendered)
+	if resp.Error != nil {
+		return
+	}
+
+	configParts, err := decodeRendered(rendered)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Unable to decode cloudinit config: "+err.Error())
+		return
+	}
+
+	partsList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: configPartAttrTypes}, configParts)
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, partsList)
+}
@@ -0,0 +1,181 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestDecodeFunction(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `
+				output "test" {
+					value = provider::cloudinit::decode("Content-Type: multipart/mixed; boundary=\"//\"\nMIME-Version: 1.0\r\n\r\n--//\r\nContent-Disposition: attachment; filename=\"foofile1.txt\"\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-shellscript\r\nMime-Version: 1.0\r\nX-Merge-Type: list()+dict()+str()\r\n\r\nfoo1\r\n--//\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/plain\r\nMime-Version: 1.0\r\n\r\nbar1\r\n--//--\r\n")
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"content_type":   knownvalue.StringExact("text/x-shellscript"),
+							"content":        knownvalue.StringExact("foo1"),
+							"content_base64": knownvalue.Null(),
+							"cloud_config":   knownvalue.Null(),
+							"include":        knownvalue.Null(),
+							"filename":       knownvalue.StringExact("foofile1.txt"),
+							"merge_type":     knownvalue.StringExact("list()+dict()+str()"),
+							"content_size":   knownvalue.Int64Exact(4),
+						}),
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"content_type":   knownvalue.StringExact("text/plain"),
+							"content":        knownvalue.StringExact("bar1"),
+							"content_base64": knownvalue.Null(),
+							"cloud_config":   knownvalue.Null(),
+							"include":        knownvalue.Null(),
+							"filename":       knownvalue.Null(),
+							"merge_type":     knownvalue.Null(),
+							"content_size":   knownvalue.Int64Exact(4),
+						}),
+					})),
+				},
+			},
+		},
+	})
+}
+
+func TestDecodeFunction_roundTrip(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Options string
+	}{
+		{
+			"no gzip or b64",
+			`{ gzip = false, base64_encode = false }`,
+		},
+		{
+			"no gzip - b64 encoded",
+			`{ gzip = false, base64_encode = true }`,
+		},
+		{
+			"gzip compression",
+			`null`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: `
+						locals {
+							parts = [
+								{
+									content_type   = "text/x-shellscript"
+									content        = "#!/bin/sh\necho hello\n"
+									content_base64 = null
+									cloud_config   = null
+									include        = null
+									filename       = "hello.sh"
+									merge_type     = null
+									content_size   = 21
+								},
+								{
+									content_type   = "text/cloud-config"
+									content        = "#cloud-config\npackages:\n  - git\n"
+									content_base64 = null
+									cloud_config   = null
+									include        = null
+									filename       = null
+									merge_type     = "list(append)+dict(recurse_array)+str()"
+									content_size   = 32
+								},
+								{
+									content_type   = "text/plain"
+									content        = null
+									content_base64 = "H4sIAAAAAAACA8tIzcnJ11Eozy/KSVHkAgDA3zG2DgAAAA=="
+									cloud_config   = null
+									include        = null
+									filename       = "hello.txt.gz"
+									merge_type     = null
+									content_size   = 34
+								},
+							]
+						}
+
+						output "test" {
+							value = jsonencode(provider::cloudinit::decode(provider::cloudinit::render(local.parts, ` + tt.Options + `))) == jsonencode(local.parts)
+						}`,
+						Check: r.ComposeTestCheckFunc(
+							r.TestCheckOutput("test", "true"),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestDecodeFunction_notMultipart(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `
+				output "test" {
+					value = provider::cloudinit::decode(base64encode("#!/bin/sh\necho hello\n"))
+				}`,
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownOutputValue("test", knownvalue.ListExact([]knownvalue.Check{
+						knownvalue.ObjectExact(map[string]knownvalue.Check{
+							"content_type":   knownvalue.StringExact("text/x-shellscript"),
+							"content":        knownvalue.StringExact("#!/bin/sh\necho hello\n"),
+							"content_base64": knownvalue.Null(),
+							"cloud_config":   knownvalue.Null(),
+							"include":        knownvalue.Null(),
+							"filename":       knownvalue.Null(),
+							"merge_type":     knownvalue.Null(),
+							"content_size":   knownvalue.Int64Exact(21),
+						}),
+					})),
+				},
+			},
+		},
+	})
+}
+
+func TestDecodeFunction_handleErrors(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `
+				output "test" {
+					value = provider::cloudinit::decode(base64encode("Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\n\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: base64\r\n\r\n!!!\r\n--MIMEBOUNDARY--\r\n"))
+				}`,
+				ExpectError: regexp.MustCompile("Unable to decode cloudinit config"),
+			},
+		},
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+)
+
+var (
+	_ function.Function = (*mergeCloudConfigFunction)(nil)
+)
+
+type mergeCloudConfigFunction struct{}
+
+func (f *mergeCloudConfigFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "merge_cloud_config"
+}
+
+func (f *mergeCloudConfigFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Merge cloud-config parts the way cloud-init would",
+		MarkdownDescription: "Merges `text/cloud-config` parts into a single cloud-config document, emulating how cloud-init " +
+			"[merges](https://cloudinit.readthedocs.io/en/latest/reference/merging.html) them on boot. Each part is merged into the result of " +
+			"the previous parts using the mergers of its inline `merge_how` key followed by those of its `merge_type`, or " +
+			"`dict(replace)+list()+str()` when neither is set, which replaces the values of keys set by earlier parts. Like in cloud-init, the " +
+			"`merge_how` and `merge_type` keys are merged into the result as well. The result is written like the `cloud_config` attribute of a " +
+			"part, with sorted keys.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name: "parts",
+				MarkdownDescription: "A list of objects, one per cloud-config part in order of declaration, with the same attributes as " +
+					"the parts of the `render` function. Every part must have the `text/cloud-config` content type, which is inferred when omitted.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *mergeCloudConfigFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var parts types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &parts)
+	if resp.Error != nil {
+		return
+	}
+
+	cloudinitConfig, funcErr := configModelFromArguments(ctx, parts, types.DynamicNull())
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	diags := cloudinitConfig.validate(ctx)
+	if !diags.HasError() {
+		diags.Append(cloudinitConfig.setDefaults(ctx)...)
+	}
+
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	var configParts []configPartModel
+	resp.Error = function.FuncErrorFromDiags(ctx, cloudinitConfig.Parts.ElementsAs(ctx, &configParts, false))
+	if resp.Error != nil {
+		return
+	}
+
+	mergeParts := make([]cloudconfig.Part, 0, len(configParts))
+
+	for i, part := range configParts {
+		if part.ContentType.ValueString() != "text/cloud-config" {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Expected part %d to have the text/cloud-config content type, got %q.", i, part.ContentType.ValueString()))
+			return
+		}
+
+		body, err := part.body()
+		if err != nil {
+			resp.Error = function.NewArgumentFuncError(0, fmt.Sprintf("Unable to read part %d: %s.", i, err))
+			return
+		}
+
+		mergeParts = append(mergeParts, cloudconfig.Part{
+			Content:   string(body),
+			MergeType: part.MergeType.ValueString(),
+		})
+	}
+
+	merged, err := cloudconfig.Merge(mergeParts)
+	if err != nil {
+		resp.Error = function.NewArgumentFuncError(0, "Unable to merge cloud-config: "+err.Error()+".")
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, string(merged))
+}
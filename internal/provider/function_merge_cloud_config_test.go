@@ -0,0 +1,136 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestMergeCloudConfigFunction(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Config   string
+		Expected string
+	}{
+		{
+			"default merge type replaces existing values",
+			`output "test" {
+				value = provider::cloudinit::merge_cloud_config([
+					{ content = "#cloud-config\nhostname: a\npackages: [git]\n" },
+					{ content = "#cloud-config\nhostname: b\npackages: [curl]\nruncmd: [echo hello]\n" },
+				])
+			}`,
+			"#cloud-config\nhostname: b\npackages:\n  - curl\nruncmd:\n  - echo hello\n",
+		},
+		{
+			"merge_type appends lists",
+			`output "test" {
+				value = provider::cloudinit::merge_cloud_config([
+					{ cloud_config = { packages = ["git"] } },
+					{
+						content_type = "text/cloud-config"
+						content      = "#cloud-config\npackages: [curl]\n"
+						merge_type   = "list(append)+dict(recurse_array)+str()"
+					},
+				])
+			}`,
+			"#cloud-config\npackages:\n  - git\n  - curl\n",
+		},
+		{
+			"inline merge_how takes precedence over merge_type",
+			`output "test" {
+				value = provider::cloudinit::merge_cloud_config([
+					{ content = "#cloud-config\npackages: [git]\n" },
+					{
+						content    = "#cloud-config\nmerge_how: list(prepend)+dict(recurse_array)\npackages: [curl]\n"
+						merge_type = "list(append)+dict(recurse_array)"
+					},
+				])
+			}`,
+			"#cloud-config\nmerge_how: list(prepend)+dict(recurse_array)\npackages:\n  - curl\n  - git\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.Config,
+						Check: r.ComposeTestCheckFunc(
+							r.TestCheckOutput("test", tt.Expected),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestMergeCloudConfigFunction_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Config     string
+		ErrorMatch *regexp.Regexp
+	}{
+		{
+			"parts must be cloud-config",
+			`output "test" {
+				value = provider::cloudinit::merge_cloud_config([
+					{ content = "#!/bin/sh\necho hello\n" },
+				])
+			}`,
+			regexp.MustCompile(`Expected part 0 to have the text/cloud-config content type`),
+		},
+		{
+			"jinja templates cannot be merged",
+			`output "test" {
+				value = provider::cloudinit::merge_cloud_config([
+					{
+						content_type = "text/cloud-config"
+						content      = "## template: jinja\n#cloud-config\nhostname: {{ v1.local_hostname }}\n"
+					},
+				])
+			}`,
+			regexp.MustCompile(`Jinja templates are only rendered on boot`),
+		},
+		{
+			"unknown merger",
+			`output "test" {
+				value = provider::cloudinit::merge_cloud_config([
+					{
+						content    = "#cloud-config\npackages: [git]\n"
+						merge_type = "lists(append)"
+					},
+				])
+			}`,
+			regexp.MustCompile(`unknown merger "lists"`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.Config,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
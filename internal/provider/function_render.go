@@ -0,0 +1,452 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ function.Function = (*renderFunction)(nil)
+)
+
+type renderFunction struct{}
+
+func (f *renderFunction) Metadata(ctx context.Context, req function.MetadataRequest, resp *function.MetadataResponse) {
+	resp.Name = "render"
+}
+
+func (f *renderFunction) Definition(ctx context.Context, req function.DefinitionRequest, resp *function.DefinitionResponse) {
+	resp.Definition = function.Definition{
+		Summary: "Render a multi-part MIME configuration for cloud-init",
+		MarkdownDescription: "Renders a [multi-part MIME configuration](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#mime-multi-part-archive) " +
+			"for use with [cloud-init](https://cloudinit.readthedocs.io/en/latest/). The output is identical to the `rendered` attribute of the " +
+			"`cloudinit_config` data source given the same parts and options.",
+		Parameters: []function.Parameter{
+			function.DynamicParameter{
+				Name: "parts",
+				MarkdownDescription: "A list of objects, one per file in the generated cloud-init configuration, in order of declaration. " +
+					"Each object accepts the same attributes as the `part` block of the `cloudinit_config` data source: " +
+					"`content`, `content_base64`, `cloud_config` or `include`, `content_type`, `filename` and `merge_type`. `cloud_config` may also be " +
+					"given as an object instead of a JSON string, and `include` is an object with `urls` and `once` attributes. A `content_size` " +
+					"attribute, as returned by the `decode` function, is ignored.",
+			},
+			function.DynamicParameter{
+				Name:           "options",
+				AllowNullValue: true,
+				MarkdownDescription: "An object with any of the `gzip`, `base64_encode`, `boundary`, `cloud_init_version`, `cloud_config_validation`, `target_platform`, " +
+					"`custom_content_types`, `output_format` and `expand_archive_parts` attributes of the `cloudinit_config` data source, which take the same defaults when omitted. May be `null`.",
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+func (f *renderFunction) Run(ctx context.Context, req function.RunRequest, resp *function.RunResponse) {
+	var parts, options types.Dynamic
+
+	resp.Error = req.Arguments.Get(ctx, &parts, &options)
+	if resp.Error != nil {
+		return
+	}
+
+	cloudinitConfig, funcErr := configModelFromArguments(ctx, parts, options)
+	if funcErr != nil {
+		resp.Error = funcErr
+		return
+	}
+
+	diags := cloudinitConfig.validate(ctx)
+	if !diags.HasError() {
+		diags.Append(cloudinitConfig.update(ctx)...)
+	}
+
+	resp.Error = function.FuncErrorFromDiags(ctx, diags)
+	if resp.Error != nil {
+		return
+	}
+
+	resp.Error = resp.Result.Set(ctx, cloudinitConfig.Rendered)
+}
+
+// configModelFromArguments builds a configModel from the dynamic parts and
+// options arguments shared by the provider-defined functions.
+func configModelFromArguments(ctx context.Context, parts types.Dynamic, options types.Dynamic) (configModel, *function.FuncError) {
+	var cloudinitConfig configModel
+
+	elements, ok := dynamicElements(parts.UnderlyingValue())
+	if !ok {
+		return cloudinitConfig, function.NewArgumentFuncError(0, "Expected parts to be a list of objects.")
+	}
+
+	if len(elements) == 0 {
+		return cloudinitConfig, function.NewArgumentFuncError(0, "Expected at least one part.")
+	}
+
+	configParts := make([]configPartModel, 0, len(elements))
+
+	for i, element := range elements {
+		attributes, ok := dynamicAttributes(element)
+		if !ok {
+			return cloudinitConfig, function.NewArgumentFuncError(0, fmt.Sprintf("Expected part %d to be an object.", i))
+		}
+
+		part := configPartModel{
+			Include: types.ObjectNull(configPartIncludeAttrTypes),
+		}
+
+		for name, value := range attributes {
+			var target *types.String
+
+			switch name {
+			case "cloud_config":
+				if part.CloudConfig, ok = dynamicJSON(value); !ok {
+					return cloudinitConfig, function.NewArgumentFuncError(0, fmt.Sprintf("Expected attribute %q in part %d to be an object or a JSON string.", name, i))
+				}
+				continue
+			case "include":
+				if part.Include, ok = dynamicInclude(value); !ok {
+					return cloudinitConfig, function.NewArgumentFuncError(0, fmt.Sprintf("Expected attribute %q in part %d to be an object with a urls list of strings and an optional once boolean.", name, i))
+				}
+				continue
+			case "content_size":
+				// Computed, but accepted so that the output of decode can be
+				// rendered again.
+				continue
+			case "content_type":
+				target = &part.ContentType
+			case "content":
+				target = &part.Content
+			case "content_base64":
+				target = &part.ContentBase64
+			case "filename":
+				target = &part.FileName
+			case "merge_type":
+				target = &part.MergeType
+			default:
+				return cloudinitConfig, function.NewArgumentFuncError(0, fmt.Sprintf("Unsupported attribute %q in part %d.", name, i))
+			}
+
+			if *target, ok = dynamicString(value); !ok {
+				return cloudinitConfig, function.NewArgumentFuncError(0, fmt.Sprintf("Expected attribute %q in part %d to be a string.", name, i))
+			}
+		}
+
+		if set := countNotNull(part.Content, part.ContentBase64, part.CloudConfig, part.Include); set != 1 {
+			return cloudinitConfig, function.NewArgumentFuncError(0, fmt.Sprintf("Expected exactly one of \"content\", \"content_base64\", \"cloud_config\" or \"include\" in part %d.", i))
+		}
+
+		configParts = append(configParts, part)
+	}
+
+	partsList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: configPartAttrTypes}, configParts)
+	if diags.HasError() {
+		return cloudinitConfig, function.FuncErrorFromDiags(ctx, diags)
+	}
+
+	cloudinitConfig.Parts = partsList
+	cloudinitConfig.Gzip = types.BoolNull()
+	cloudinitConfig.Base64Encode = types.BoolNull()
+	cloudinitConfig.Boundary = types.StringNull()
+	cloudinitConfig.CloudInitVersion = types.StringNull()
+	cloudinitConfig.CloudConfigValidation = types.StringNull()
+	cloudinitConfig.TargetPlatform = types.StringNull()
+	cloudinitConfig.CustomContentTypes = types.ListNull(types.StringType)
+	cloudinitConfig.OutputFormat = types.StringNull()
+	cloudinitConfig.ExpandArchiveParts = types.BoolNull()
+
+	if options.IsNull() || options.IsUnderlyingValueNull() {
+		return cloudinitConfig, nil
+	}
+
+	attributes, ok := dynamicAttributes(options.UnderlyingValue())
+	if !ok {
+		return cloudinitConfig, function.NewArgumentFuncError(1, "Expected options to be an object.")
+	}
+
+	for name, value := range attributes {
+		switch name {
+		case "gzip":
+			cloudinitConfig.Gzip, ok = dynamicBool(value)
+		case "base64_encode":
+			cloudinitConfig.Base64Encode, ok = dynamicBool(value)
+		case "boundary":
+			cloudinitConfig.Boundary, ok = dynamicString(value)
+			if ok && !cloudinitConfig.Boundary.IsNull() && cloudinitConfig.Boundary.ValueString() == "" {
+				return cloudinitConfig, function.NewArgumentFuncError(1, "Expected boundary to be at least 1 character long.")
+			}
+		case "cloud_init_version":
+			cloudinitConfig.CloudInitVersion, ok = dynamicString(value)
+		case "cloud_config_validation":
+			cloudinitConfig.CloudConfigValidation, ok = dynamicString(value)
+			if ok && !cloudinitConfig.CloudConfigValidation.IsNull() && !slices.Contains(cloudConfigValidations(), cloudinitConfig.CloudConfigValidation.ValueString()) {
+				return cloudinitConfig, function.NewArgumentFuncError(1, fmt.Sprintf("Expected cloud_config_validation to be one of: %s.", strings.Join(cloudConfigValidations(), ", ")))
+			}
+		case "target_platform":
+			cloudinitConfig.TargetPlatform, ok = dynamicString(value)
+			if ok && !cloudinitConfig.TargetPlatform.IsNull() {
+				if _, known := platformLimits[cloudinitConfig.TargetPlatform.ValueString()]; !known {
+					return cloudinitConfig, function.NewArgumentFuncError(1, fmt.Sprintf("Expected target_platform to be one of: %s.", strings.Join(targetPlatforms(), ", ")))
+				}
+			}
+		case "custom_content_types":
+			cloudinitConfig.CustomContentTypes, ok = dynamicStringList(value)
+		case "output_format":
+			cloudinitConfig.OutputFormat, ok = dynamicString(value)
+			if ok && !cloudinitConfig.OutputFormat.IsNull() && !slices.Contains(outputFormats(), cloudinitConfig.OutputFormat.ValueString()) {
+				return cloudinitConfig, function.NewArgumentFuncError(1, fmt.Sprintf("Expected output_format to be one of: %s.", strings.Join(outputFormats(), ", ")))
+			}
+		case "expand_archive_parts":
+			cloudinitConfig.ExpandArchiveParts, ok = dynamicBool(value)
+		default:
+			return cloudinitConfig, function.NewArgumentFuncError(1, fmt.Sprintf("Unsupported attribute %q in options.", name))
+		}
+
+		if !ok {
+			return cloudinitConfig, function.NewArgumentFuncError(1, fmt.Sprintf("Unexpected type for attribute %q in options.", name))
+		}
+	}
+
+	return cloudinitConfig, nil
+}
+
+// dynamicElements returns the elements of a list or tuple value passed to a
+// dynamic function parameter.
+func dynamicElements(value attr.Value) ([]attr.Value, bool) {
+	switch v := value.(type) {
+	case types.List:
+		return v.Elements(), true
+	case types.Tuple:
+		return v.Elements(), true
+	}
+
+	return nil, false
+}
+
+// dynamicAttributes returns the attributes of an object or map value passed to
+// a dynamic function parameter.
+func dynamicAttributes(value attr.Value) (map[string]attr.Value, bool) {
+	switch v := value.(type) {
+	case types.Object:
+		return v.Attributes(), true
+	case types.Map:
+		return v.Elements(), true
+	}
+
+	return nil, false
+}
+
+func dynamicString(value attr.Value) (types.String, bool) {
+	if dynamicValue, ok := value.(types.Dynamic); ok {
+		if dynamicValue.IsNull() || dynamicValue.IsUnderlyingValueNull() {
+			return types.StringNull(), true
+		}
+
+		value = dynamicValue.UnderlyingValue()
+	}
+
+	v, ok := value.(types.String)
+
+	return v, ok
+}
+
+func dynamicBool(value attr.Value) (types.Bool, bool) {
+	if dynamicValue, ok := value.(types.Dynamic); ok {
+		if dynamicValue.IsNull() || dynamicValue.IsUnderlyingValueNull() {
+			return types.BoolNull(), true
+		}
+
+		value = dynamicValue.UnderlyingValue()
+	}
+
+	v, ok := value.(types.Bool)
+
+	return v, ok
+}
+
+// dynamicStringList returns a list of strings from a list or tuple value passed
+// to a dynamic function parameter.
+func dynamicStringList(value attr.Value) (types.List, bool) {
+	if dynamicValue, ok := value.(types.Dynamic); ok {
+		if dynamicValue.IsNull() || dynamicValue.IsUnderlyingValueNull() {
+			return types.ListNull(types.StringType), true
+		}
+
+		value = dynamicValue.UnderlyingValue()
+	}
+
+	if value.IsNull() {
+		return types.ListNull(types.StringType), true
+	}
+
+	elements, ok := dynamicElements(value)
+	if !ok {
+		return types.ListNull(types.StringType), false
+	}
+
+	values := make([]attr.Value, 0, len(elements))
+	for _, element := range elements {
+		v, ok := dynamicString(element)
+		if !ok || v.IsNull() {
+			return types.ListNull(types.StringType), false
+		}
+
+		values = append(values, v)
+	}
+
+	list, diags := types.ListValue(types.StringType, values)
+
+	return list, !diags.HasError()
+}
+
+// dynamicJSON returns the JSON encoding of a value passed to a dynamic function
+// parameter, like jsonencode. Strings are assumed to already be JSON.
+func dynamicJSON(value attr.Value) (types.String, bool) {
+	if v, ok := dynamicString(value); ok {
+		return v, true
+	}
+
+	goValue, ok := dynamicGoValue(value)
+	if !ok {
+		return types.StringNull(), false
+	}
+
+	data, err := json.Marshal(goValue)
+	if err != nil {
+		return types.StringNull(), false
+	}
+
+	return types.StringValue(string(data)), true
+}
+
+// dynamicGoValue converts a value passed to a dynamic function parameter into
+// the equivalent of a value decoded by encoding/json.
+func dynamicGoValue(value attr.Value) (any, bool) {
+	if value.IsNull() {
+		return nil, true
+	}
+
+	switch v := value.(type) {
+	case types.Dynamic:
+		if v.IsUnderlyingValueNull() {
+			return nil, true
+		}
+
+		return dynamicGoValue(v.UnderlyingValue())
+	case types.String:
+		return v.ValueString(), true
+	case types.Bool:
+		return v.ValueBool(), true
+	case types.Number:
+		f := v.ValueBigFloat()
+		if f.IsInt() {
+			i, _ := f.Int(nil)
+			return json.Number(i.String()), true
+		}
+
+		return json.Number(f.Text('g', -1)), true
+	case types.Set:
+		return dynamicGoValues(v.Elements())
+	}
+
+	if elements, ok := dynamicElements(value); ok {
+		return dynamicGoValues(elements)
+	}
+
+	if attributes, ok := dynamicAttributes(value); ok {
+		result := make(map[string]any, len(attributes))
+		for name, attribute := range attributes {
+			if result[name], ok = dynamicGoValue(attribute); !ok {
+				return nil, false
+			}
+		}
+
+		return result, true
+	}
+
+	return nil, false
+}
+
+func dynamicGoValues(elements []attr.Value) (any, bool) {
+	result := make([]any, 0, len(elements))
+	for _, element := range elements {
+		v, ok := dynamicGoValue(element)
+		if !ok {
+			return nil, false
+		}
+
+		result = append(result, v)
+	}
+
+	return result, true
+}
+
+// dynamicInclude returns the include block of a part from an object value
+// passed to a dynamic function parameter, with a urls list and an optional once
+// attribute.
+func dynamicInclude(value attr.Value) (types.Object, bool) {
+	if dynamicValue, ok := value.(types.Dynamic); ok {
+		if dynamicValue.IsNull() || dynamicValue.IsUnderlyingValueNull() {
+			return types.ObjectNull(configPartIncludeAttrTypes), true
+		}
+
+		value = dynamicValue.UnderlyingValue()
+	}
+
+	if value.IsNull() {
+		return types.ObjectNull(configPartIncludeAttrTypes), true
+	}
+
+	attributes, ok := dynamicAttributes(value)
+	if !ok {
+		return types.ObjectNull(configPartIncludeAttrTypes), false
+	}
+
+	values := map[string]attr.Value{
+		"urls": types.ListNull(types.StringType),
+		"once": types.BoolNull(),
+	}
+
+	for name, attribute := range attributes {
+		switch name {
+		case "urls":
+			values[name], ok = dynamicStringList(attribute)
+		case "once":
+			values[name], ok = dynamicBool(attribute)
+		default:
+			ok = false
+		}
+
+		if !ok {
+			return types.ObjectNull(configPartIncludeAttrTypes), false
+		}
+	}
+
+	if values["urls"].IsNull() {
+		return types.ObjectNull(configPartIncludeAttrTypes), false
+	}
+
+	include, diags := types.ObjectValue(configPartIncludeAttrTypes, values)
+
+	return include, !diags.HasError()
+}
+
+func countNotNull(values ...attr.Value) int {
+	count := 0
+	for _, value := range values {
+		if !value.IsNull() {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,319 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestRenderFunction(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Config   string
+		Expected string
+	}{
+		{
+			"no gzip or b64 - basic content",
+			`output "test" {
+				value = provider::cloudinit::render(
+					[
+						{
+							content_type = "text/x-shellscript"
+							content      = "baz"
+						},
+					],
+					{
+						gzip          = false
+						base64_encode = false
+					},
+				)
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-shellscript\r\nMime-Version: 1.0\r\n\r\nbaz\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - two parts - all fields",
+			`output "test" {
+				value = provider::cloudinit::render(
+					[
+						{
+							content_type = "text/x-shellscript"
+							content      = "foo1"
+							filename     = "foofile1.txt"
+							merge_type   = "list()+dict()+str()"
+						},
+						{
+							content  = "bar1"
+							filename = "barfile1.txt"
+						},
+					],
+					{
+						gzip          = false
+						base64_encode = false
+						boundary      = "//"
+					},
+				)
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"//\"\nMIME-Version: 1.0\r\n\r\n--//\r\nContent-Disposition: attachment; filename=\"foofile1.txt\"\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-shellscript\r\nMime-Version: 1.0\r\nX-Merge-Type: list()+dict()+str()\r\n\r\nfoo1\r\n--//\r\nContent-Disposition: attachment; filename=\"barfile1.txt\"\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/plain\r\nMime-Version: 1.0\r\n\r\nbar1\r\n--//--\r\n",
+		},
+		{
+			"null options - defaults to gzip and b64",
+			`output "test" {
+				value = provider::cloudinit::render(
+					[
+						{
+							content_type = "text/x-shellscript"
+							content      = "heythere"
+						},
+					],
+					null,
+				)
+			}`,
+			"H4sIAAAAAAAA/2TNuwrCQBCF4X5h32FJP0YrIWLhJYVFFEQFy1xGM5DMhtkJJG8vWkjQ8sDP+XaeFVnhMnaYuLZvlLpcNG5pwGrlCt9zlcu4jrJDlm5P1+N+c75H5r3ghhLIc+IWs7k11gBMI2u+35JzeKBAyqWviJ+JWxakk+CDKw4aDxBqbJpQCnVqTUYt/jk1jlqj4K8IYM0rAAD//0u6BO3QAAAA",
+		},
+		{
+			"no gzip or b64 - custom content type",
+			`output "test" {
+				value = provider::cloudinit::render(
+					[
+						{
+							content_type = "text/x-my-handler"
+							content      = "baz"
+						},
+					],
+					{
+						gzip                 = false
+						base64_encode        = false
+						custom_content_types = ["text/x-my-handler"]
+					},
+				)
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-my-handler\r\nMime-Version: 1.0\r\n\r\nbaz\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - cloud_config object",
+			`output "test" {
+				value = provider::cloudinit::render(
+					[
+						{
+							cloud_config = {
+								packages    = ["git"]
+								write_files = [{ path = "/etc/motd", content = "hello\nworld\n" }]
+							}
+						},
+					],
+					{
+						gzip          = false
+						base64_encode = false
+					},
+				)
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\npackages:\n  - git\nwrite_files:\n  - content: |\n      hello\n      world\n    path: /etc/motd\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - include object",
+			`output "test" {
+				value = provider::cloudinit::render(
+					[
+						{
+							include = {
+								urls = ["https://example.com/cloud-config.yaml"]
+								once = true
+							}
+						},
+					],
+					{
+						gzip          = false
+						base64_encode = false
+					},
+				)
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-include-once-url\r\nMime-Version: 1.0\r\n\r\n#include-once\nhttps://example.com/cloud-config.yaml\n\r\n--MIMEBOUNDARY--\r\n",
+		},
+		{
+			"no gzip or b64 - archive part expanded into MIME parts",
+			`output "test" {
+				value = provider::cloudinit::render(
+					[
+						{
+							content = "#cloud-config-archive\n- type: text/x-shellscript\n  content: echo hello\n"
+						},
+					],
+					{
+						gzip                 = false
+						base64_encode        = false
+						expand_archive_parts = true
+					},
+				)
+			}`,
+			"Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/x-shellscript\r\nMime-Version: 1.0\r\n\r\necho hello\r\n--MIMEBOUNDARY--\r\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: tt.Config,
+						Check: r.ComposeTestCheckFunc(
+							r.TestCheckOutput("test", tt.Expected),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestRenderFunction_matchesDataSource(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_8_0),
+		},
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: `
+				data "cloudinit_config" "foo" {
+					part {
+						content_type = "text/x-shellscript"
+						content      = "foo1"
+						filename     = "foofile1.txt"
+					}
+
+					part {
+						content_type = "text/cloud-config"
+						content      = "bar1"
+						merge_type   = "list(append)+dict(recurse_array)+str()"
+					}
+				}
+
+				output "test" {
+					value = provider::cloudinit::render([
+						{
+							content_type = "text/x-shellscript"
+							content      = "foo1"
+							filename     = "foofile1.txt"
+						},
+						{
+							content_type = "text/cloud-config"
+							content      = "bar1"
+							merge_type   = "list(append)+dict(recurse_array)+str()"
+						},
+					], null) == data.cloudinit_config.foo.rendered
+				}`,
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckOutput("test", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestRenderFunction_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Config     string
+		ErrorMatch *regexp.Regexp
+	}{
+		{
+			"base64 can't be false when gzip is true",
+			`output "test" {
+				value = provider::cloudinit::render([{ content = "abc" }], {
+					gzip          = true
+					base64_encode = false
+				})
+			}`,
+			regexp.MustCompile("Expected base64_encode to be set to true when gzip is true"),
+		},
+		{
+			"at least one part is required",
+			`output "test" {
+				value = provider::cloudinit::render([], null)
+			}`,
+			regexp.MustCompile("Expected at least one part"),
+		},
+		{
+			"content or content_base64 is required",
+			`output "test" {
+				value = provider::cloudinit::render([{ filename = "abc" }], null)
+			}`,
+			regexp.MustCompile(`Expected exactly one of "content",`),
+		},
+		{
+			"content and content_base64 are mutually exclusive",
+			`output "test" {
+				value = provider::cloudinit::render([{ content = "abc", content_base64 = "YWJj" }], null)
+			}`,
+			regexp.MustCompile(`Expected exactly one of "content",`),
+		},
+		{
+			"include without urls",
+			`output "test" {
+				value = provider::cloudinit::render([{ include = { once = true } }], null)
+			}`,
+			regexp.MustCompile(`Expected attribute "include" in part 0 to be an object with a urls list`),
+		},
+		{
+			"invalid include URL",
+			`output "test" {
+				value = provider::cloudinit::render([{ include = { urls = ["ftp://example.com/a.yaml"] } }], null)
+			}`,
+			regexp.MustCompile(`URL 0 of the include of part 0 is not valid`),
+		},
+		{
+			"unsupported part attribute",
+			`output "test" {
+				value = provider::cloudinit::render([{ content = "abc", contents = "abc" }], null)
+			}`,
+			regexp.MustCompile(`Unsupported attribute "contents" in part 0`),
+		},
+		{
+			"unsupported option",
+			`output "test" {
+				value = provider::cloudinit::render([{ content = "abc" }], { compress = true })
+			}`,
+			regexp.MustCompile(`Unsupported attribute "compress" in options`),
+		},
+		{
+			"unknown content_type",
+			`output "test" {
+				value = provider::cloudinit::render([{ content_type = "text/x-my-handler", content = "abc" }], null)
+			}`,
+			regexp.MustCompile(`Unknown content type "text/x-my-handler"`),
+		},
+		{
+			"unknown output_format",
+			`output "test" {
+				value = provider::cloudinit::render([{ content = "abc" }], { output_format = "zip" })
+			}`,
+			regexp.MustCompile(`Expected output_format to be one of: mime, raw, archive`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+					tfversion.SkipBelow(tfversion.Version1_8_0),
+				},
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.Config,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// configPartIncludeAttrTypes are the attributes of the include block of a part.
+var configPartIncludeAttrTypes = map[string]attr.Type{
+	"urls": types.ListType{ElemType: types.StringType},
+	"once": types.BoolType,
+}
+
+// includeSchemes are the URL schemes cloud-init fetches includes with.
+var includeSchemes = []string{"https", "http", "file"}
+
+// includeURLs returns the URLs of the include block of the part.
+func (p configPartModel) includeURLs() []string {
+	urls, ok := p.Include.Attributes()["urls"].(types.List)
+	if !ok {
+		return nil
+	}
+
+	values := make([]string, 0, len(urls.Elements()))
+	for _, element := range urls.Elements() {
+		if value, ok := element.(types.String); ok {
+			values = append(values, value.ValueString())
+		}
+	}
+
+	return values
+}
+
+// includeOnce returns whether once is set in the include block of the part.
+func (p configPartModel) includeOnce() bool {
+	once, ok := p.Include.Attributes()["once"].(types.Bool)
+
+	return ok && once.ValueBool()
+}
+
+// includeContentType returns the content type cloud-init handles the include
+// block of the part as.
+func (p configPartModel) includeContentType() string {
+	if p.includeOnce() {
+		return "text/x-include-once-url"
+	}
+
+	return "text/x-include-url"
+}
+
+// includeBody returns the content of the include block of the part, the
+// #include or #include-once marker followed by a URL per line.
+func (p configPartModel) includeBody() []byte {
+	marker := "#include"
+	if p.includeOnce() {
+		marker = "#include-once"
+	}
+
+	return []byte(strings.Join(append([]string{marker}, p.includeURLs()...), "\n") + "\n")
+}
+
+// includeKnown returns whether an include block and the values in it are known.
+func includeKnown(include types.Object) bool {
+	if include.IsUnknown() {
+		return false
+	}
+
+	for _, value := range include.Attributes() {
+		if value.IsUnknown() {
+			return false
+		}
+
+		if list, ok := value.(types.List); ok {
+			for _, element := range list.Elements() {
+				if element.IsUnknown() {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// validateIncludeParts checks the URLs of include blocks, which cloud-init
+// fetches on boot, and that the content type of the part handles them.
+// Plaintext HTTP URLs are reported with a warning.
+func (c configModel) validateIncludeParts(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.Parts.IsNull() || c.Parts.IsUnknown() {
+		return diags
+	}
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, part := range configParts {
+		if part.Include.IsNull() || !includeKnown(part.Include) {
+			continue
+		}
+
+		includePath := path.Root("part").AtListIndex(i).AtName("include")
+		urls := part.includeURLs()
+
+		if len(urls) == 0 {
+			diags.AddAttributeError(
+				includePath.AtName("urls"),
+				"Invalid Attribute Value",
+				fmt.Sprintf("Expected urls in the include of part %d to have at least one URL.", i),
+			)
+		}
+
+		for j, includeURL := range urls {
+			urlPath := includePath.AtName("urls").AtListIndex(j)
+
+			scheme, err := parseIncludeURL(includeURL)
+			if err != nil {
+				diags.AddAttributeError(
+					urlPath,
+					"Invalid Include URL",
+					endSentence(fmt.Sprintf("URL %d of the include of part %d is not valid: %s", j, i, c.contentDetail(err.Error()))),
+				)
+				continue
+			}
+
+			if scheme == "http" {
+				diags.AddAttributeWarning(
+					urlPath,
+					"Insecure Include URL",
+					fmt.Sprintf("URL %d of the include of part %d uses plaintext HTTP, so its content can be read or tampered "+
+						"with in transit, and cloud-init handles whatever it receives as user data. Use an https URL instead.", j, i),
+				)
+			}
+		}
+
+		if part.ContentType.IsNull() || part.ContentType.IsUnknown() {
+			continue
+		}
+
+		mediaType, _, err := mime.ParseMediaType(part.ContentType.ValueString())
+		if err != nil {
+			mediaType = part.ContentType.ValueString()
+		}
+
+		if !strings.EqualFold(mediaType, part.includeContentType()) {
+			diags.AddAttributeError(
+				path.Root("part").AtListIndex(i).AtName("content_type"),
+				"Invalid Attribute Combination",
+				fmt.Sprintf("Expected content_type to be %q when include is set, got %q.", part.includeContentType(), part.ContentType.ValueString()),
+			)
+		}
+	}
+
+	return diags
+}
+
+// parseIncludeURL checks that rawURL is an absolute URL cloud-init can fetch,
+// returning its scheme. cloud-init reads includes a URL per line, so URLs with
+// whitespace are rejected as well.
+func parseIncludeURL(rawURL string) (string, error) {
+	if strings.ContainsAny(rawURL, " \t\r\n") {
+		return "", fmt.Errorf("%q contains whitespace", rawURL)
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if !containsFold(includeSchemes, scheme) {
+		return "", fmt.Errorf("expected %q to be an absolute URL with one of the schemes %s", rawURL, strings.Join(includeSchemes, ", "))
+	}
+
+	if scheme != "file" && parsed.Host == "" {
+		return "", fmt.Errorf("expected %q to have a host", rawURL)
+	}
+
+	return scheme, nil
+}
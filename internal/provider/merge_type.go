@@ -0,0 +1,125 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+)
+
+// validateMergeTypes checks the merge_type of every part, and the inline
+// merge_how key of text/cloud-config parts, against the mergers cloud-init
+// provides. cloud-init silently ignores unknown options, so a misspelled option
+// would otherwise only show as unexpected merging on boot.
+func (c configModel) validateMergeTypes(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.Parts.IsNull() || c.Parts.IsUnknown() {
+		return diags
+	}
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	for i, part := range configParts {
+		var mergers []cloudconfig.Merger
+
+		if !part.MergeType.IsNull() && !part.MergeType.IsUnknown() {
+			var err error
+
+			mergers, err = parseMergers(part.MergeType.ValueString())
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root("part").AtListIndex(i).AtName("merge_type"),
+					"Invalid Attribute Value",
+					endSentence(fmt.Sprintf("Invalid merge_type %q: %s", part.MergeType.ValueString(), err)),
+				)
+				continue
+			}
+		}
+
+		if part.ContentType.ValueString() != "text/cloud-config" || !part.bodyKnown() {
+			continue
+		}
+
+		body, err := part.body()
+		if err != nil || bytes.HasPrefix(body, []byte("## template: jinja")) {
+			continue
+		}
+
+		inlineMergers, err := cloudconfig.InlineMergers(string(body))
+		if err == nil {
+			err = validateMergers(inlineMergers)
+		}
+		if err != nil {
+			diags.AddAttributeError(
+				part.contentPath(i),
+				"Invalid cloud-config Content",
+				endSentence(fmt.Sprintf("The content of part %d has an invalid merge_how key: %s", i, c.contentDetail(err.Error()))),
+			)
+			continue
+		}
+
+		if len(mergers) > 0 && len(inlineMergers) > 0 && !reflect.DeepEqual(mergers, inlineMergers) {
+			diags.AddAttributeWarning(
+				path.Root("part").AtListIndex(i).AtName("merge_type"),
+				"Conflicting Merge Type",
+				fmt.Sprintf("The content of part %d sets merge_how to %q, which cloud-init uses before merge_type %q. The mergers "+
+					"of merge_type only apply to types the mergers of merge_how do not handle.",
+					i, formatMergers(inlineMergers), part.MergeType.ValueString()),
+			)
+		}
+	}
+
+	return diags
+}
+
+func parseMergers(mergeType string) ([]cloudconfig.Merger, error) {
+	mergers, err := cloudconfig.ParseMergeType(mergeType)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergers, validateMergers(mergers)
+}
+
+func validateMergers(mergers []cloudconfig.Merger) error {
+	for _, merger := range mergers {
+		if err := merger.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatMergers(mergers []cloudconfig.Merger) string {
+	names := make([]string, 0, len(mergers))
+	for _, merger := range mergers {
+		names = append(names, merger.String())
+	}
+
+	return strings.Join(names, "+")
+}
+
+// endSentence adds a period to a diagnostic detail ending in an error message,
+// unless the message already ends with a question.
+func endSentence(detail string) string {
+	if strings.HasSuffix(detail, "?") {
+		return detail
+	}
+
+	return detail + "."
+}
@@ -0,0 +1,472 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/networkconfig"
+)
+
+type networkConfigModel struct {
+	ID        types.String `tfsdk:"id"`
+	Version   types.Int64  `tfsdk:"version"`
+	Ethernets types.List   `tfsdk:"ethernet"` // networkEthernetModel
+	Bonds     types.List   `tfsdk:"bond"`     // networkBondModel
+	Bridges   types.List   `tfsdk:"bridge"`   // networkBridgeModel
+	VLANs     types.List   `tfsdk:"vlan"`     // networkVLANModel
+	Rendered  types.String `tfsdk:"rendered"`
+}
+
+// networkInterfaceModel holds the attributes every kind of interface block has.
+type networkInterfaceModel struct {
+	Name          types.String `tfsdk:"name"`
+	Addresses     types.List   `tfsdk:"addresses"` // types.String
+	DHCP4         types.Bool   `tfsdk:"dhcp4"`
+	DHCP6         types.Bool   `tfsdk:"dhcp6"`
+	MTU           types.Int64  `tfsdk:"mtu"`
+	Nameservers   types.List   `tfsdk:"nameservers"`    // types.String
+	SearchDomains types.List   `tfsdk:"search_domains"` // types.String
+	Routes        types.List   `tfsdk:"route"`          // networkRouteModel
+}
+
+type networkRouteModel struct {
+	To     types.String `tfsdk:"to"`
+	Via    types.String `tfsdk:"via"`
+	Metric types.Int64  `tfsdk:"metric"`
+}
+
+type networkEthernetModel struct {
+	networkInterfaceModel
+	MACAddress types.String `tfsdk:"mac_address"`
+}
+
+type networkBondModel struct {
+	networkInterfaceModel
+	Interfaces         types.List   `tfsdk:"interfaces"` // types.String
+	Mode               types.String `tfsdk:"mode"`
+	MIIMonitorInterval types.Int64  `tfsdk:"mii_monitor_interval"`
+	Primary            types.String `tfsdk:"primary"`
+}
+
+type networkBridgeModel struct {
+	networkInterfaceModel
+	Interfaces types.List `tfsdk:"interfaces"` // types.String
+	STP        types.Bool `tfsdk:"stp"`
+}
+
+type networkVLANModel struct {
+	networkInterfaceModel
+	ID   types.Int64  `tfsdk:"id"`
+	Link types.String `tfsdk:"link"`
+}
+
+// networkInterface is an interface block of any kind, with the path of its block.
+type networkInterface struct {
+	kind  string
+	path  path.Path
+	model networkInterfaceModel
+}
+
+// networkInterfaces reads the interface blocks of every kind.
+func (c networkConfigModel) networkInterfaces(ctx context.Context) ([]networkEthernetModel, []networkBondModel, []networkBridgeModel, []networkVLANModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var ethernets []networkEthernetModel
+	var bonds []networkBondModel
+	var bridges []networkBridgeModel
+	var vlans []networkVLANModel
+
+	if !c.Ethernets.IsNull() && !c.Ethernets.IsUnknown() {
+		diags.Append(c.Ethernets.ElementsAs(ctx, &ethernets, false)...)
+	}
+
+	if !c.Bonds.IsNull() && !c.Bonds.IsUnknown() {
+		diags.Append(c.Bonds.ElementsAs(ctx, &bonds, false)...)
+	}
+
+	if !c.Bridges.IsNull() && !c.Bridges.IsUnknown() {
+		diags.Append(c.Bridges.ElementsAs(ctx, &bridges, false)...)
+	}
+
+	if !c.VLANs.IsNull() && !c.VLANs.IsUnknown() {
+		diags.Append(c.VLANs.ElementsAs(ctx, &vlans, false)...)
+	}
+
+	return ethernets, bonds, bridges, vlans, diags
+}
+
+// validate checks the addresses, MAC addresses and routes of every interface,
+// and that bonds, bridges and VLANs only use interfaces of the kinds they can
+// use, which cloud-init and netplan otherwise only report on boot.
+func (c networkConfigModel) validate(ctx context.Context) diag.Diagnostics {
+	ethernets, bonds, bridges, vlans, diags := c.networkInterfaces(ctx)
+	if diags.HasError() {
+		return diags
+	}
+
+	var interfaces []networkInterface
+
+	for i, ethernet := range ethernets {
+		interfaces = append(interfaces, networkInterface{"ethernet", path.Root("ethernet").AtListIndex(i), ethernet.networkInterfaceModel})
+
+		if !ethernet.MACAddress.IsNull() && !ethernet.MACAddress.IsUnknown() {
+			if err := networkconfig.ValidateMACAddress(ethernet.MACAddress.ValueString()); err != nil {
+				diags.AddAttributeError(
+					path.Root("ethernet").AtListIndex(i).AtName("mac_address"),
+					"Invalid Attribute Value",
+					endSentence(fmt.Sprintf("Invalid MAC address %q: %s", ethernet.MACAddress.ValueString(), err)),
+				)
+			}
+		}
+	}
+
+	for i, bond := range bonds {
+		interfaces = append(interfaces, networkInterface{"bond", path.Root("bond").AtListIndex(i), bond.networkInterfaceModel})
+	}
+
+	for i, bridge := range bridges {
+		interfaces = append(interfaces, networkInterface{"bridge", path.Root("bridge").AtListIndex(i), bridge.networkInterfaceModel})
+	}
+
+	for i, vlan := range vlans {
+		interfaces = append(interfaces, networkInterface{"vlan", path.Root("vlan").AtListIndex(i), vlan.networkInterfaceModel})
+	}
+
+	// kinds maps the name of every interface to its kind, unless a name is
+	// unknown, in which case references cannot be checked.
+	kinds := make(map[string]string)
+
+	for _, iface := range interfaces {
+		diags.Append(iface.validate(ctx)...)
+
+		if kinds == nil || iface.model.Name.IsUnknown() {
+			kinds = nil
+			continue
+		}
+
+		name := iface.model.Name.ValueString()
+		if kind, ok := kinds[name]; ok {
+			diags.AddAttributeError(
+				iface.path.AtName("name"),
+				"Invalid Attribute Value",
+				fmt.Sprintf("Duplicate interface name %q, which is also the name of an interface of the %s blocks.", name, kind),
+			)
+			continue
+		}
+
+		kinds[name] = iface.kind
+	}
+
+	if kinds == nil || diags.HasError() {
+		return diags
+	}
+
+	// members maps the name of every interface in a bond or bridge to the
+	// bond or bridge, as an interface can only be in one of them.
+	members := make(map[string]string)
+
+	checkReferences := func(attributePath path.Path, owner string, list types.List, allowed ...string) []string {
+		if list.IsNull() || list.IsUnknown() {
+			return nil
+		}
+
+		var names []types.String
+		diags.Append(list.ElementsAs(ctx, &names, false)...)
+
+		var result []string
+		known := true
+
+		for j, name := range names {
+			if name.IsUnknown() {
+				known = false
+				continue
+			}
+
+			diags.Append(checkNetworkReference(attributePath.AtListIndex(j), kinds, name.ValueString(), allowed...)...)
+
+			if member, ok := members[name.ValueString()]; ok {
+				diags.AddAttributeError(
+					attributePath.AtListIndex(j),
+					"Invalid Attribute Value",
+					fmt.Sprintf("Interface %q is already in %q. An interface can only be in one bond or bridge.", name.ValueString(), member),
+				)
+			}
+
+			members[name.ValueString()] = owner
+			result = append(result, name.ValueString())
+		}
+
+		// The names are only returned when they are all known.
+		if !known {
+			return nil
+		}
+
+		return result
+	}
+
+	for i, bond := range bonds {
+		bondPath := path.Root("bond").AtListIndex(i)
+		names := checkReferences(bondPath.AtName("interfaces"), bond.Name.ValueString(), bond.Interfaces, "ethernet")
+
+		if !bond.Primary.IsNull() && !bond.Primary.IsUnknown() && names != nil && !slices.Contains(names, bond.Primary.ValueString()) {
+			diags.AddAttributeError(
+				bondPath.AtName("primary"),
+				"Invalid Attribute Value",
+				fmt.Sprintf("Expected primary to be one of the interfaces of the bond, got %q.", bond.Primary.ValueString()),
+			)
+		}
+	}
+
+	for i, bridge := range bridges {
+		checkReferences(path.Root("bridge").AtListIndex(i).AtName("interfaces"), bridge.Name.ValueString(), bridge.Interfaces, "ethernet", "bond", "vlan")
+	}
+
+	for i, vlan := range vlans {
+		if !vlan.Link.IsNull() && !vlan.Link.IsUnknown() {
+			diags.Append(checkNetworkReference(path.Root("vlan").AtListIndex(i).AtName("link"), kinds, vlan.Link.ValueString(), "ethernet", "bond")...)
+		}
+	}
+
+	return diags
+}
+
+// checkNetworkReference checks that name is the name of an interface of one of
+// the allowed kinds.
+func checkNetworkReference(attributePath path.Path, kinds map[string]string, name string, allowed ...string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	blocks := allowed[len(allowed)-1] + " blocks"
+	if len(allowed) > 1 {
+		blocks = strings.Join(allowed[:len(allowed)-1], ", ") + " or " + blocks
+	}
+
+	kind, ok := kinds[name]
+	if !ok {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid Attribute Value",
+			fmt.Sprintf("Expected the name of an interface of the %s, got %q, which is not the name of any interface.", blocks, name),
+		)
+		return diags
+	}
+
+	if !slices.Contains(allowed, kind) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid Attribute Value",
+			fmt.Sprintf("Expected the name of an interface of the %s, got %q, which is a %s.", blocks, name, kind),
+		)
+	}
+
+	return diags
+}
+
+// validate checks the name, addresses, nameservers and routes of an interface.
+func (i networkInterface) validate(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !i.model.Name.IsUnknown() {
+		if err := networkconfig.ValidateInterfaceName(i.model.Name.ValueString()); err != nil {
+			diags.AddAttributeError(
+				i.path.AtName("name"),
+				"Invalid Attribute Value",
+				endSentence(fmt.Sprintf("Invalid interface name %q: %s", i.model.Name.ValueString(), err)),
+			)
+		}
+	}
+
+	forEachString := func(name string, list types.List, check func(string) error) {
+		if list.IsNull() || list.IsUnknown() {
+			return
+		}
+
+		var values []types.String
+		diags.Append(list.ElementsAs(ctx, &values, false)...)
+
+		for j, value := range values {
+			if value.IsUnknown() {
+				continue
+			}
+
+			if err := check(value.ValueString()); err != nil {
+				diags.AddAttributeError(i.path.AtName(name).AtListIndex(j), "Invalid Attribute Value", endSentence(err.Error()))
+			}
+		}
+	}
+
+	forEachString("addresses", i.model.Addresses, func(address string) error {
+		if _, err := networkconfig.ParseAddress(address); err != nil {
+			return fmt.Errorf("Invalid address %q: %w", address, err)
+		}
+		return nil
+	})
+
+	forEachString("nameservers", i.model.Nameservers, func(address string) error {
+		if _, err := networkconfig.ParseIP(address); err != nil {
+			return fmt.Errorf("Invalid nameserver %q: %w", address, err)
+		}
+		return nil
+	})
+
+	if i.model.Routes.IsNull() || i.model.Routes.IsUnknown() {
+		return diags
+	}
+
+	var routes []networkRouteModel
+	diags.Append(i.model.Routes.ElementsAs(ctx, &routes, false)...)
+
+	for j, route := range routes {
+		routePath := i.path.AtName("route").AtListIndex(j)
+
+		if route.Via.IsUnknown() {
+			continue
+		}
+
+		via, err := networkconfig.ParseIP(route.Via.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				routePath.AtName("via"),
+				"Invalid Attribute Value",
+				endSentence(fmt.Sprintf("Invalid gateway %q: %s", route.Via.ValueString(), err)),
+			)
+			continue
+		}
+
+		if route.To.IsUnknown() {
+			continue
+		}
+
+		if _, err := networkconfig.ParseDestination(route.To.ValueString(), via); err != nil {
+			diags.AddAttributeError(
+				routePath.AtName("to"),
+				"Invalid Attribute Value",
+				endSentence(fmt.Sprintf("Invalid destination %q: %s", route.To.ValueString(), err)),
+			)
+		}
+	}
+
+	return diags
+}
+
+// update renders the network configuration.
+func (c *networkConfigModel) update(ctx context.Context) diag.Diagnostics {
+	if c.Version.IsNull() {
+		c.Version = types.Int64Value(networkconfig.Version2)
+	}
+
+	ethernets, bonds, bridges, vlans, diags := c.networkInterfaces(ctx)
+	if diags.HasError() {
+		return diags
+	}
+
+	var config networkconfig.Config
+
+	for _, ethernet := range ethernets {
+		iface, ifaceDiags := ethernet.networkInterfaceModel.config(ctx)
+		diags.Append(ifaceDiags...)
+
+		config.Ethernets = append(config.Ethernets, networkconfig.Ethernet{
+			Interface:  iface,
+			MACAddress: ethernet.MACAddress.ValueString(),
+		})
+	}
+
+	for _, bond := range bonds {
+		iface, ifaceDiags := bond.networkInterfaceModel.config(ctx)
+		diags.Append(ifaceDiags...)
+
+		var interfaces []string
+		diags.Append(bond.Interfaces.ElementsAs(ctx, &interfaces, false)...)
+
+		config.Bonds = append(config.Bonds, networkconfig.Bond{
+			Interface:          iface,
+			Interfaces:         interfaces,
+			Mode:               bond.Mode.ValueString(),
+			MIIMonitorInterval: bond.MIIMonitorInterval.ValueInt64(),
+			Primary:            bond.Primary.ValueString(),
+		})
+	}
+
+	for _, bridge := range bridges {
+		iface, ifaceDiags := bridge.networkInterfaceModel.config(ctx)
+		diags.Append(ifaceDiags...)
+
+		var interfaces []string
+		diags.Append(bridge.Interfaces.ElementsAs(ctx, &interfaces, false)...)
+
+		var stp *bool
+		if !bridge.STP.IsNull() {
+			stp = bridge.STP.ValueBoolPointer()
+		}
+
+		config.Bridges = append(config.Bridges, networkconfig.Bridge{
+			Interface:  iface,
+			Interfaces: interfaces,
+			STP:        stp,
+		})
+	}
+
+	for _, vlan := range vlans {
+		iface, ifaceDiags := vlan.networkInterfaceModel.config(ctx)
+		diags.Append(ifaceDiags...)
+
+		config.VLANs = append(config.VLANs, networkconfig.VLAN{
+			Interface: iface,
+			ID:        vlan.ID.ValueInt64(),
+			Link:      vlan.Link.ValueString(),
+		})
+	}
+
+	if diags.HasError() {
+		return diags
+	}
+
+	rendered, err := networkconfig.Marshal(config, int(c.Version.ValueInt64()))
+	if err != nil {
+		diags.AddError("Unable to render network configuration", err.Error())
+		return diags
+	}
+
+	c.Rendered = types.StringValue(string(rendered))
+	c.ID = types.StringValue(hashcode.SHA256(string(rendered)))
+
+	return diags
+}
+
+// config returns the settings of the interface for rendering.
+func (m networkInterfaceModel) config(ctx context.Context) (networkconfig.Interface, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	iface := networkconfig.Interface{
+		Name:  m.Name.ValueString(),
+		DHCP4: m.DHCP4.ValueBool(),
+		DHCP6: m.DHCP6.ValueBool(),
+		MTU:   m.MTU.ValueInt64(),
+	}
+
+	diags.Append(m.Addresses.ElementsAs(ctx, &iface.Addresses, false)...)
+	diags.Append(m.Nameservers.ElementsAs(ctx, &iface.Nameservers, false)...)
+	diags.Append(m.SearchDomains.ElementsAs(ctx, &iface.SearchDomains, false)...)
+
+	var routes []networkRouteModel
+	diags.Append(m.Routes.ElementsAs(ctx, &routes, false)...)
+
+	for _, route := range routes {
+		iface.Routes = append(iface.Routes, networkconfig.Route{
+			To:     route.To.ValueString(),
+			Via:    route.Via.ValueString(),
+			Metric: route.Metric.ValueInt64(),
+		})
+	}
+
+	return iface, diags
+}
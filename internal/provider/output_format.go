@@ -0,0 +1,169 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+)
+
+const (
+	// outputFormatMIME renders the parts as a multi-part MIME message, the
+	// default.
+	outputFormatMIME = "mime"
+
+	// outputFormatRaw renders the content of a single part as is, for platforms
+	// and images which do not handle multi-part MIME well.
+	outputFormatRaw = "raw"
+
+	// outputFormatArchive renders the parts as a cloud-config-archive document.
+	outputFormatArchive = "archive"
+)
+
+// outputFormats returns the supported values of output_format.
+func outputFormats() []string {
+	return []string{outputFormatMIME, outputFormatRaw, outputFormatArchive}
+}
+
+// outputFormat returns the output format, which defaults to MIME.
+func (c configModel) outputFormat() string {
+	if c.OutputFormat.IsNull() {
+		return outputFormatMIME
+	}
+
+	return c.OutputFormat.ValueString()
+}
+
+// renderDocumentToWriter renders the parts in the output format, before gzip
+// compression and base64 encoding. cloud-config-archive parts are expanded into
+// a part per entry when expandsArchiveParts says so.
+func (c configModel) renderDocumentToWriter(ctx context.Context, parts []configPartModel, writer io.Writer) error {
+	if c.outputFormat() == outputFormatRaw {
+		return renderRawPartToWriter(parts, writer)
+	}
+
+	if c.expandsArchiveParts() {
+		var err error
+		if parts, err = expandArchiveParts(parts); err != nil {
+			return err
+		}
+	}
+
+	if c.outputFormat() == outputFormatArchive {
+		return renderArchiveToWriter(parts, writer)
+	}
+
+	return renderPartsToWriter(ctx, c.mimeBoundary(parts), parts, writer)
+}
+
+// renderRawPartToWriter writes the content of the only part, which cloud-init
+// recognizes by its leading marker rather than a header.
+func renderRawPartToWriter(parts []configPartModel, writer io.Writer) error {
+	if len(parts) != 1 {
+		return fmt.Errorf("expected exactly one part for the raw output format, got %d", len(parts))
+	}
+
+	body, err := parts[0].body()
+	if err != nil {
+		return fmt.Errorf("part 0: %w", err)
+	}
+
+	_, err = writer.Write(body)
+
+	return err
+}
+
+// renderArchiveToWriter writes the parts as a cloud-config-archive document.
+func renderArchiveToWriter(parts []configPartModel, writer io.Writer) error {
+	entries := make([]cloudconfig.ArchiveEntry, 0, len(parts))
+
+	for i, part := range parts {
+		body, err := part.body()
+		if err != nil {
+			return fmt.Errorf("part %d: %w", i, err)
+		}
+
+		entries = append(entries, cloudconfig.ArchiveEntry{
+			Type:        part.ContentType.ValueString(),
+			Filename:    part.FileName.ValueString(),
+			MergeType:   part.MergeType.ValueString(),
+			Content:     body,
+			LaunchIndex: part.launchIndex,
+		})
+	}
+
+	document, err := cloudconfig.MarshalArchive(entries)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(document)
+
+	return err
+}
+
+// validateOutputFormat checks that the parts can be rendered in the raw output
+// format, which has no headers: there must be a single part, and cloud-init
+// must infer its content type from the content.
+func (c configModel) validateOutputFormat(ctx context.Context) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.OutputFormat.IsUnknown() || c.outputFormat() != outputFormatRaw || c.Parts.IsNull() || c.Parts.IsUnknown() {
+		return diags
+	}
+
+	var configParts []configPartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	if len(configParts) != 1 {
+		diags.AddAttributeError(
+			path.Root("output_format"),
+			"Invalid Attribute Configuration",
+			fmt.Sprintf("Expected exactly one part when output_format is %q, got %d. Use the %q or %q output format for more parts.",
+				outputFormatRaw, len(configParts), outputFormatMIME, outputFormatArchive),
+		)
+		return diags
+	}
+
+	part := configParts[0]
+
+	if !part.FileName.IsNull() || !part.MergeType.IsNull() {
+		diags.AddAttributeWarning(
+			path.Root("output_format"),
+			"Part Headers Not Rendered",
+			fmt.Sprintf("The %q output format has no headers, so the filename and merge_type of the part are left out. "+
+				"A text/cloud-config part can set its mergers with the merge_how key instead.", outputFormatRaw),
+		)
+	}
+
+	if part.ContentType.IsUnknown() || !part.bodyKnown() {
+		return diags
+	}
+
+	body, err := part.body()
+	if err != nil {
+		return diags
+	}
+
+	if inferred := inferContentType(body); part.ContentType.ValueString() != inferred {
+		diags.AddAttributeError(
+			path.Root("part").AtListIndex(0).AtName("content_type"),
+			"Invalid Attribute Configuration",
+			fmt.Sprintf("The %q output format has no headers, so cloud-init infers the content type from the content, which "+
+				"gives %q rather than %q. Start the content with the marker of the content type, or use the %q output format.",
+				outputFormatRaw, inferred, part.ContentType.ValueString(), outputFormatMIME),
+		)
+	}
+
+	return diags
+}
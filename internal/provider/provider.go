@@ -7,13 +7,17 @@ import (
 	"context"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 )
 
 var (
-	_ provider.Provider = (*cloudinitProvider)(nil)
+	_ provider.Provider                       = (*cloudinitProvider)(nil)
+	_ provider.ProviderWithFunctions          = (*cloudinitProvider)(nil)
+	_ provider.ProviderWithEphemeralResources = (*cloudinitProvider)(nil)
 )
 
 type cloudinitProvider struct{}
@@ -43,6 +47,12 @@ func (p *cloudinitProvider) Resources(ctx context.Context) []func() resource.Res
 		func() resource.Resource {
 			return &configResource{}
 		},
+		func() resource.Resource {
+			return &noCloudSeedResource{}
+		},
+		func() resource.Resource {
+			return &configDriveResource{}
+		},
 	}
 }
 
@@ -51,5 +61,39 @@ func (p *cloudinitProvider) DataSources(ctx context.Context) []func() datasource
 		func() datasource.DataSource {
 			return &configDataSource{}
 		},
+		func() datasource.DataSource {
+			return &networkConfigDataSource{}
+		},
+		func() datasource.DataSource {
+			return &metaDataDataSource{}
+		},
+		func() datasource.DataSource {
+			return &vmwareGuestInfoDataSource{}
+		},
+		func() datasource.DataSource {
+			return &jinjaPreviewDataSource{}
+		},
+	}
+}
+
+func (p *cloudinitProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		func() ephemeral.EphemeralResource {
+			return &configEphemeralResource{}
+		},
+	}
+}
+
+func (p *cloudinitProvider) Functions(ctx context.Context) []func() function.Function {
+	return []func() function.Function{
+		func() function.Function {
+			return &renderFunction{}
+		},
+		func() function.Function {
+			return &decodeFunction{}
+		},
+		func() function.Function {
+			return &mergeCloudConfigFunction{}
+		},
 	}
 }
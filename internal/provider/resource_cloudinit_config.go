@@ -5,17 +5,28 @@ package provider
 
 import (
 	"context"
+	"maps"
+	"slices"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
 )
 
 var (
@@ -24,6 +35,14 @@ var (
 
 type configResource struct{}
 
+// configResourcePartModel is a part of the resource, which unlike the data
+// source supports write-only content.
+type configResourcePartModel struct {
+	configPartModel
+	ContentWO        types.String `tfsdk:"content_wo"`
+	ContentWOVersion types.Int64  `tfsdk:"content_wo_version"`
+}
+
 func (r *configResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_config"
 }
@@ -36,59 +55,53 @@ func (r *configResource) ValidateConfig(ctx context.Context, req resource.Valida
 		return
 	}
 
-	resp.Diagnostics.Append(cloudinitConfig.validate(ctx)...)
+	sharedConfig, _, diags := resourceConfigModel(ctx, cloudinitConfig, cloudinitConfig.Parts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(sharedConfig.validate(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(sharedConfig.validatePlatformLimit(ctx)...)
 }
 
 func (r *configResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	contentAttributes := append(slices.Clone(partContentAttributes), "content_wo")
+
 	resp.Schema = schema.Schema{
 		Blocks: map[string]schema.Block{
 			"part": schema.ListNestedBlock{
 				Validators: []validator.List{
 					listvalidator.IsRequired(),
 				},
-				NestedObject: schema.NestedBlockObject{
-					Attributes: map[string]schema.Attribute{
-						"content_type": schema.StringAttribute{
-							Validators: []validator.String{
-								stringvalidator.LengthAtLeast(1),
-							},
-							PlanModifiers: []planmodifier.String{
-								stringplanmodifier.RequiresReplace(),
-							},
-							Optional:            true,
-							Computed:            true,
-							Default:             stringdefault.StaticString("text/plain"),
-							MarkdownDescription: "A MIME-style content type to report in the header for the part. Defaults to `text/plain`",
-						},
-						"content": schema.StringAttribute{
-							PlanModifiers: []planmodifier.String{
-								stringplanmodifier.RequiresReplace(),
-							},
-							Required:            true,
-							MarkdownDescription: "Body content for the part.",
-						},
-						"filename": schema.StringAttribute{
-							PlanModifiers: []planmodifier.String{
-								stringplanmodifier.RequiresReplace(),
-							},
-							Optional:            true,
-							MarkdownDescription: "A filename to report in the header for the part.",
+				NestedObject: resourcePartObject(contentAttributes, map[string]schema.Attribute{
+					"content_wo": schema.StringAttribute{
+						Optional:  true,
+						WriteOnly: true,
+						MarkdownDescription: "Write-only body content for the part, for content holding secrets. It is rendered like `content`, but " +
+							"never stored in the plan or state, and `rendered` is then `null` with only its digests and sizes stored. Changes " +
+							"cannot be detected, so change `content_wo_version` to render the part again. " + exactlyOneOfDescription(contentAttributes) +
+							" Requires Terraform 1.11 or later.",
+					},
+					"content_wo_version": schema.Int64Attribute{
+						Validators: []validator.Int64{
+							int64validator.AlsoRequires(path.MatchRelative().AtParent().AtName("content_wo")),
 						},
-						"merge_type": schema.StringAttribute{
-							PlanModifiers: []planmodifier.String{
-								stringplanmodifier.RequiresReplace(),
-							},
-							Optional: true,
-							MarkdownDescription: "A value for the `X-Merge-Type` header of the part, to control " +
-								"[cloud-init merging behavior](https://cloudinit.readthedocs.io/en/latest/reference/merging.html).",
+						PlanModifiers: []planmodifier.Int64{
+							int64planmodifier.RequiresReplace(),
 						},
+						Optional:            true,
+						MarkdownDescription: "A version for `content_wo`. Changing it replaces the resource, rendering the part with the current `content_wo`.",
 					},
-				},
-				MarkdownDescription: "A nested block type which adds a file to the generated cloud-init configuration. Use multiple " +
-					"`part` blocks to specify multiple files, which will be included in order of declaration in the final MIME document.",
+				}),
+				MarkdownDescription: partBlockDescription,
 			},
 		},
-		Attributes: map[string]schema.Attribute{
+		Attributes: resourceRenderAttributes(map[string]schema.Attribute{
 			"gzip": schema.BoolAttribute{
 				PlanModifiers: []planmodifier.Bool{
 					boolplanmodifier.RequiresReplace(),
@@ -96,7 +109,7 @@ func (r *configResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Optional:            true,
 				Computed:            true,
 				Default:             booldefault.StaticBool(true),
-				MarkdownDescription: "Specify whether or not to gzip the `rendered` output. Defaults to `true`.",
+				MarkdownDescription: gzipDescription,
 			},
 			"base64_encode": schema.BoolAttribute{
 				PlanModifiers: []planmodifier.Bool{
@@ -105,36 +118,87 @@ func (r *configResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Optional:            true,
 				Computed:            true,
 				Default:             booldefault.StaticBool(true),
-				MarkdownDescription: "Specify whether or not to base64 encode the `rendered` output. Defaults to `true`, and cannot be disabled if gzip is `true`.",
+				MarkdownDescription: base64EncodeDescription,
 			},
-			"boundary": schema.StringAttribute{
+			"target_platform": schema.StringAttribute{
 				Validators: []validator.String{
-					stringvalidator.LengthAtLeast(1),
+					stringvalidator.OneOf(targetPlatforms()...),
 				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.RequiresReplace(),
 				},
 				Optional:            true,
-				Computed:            true,
-				Default:             stringdefault.StaticString("MIMEBOUNDARY"),
-				MarkdownDescription: "Specify the Writer's default boundary separator. Defaults to `MIMEBOUNDARY`.",
+				MarkdownDescription: targetPlatformDescription,
+			},
+			"output_format": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormats()...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional:            true,
+				MarkdownDescription: outputFormatDescription,
+			},
+			"expand_archive_parts": schema.BoolAttribute{
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+				Optional:            true,
+				MarkdownDescription: expandArchivePartsDescription,
+			},
+			"sensitive_output": schema.BoolAttribute{
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+				Optional:            true,
+				MarkdownDescription: sensitiveOutputDescription,
 			},
 			"rendered": schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "The final rendered multi-part cloud-init config. `null` when `sensitive_output` is `true`, or when " +
+					"a part sets `content_wo` so that it is not stored in the state.",
+			},
+			"rendered_sensitive": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: renderedSensitiveDescription,
+			},
+			"mime_size": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: mimeSizeDescription,
+			},
+			"compressed_size": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: compressedSizeDescription,
+			},
+			"rendered_size": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: renderedSizeDescription,
+			},
+			"rendered_sha256": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "The final rendered multi-part cloud-init config.",
+				MarkdownDescription: renderedSHA256Description,
+			},
+			"rendered_sha512": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: renderedSHA512Description,
+			},
+			"rendered_md5": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: renderedMD5Description,
+			},
+			"mime_sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: mimeSHA256Description,
 			},
 			"id": schema.StringAttribute{
 				Computed:            true,
-				MarkdownDescription: "[CRC-32](https://pkg.go.dev/hash/crc32) checksum of `rendered` cloud-init config.",
+				MarkdownDescription: configIDDescription,
 			},
-		},
-		DeprecationMessage: `This resource is deprecated, please use the cloudinit_config data source instead.`,
-		MarkdownDescription: "Renders a [multi-part MIME configuration](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#mime-multi-part-archive) " +
-			"for use with [cloud-init](https://cloudinit.readthedocs.io/en/latest/).\n\n" +
-			"Cloud-init is a commonly-used startup configuration utility for cloud compute instances. It accepts configuration via provider-specific " +
-			"user data mechanisms, such as `user_data` for Amazon EC2 instances. Multi-part MIME is one of the data formats it accepts. For more information, " +
-			"see [User-Data Formats](https://cloudinit.readthedocs.io/en/latest/explanation/format.html) in the cloud-init manual.\n\n" +
-			"This is not a generalized utility for producing multi-part MIME messages. Its feature set is specialized for cloud-init multi-part MIME messages.",
+		}),
+		DeprecationMessage:  `This resource is deprecated, please use the cloudinit_config data source instead.`,
+		MarkdownDescription: configDescription,
 	}
 }
 
@@ -146,7 +210,26 @@ func (r *configResource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	resp.Diagnostics.Append(cloudinitConfig.update(ctx)...)
+	// Write-only content is only available in the configuration
+	var writeOnlyConfig configModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &writeOnlyConfig)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	sharedConfig, resourceParts, diags := resourceConfigModel(ctx, cloudinitConfig, writeOnlyConfig.Parts)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(sharedConfig.update(ctx)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(cloudinitConfig.setFromConfigModel(ctx, sharedConfig, resourceParts)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, cloudinitConfig)...)
 }
 
@@ -158,3 +241,233 @@ func (r *configResource) Update(_ context.Context, _ resource.UpdateRequest, _ *
 
 func (r *configResource) Delete(_ context.Context, _ resource.DeleteRequest, _ *resource.DeleteResponse) {
 }
+
+// resourceConfigModel returns a copy of the resource model c with the parts
+// shared with the data source, taking the content of parts with write-only
+// content from writeOnlyParts, which must come from the configuration. The
+// resource parts are returned as well, for setFromConfigModel.
+func resourceConfigModel(ctx context.Context, c configModel, writeOnlyParts types.List) (configModel, []configResourcePartModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	sharedConfig := c
+	partType := types.ObjectType{AttrTypes: configPartAttrTypes}
+
+	if c.Parts.IsNull() || c.Parts.IsUnknown() || writeOnlyParts.IsUnknown() {
+		sharedConfig.Parts = types.ListUnknown(partType)
+		if c.Parts.IsNull() {
+			sharedConfig.Parts = types.ListNull(partType)
+		}
+
+		return sharedConfig, nil, diags
+	}
+
+	var resourceParts, configParts []configResourcePartModel
+	diags.Append(c.Parts.ElementsAs(ctx, &resourceParts, false)...)
+	diags.Append(writeOnlyParts.ElementsAs(ctx, &configParts, false)...)
+	if diags.HasError() {
+		return sharedConfig, nil, diags
+	}
+
+	parts := make([]configPartModel, 0, len(resourceParts))
+	for i, resourcePart := range resourceParts {
+		part := resourcePart.configPartModel
+		if i < len(configParts) && !configParts[i].ContentWO.IsNull() {
+			part.Content = configParts[i].ContentWO
+			resourceParts[i].ContentWO = configParts[i].ContentWO
+		}
+
+		parts = append(parts, part)
+	}
+
+	partsList, convertDiags := types.ListValueFrom(ctx, partType, parts)
+	diags.Append(convertDiags...)
+
+	sharedConfig.Parts = partsList
+
+	return sharedConfig, resourceParts, diags
+}
+
+// setFromConfigModel sets the resource model c to sharedConfig after update.
+// The write-only content is never stored, and neither is the rendered output
+// when it includes any.
+func (c *configModel) setFromConfigModel(ctx context.Context, sharedConfig configModel, resourceParts []configResourcePartModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var parts []configPartModel
+	diags.Append(sharedConfig.Parts.ElementsAs(ctx, &parts, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	writeOnly := false
+	for i := range resourceParts {
+		resourceParts[i].configPartModel = parts[i]
+
+		if !resourceParts[i].ContentWO.IsNull() {
+			writeOnly = true
+			resourceParts[i].Content = types.StringNull()
+			resourceParts[i].ContentWO = types.StringNull()
+		}
+	}
+
+	partsList, convertDiags := types.ListValueFrom(ctx, c.Parts.ElementType(ctx), resourceParts)
+	diags.Append(convertDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	*c = sharedConfig
+	c.Parts = partsList
+
+	if writeOnly {
+		c.Rendered = types.StringNull()
+		c.RenderedSensitive = types.StringNull()
+	}
+
+	return diags
+}
+
+// resourcePartObject returns the nested object of the part block of the
+// resources rendering parts, with the attributes and blocks of which exactly
+// one must set the content of a part, and the given attributes only the parts
+// of that resource have. Changing a part replaces the resource.
+func resourcePartObject(contentAttributes []string, attributes map[string]schema.Attribute) schema.NestedBlockObject {
+	oneOf := " " + exactlyOneOfDescription(contentAttributes)
+
+	common := map[string]schema.Attribute{
+		"content_type": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+			PlanModifiers: []planmodifier.String{
+				inferContentTypeModifier{},
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			Computed:            true,
+			MarkdownDescription: partContentTypeDescription,
+		},
+		"content": schema.StringAttribute{
+			Validators: []validator.String{
+				exactlyOneOfValidator(contentAttributes),
+			},
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: partContentDescription + oneOf,
+		},
+		"content_base64": schema.StringAttribute{
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: partContentBase64Description + oneOf,
+		},
+		"cloud_config": schema.StringAttribute{
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: partCloudConfigDescription + oneOf,
+		},
+		"filename": schema.StringAttribute{
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: partFilenameDescription,
+		},
+		"merge_type": schema.StringAttribute{
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: partMergeTypeDescription,
+		},
+		"content_size": schema.Int64Attribute{
+			Computed:            true,
+			MarkdownDescription: partContentSizeDescription,
+		},
+	}
+
+	maps.Copy(common, attributes)
+
+	return schema.NestedBlockObject{
+		Blocks: map[string]schema.Block{
+			"include": schema.SingleNestedBlock{
+				PlanModifiers: []planmodifier.Object{
+					objectplanmodifier.RequiresReplace(),
+				},
+				Attributes: map[string]schema.Attribute{
+					"urls": schema.ListAttribute{
+						ElementType:         types.StringType,
+						Optional:            true,
+						MarkdownDescription: partIncludeURLsDescription,
+					},
+					"once": schema.BoolAttribute{
+						Optional:            true,
+						MarkdownDescription: partIncludeOnceDescription,
+					},
+				},
+				MarkdownDescription: partIncludeDescription + oneOf,
+			},
+		},
+		Attributes: common,
+	}
+}
+
+// resourceRenderAttributes returns the attributes configuring how the parts
+// are rendered, which the resources rendering parts share, along with the given
+// attributes of the resource.
+func resourceRenderAttributes(attributes map[string]schema.Attribute) map[string]schema.Attribute {
+	common := map[string]schema.Attribute{
+		"boundary": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.LengthAtLeast(1),
+			},
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			Computed:            true,
+			Default:             stringdefault.StaticString("MIMEBOUNDARY"),
+			MarkdownDescription: boundaryDescription,
+		},
+		"cloud_init_version": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.OneOf(cloudconfig.Versions()...),
+			},
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: cloudInitVersionDescription,
+		},
+		"cloud_config_validation": schema.StringAttribute{
+			Validators: []validator.String{
+				stringvalidator.OneOf(cloudConfigValidations()...),
+			},
+			PlanModifiers: []planmodifier.String{
+				stringplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: cloudConfigValidationDescription,
+		},
+		"custom_content_types": schema.ListAttribute{
+			ElementType: types.StringType,
+			Validators: []validator.List{
+				listvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+			},
+			PlanModifiers: []planmodifier.List{
+				listplanmodifier.RequiresReplace(),
+			},
+			Optional:            true,
+			MarkdownDescription: customContentTypesDescription,
+		},
+	}
+
+	maps.Copy(common, attributes)
+
+	return common
+}
@@ -0,0 +1,338 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/diskimage"
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
+)
+
+var (
+	_ resource.ResourceWithValidateConfig = (*configDriveResource)(nil)
+)
+
+const (
+	// configDriveLabel is the volume label cloud-init looks for OpenStack
+	// config drives with.
+	configDriveLabel = "config-2"
+
+	// configDriveDirectory is the directory of the files of the latest
+	// metadata version, which cloud-init reads first.
+	configDriveDirectory = "openstack/latest/"
+)
+
+type configDriveResource struct{}
+
+type configDriveModel struct {
+	ID                    types.String `tfsdk:"id"`
+	Filename              types.String `tfsdk:"filename"`
+	Parts                 types.List   `tfsdk:"part"` // configPartModel
+	Boundary              types.String `tfsdk:"boundary"`
+	CloudInitVersion      types.String `tfsdk:"cloud_init_version"`
+	CloudConfigValidation types.String `tfsdk:"cloud_config_validation"`
+	CustomContentTypes    types.List   `tfsdk:"custom_content_types"` // types.String
+	OutputFormat          types.String `tfsdk:"output_format"`
+	ExpandArchiveParts    types.Bool   `tfsdk:"expand_archive_parts"`
+	MetaData              types.String `tfsdk:"meta_data"`
+	NetworkData           types.String `tfsdk:"network_data"`
+	VendorData            types.String `tfsdk:"vendor_data"`
+	UserData              types.String `tfsdk:"user_data"`
+	ContentBase64         types.String `tfsdk:"content_base64"`
+	ContentSHA256         types.String `tfsdk:"content_sha256"`
+	Size                  types.Int64  `tfsdk:"size"`
+}
+
+func (r *configDriveResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_config_drive"
+}
+
+func (r *configDriveResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var drive configDriveModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &drive)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(drive.userDataConfig().validate(ctx)...)
+	resp.Diagnostics.Append(drive.validate()...)
+}
+
+func (r *configDriveResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Blocks: map[string]schema.Block{
+			"part": imagePartBlock("A nested block type which adds a file to the `user_data` of the image. Use multiple `part` blocks to " +
+				"specify multiple files, which will be included in order of declaration in the final MIME document."),
+		},
+		Attributes: resourceRenderAttributes(map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The path of a local file to write the image to, such as `config-drive.iso`, creating parent directories as " +
+					"needed. The file is deleted with the resource, and written again when it is missing or changed outside of Terraform. " +
+					"When omitted, the image is only available as `content_base64`.",
+			},
+			"meta_data": schema.StringAttribute{
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Required: true,
+				MarkdownDescription: "The content of the `meta_data.json` file as a JSON object, such as the result of `jsonencode`. " +
+					"Must have the `uuid` of the instance, which cloud-init uses as the `instance-id`, and may have its `hostname`, which " +
+					"cloud-init uses as the `local-hostname`, and `public_keys`, an object of key names and SSH public keys.",
+			},
+			"network_data": schema.StringAttribute{
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The content of the `network_data.json` file as a JSON object, such as the result of `jsonencode`, with the " +
+					"`links`, `networks` and `services` of the [OpenStack network data](https://docs.openstack.org/nova/latest/user/metadata.html#openstack-format-metadata) " +
+					"format. The file is left out when omitted.",
+			},
+			"vendor_data": schema.StringAttribute{
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The content of the `vendor_data.json` file as a JSON object, such as the result of `jsonencode`. " +
+					"cloud-init reads vendor data from its `cloud-init` key. The file is left out when omitted.",
+			},
+			"output_format": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormats()...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The format of the `user_data` file. `mime` renders a multi-part MIME message. `raw` renders the " +
+					"content of the only part as is, and requires exactly one part whose content starts with the marker of its content " +
+					"type, such as `#cloud-config` or `#!`. `archive` renders a " +
+					"[cloud-config-archive](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#cloud-config-archive) document. " +
+					"Defaults to `mime`.",
+			},
+			"expand_archive_parts": schema.BoolAttribute{
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+				Optional:            true,
+				MarkdownDescription: expandArchivePartsDescription,
+			},
+			"user_data": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The content of the `user_data` file, rendered from the parts like the `cloudinit_config` data source does without gzip or base64.",
+			},
+			"content_base64": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The base64 encoded image, for uploading it without a local file.",
+			},
+			"content_sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 digest of the image. The image only depends on the files, so it stays the same as long as they do.",
+			},
+			"size": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "The size in bytes of the image.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The same as `content_sha256`.",
+			},
+		}),
+		MarkdownDescription: "Builds an [OpenStack config drive](https://cloudinit.readthedocs.io/en/latest/reference/datasources/configdrive.html), " +
+			"the ISO 9660 image labelled `config-2` which cloud-init reads `user_data`, `meta_data.json`, `network_data.json` and " +
+			"`vendor_data.json` from in its `openstack/latest` directory, for OpenStack instances without a metadata service and " +
+			"Ironic bare metal nodes.\n\n" +
+			"The `user_data` is rendered from `part` blocks like the `cloudinit_config` data source does, and `meta_data` is checked " +
+			"before the image is built. The image is built in the provider, without `genisoimage` or `mkisofs`, and written to `filename` " +
+			"and exposed as `content_base64`.",
+	}
+}
+
+func (r *configDriveResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var drive configDriveModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &drive)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	image, diags := drive.update(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !drive.Filename.IsNull() {
+		if err := writeImage(drive.Filename.ValueString(), image); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("filename"), "Unable to write config drive image", err.Error())
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, drive)...)
+}
+
+// Read removes the resource when the file is missing or has changed, so that
+// it is written again.
+func (r *configDriveResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var drive configDriveModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &drive)...)
+	if resp.Diagnostics.HasError() || drive.Filename.IsNull() {
+		return
+	}
+
+	image, err := os.ReadFile(drive.Filename.ValueString())
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("filename"), "Unable to read config drive image", err.Error())
+		return
+	}
+
+	if hashcode.SHA256(string(image)) != drive.ContentSHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *configDriveResource) Update(_ context.Context, _ resource.UpdateRequest, _ *resource.UpdateResponse) {
+}
+
+func (r *configDriveResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var drive configDriveModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &drive)...)
+	if resp.Diagnostics.HasError() || drive.Filename.IsNull() {
+		return
+	}
+
+	if err := os.Remove(drive.Filename.ValueString()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddAttributeError(path.Root("filename"), "Unable to delete config drive image", err.Error())
+	}
+}
+
+// userDataConfig returns the config the user_data is rendered from. The image
+// holds the user_data as is, so it is neither gzipped nor base64 encoded.
+func (drive configDriveModel) userDataConfig() configModel {
+	return configModel{
+		Parts:                 drive.Parts,
+		Gzip:                  types.BoolValue(false),
+		Base64Encode:          types.BoolValue(false),
+		Boundary:              drive.Boundary,
+		CloudInitVersion:      drive.CloudInitVersion,
+		CloudConfigValidation: drive.CloudConfigValidation,
+		TargetPlatform:        types.StringNull(),
+		CustomContentTypes:    drive.CustomContentTypes,
+		OutputFormat:          drive.OutputFormat,
+		ExpandArchiveParts:    drive.ExpandArchiveParts,
+		SensitiveOutput:       types.BoolNull(),
+	}
+}
+
+// validate checks meta_data, network_data and vendor_data, which cloud-init
+// fails to read the config drive with when they are invalid. Values unknown
+// while validating the config are checked by update.
+func (drive configDriveModel) validate() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	documents := []struct {
+		name     string
+		value    types.String
+		validate func([]byte) error
+	}{
+		{"meta_data", drive.MetaData, cloudconfig.ValidateConfigDriveMetaData},
+		{"network_data", drive.NetworkData, cloudconfig.ValidateConfigDriveNetworkData},
+		{"vendor_data", drive.VendorData, cloudconfig.ValidateConfigDriveVendorData},
+	}
+
+	for _, document := range documents {
+		if document.value.IsNull() || document.value.IsUnknown() {
+			continue
+		}
+
+		if err := document.validate([]byte(document.value.ValueString())); err != nil {
+			diags.AddAttributeError(
+				path.Root(document.name),
+				"Invalid Attribute Value",
+				endSentence(fmt.Sprintf("Invalid %s: %s", document.name, err)),
+			)
+		}
+	}
+
+	return diags
+}
+
+// update renders the user_data, builds the image and sets the computed
+// attributes. The image is returned for writing to filename.
+func (drive *configDriveModel) update(ctx context.Context) ([]byte, diag.Diagnostics) {
+	diags := drive.validate()
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	userDataConfig := drive.userDataConfig()
+
+	diags.Append(userDataConfig.update(ctx)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	drive.Parts = userDataConfig.Parts
+	drive.UserData = userDataConfig.Rendered
+
+	files := []diskimage.File{
+		{Path: configDriveDirectory + "meta_data.json", Content: []byte(drive.MetaData.ValueString())},
+		{Path: configDriveDirectory + "user_data", Content: []byte(drive.UserData.ValueString())},
+	}
+
+	if !drive.NetworkData.IsNull() {
+		files = append(files, diskimage.File{Path: configDriveDirectory + "network_data.json", Content: []byte(drive.NetworkData.ValueString())})
+	}
+
+	if !drive.VendorData.IsNull() {
+		files = append(files, diskimage.File{Path: configDriveDirectory + "vendor_data.json", Content: []byte(drive.VendorData.ValueString())})
+	}
+
+	image, err := diskimage.ISO9660(configDriveLabel, files)
+	if err != nil {
+		diags.AddError("Unable to build config drive image", err.Error())
+		return nil, diags
+	}
+
+	drive.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(image))
+	drive.ContentSHA256 = types.StringValue(hashcode.SHA256(string(image)))
+	drive.Size = types.Int64Value(int64(len(image)))
+	drive.ID = drive.ContentSHA256
+
+	return image, diags
+}
@@ -0,0 +1,140 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestConfigDriveResource(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "drive", "config-drive.iso")
+
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: fmt.Sprintf(`resource "cloudinit_config_drive" "foo" {
+					filename  = %q
+					meta_data = jsonencode({
+						uuid     = "0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+						hostname = "web-01"
+					})
+
+					part {
+						content = "#cloud-config\npackages:\n  - git\n"
+					}
+				}`, filename),
+				Check: r.ComposeTestCheckFunc(
+					r.TestCheckResourceAttr("cloudinit_config_drive.foo", "user_data", "Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\npackages:\n  - git\n\r\n--MIMEBOUNDARY--\r\n"),
+					r.TestCheckResourceAttr("cloudinit_config_drive.foo", "content_sha256", "7a3e8ae8b9621061b55257472741f71d20a9f1a3186fe64a70cbdae61ac8c6da"),
+					r.TestCheckResourceAttr("cloudinit_config_drive.foo", "size", "65536"),
+					testCheckImageFile("cloudinit_config_drive.foo", filename),
+				),
+			},
+		},
+	})
+}
+
+func TestConfigDriveResource_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		ResourceBlock string
+		ErrorMatch    *regexp.Regexp
+	}{
+		{
+			"meta_data without uuid",
+			`resource "cloudinit_config_drive" "foo" {
+				meta_data = jsonencode({
+					hostname = "web-01"
+				})
+
+				part {
+					content = "#!/bin/sh\necho hello\n"
+				}
+			}`,
+			regexp.MustCompile(`Invalid meta_data: expected the uuid key to be a string`),
+		},
+		{
+			"invalid public key",
+			`resource "cloudinit_config_drive" "foo" {
+				meta_data = jsonencode({
+					uuid        = "0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+					public_keys = {
+						default = "ssh-ed25519 c3NoLXJzYQ=="
+					}
+				})
+
+				part {
+					content = "#!/bin/sh\necho hello\n"
+				}
+			}`,
+			regexp.MustCompile(`invalid public key "default"`),
+		},
+		{
+			"network_data links not a list",
+			`resource "cloudinit_config_drive" "foo" {
+				meta_data    = jsonencode({
+					uuid = "0b7b4e2a-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+				})
+				network_data = jsonencode({
+					links = { id = "eth0" }
+				})
+
+				part {
+					content = "#!/bin/sh\necho hello\n"
+				}
+			}`,
+			regexp.MustCompile(`Invalid network_data: expected the links key to be a list`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.ResourceBlock,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
+
+// meta_data which is unknown while the config is validated is checked before
+// the image is built.
+func TestConfigDriveResource_handleUnknownErrors(t *testing.T) {
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_4_0),
+		},
+		Steps: []r.TestStep{
+			{
+				Config: `resource "terraform_data" "meta_data" {
+					input = {
+						hostname = "web-01"
+					}
+				}
+
+				resource "cloudinit_config_drive" "foo" {
+					meta_data = jsonencode(terraform_data.meta_data.output)
+
+					part {
+						content = "#!/bin/sh\necho hello\n"
+					}
+				}`,
+				ExpectError: regexp.MustCompile(`Invalid meta_data: expected the uuid key to be a string`),
+			},
+		},
+	})
+}
@@ -0,0 +1,345 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/diskimage"
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
+)
+
+var (
+	_ resource.ResourceWithValidateConfig = (*noCloudSeedResource)(nil)
+)
+
+const (
+	// noCloudLabel is the volume label cloud-init looks for NoCloud seed images
+	// with.
+	noCloudLabel = "cidata"
+
+	seedFormatISO9660 = "iso9660"
+	seedFormatVFAT    = "vfat"
+)
+
+type noCloudSeedResource struct{}
+
+type noCloudSeedModel struct {
+	ID                    types.String `tfsdk:"id"`
+	Filename              types.String `tfsdk:"filename"`
+	Format                types.String `tfsdk:"format"`
+	Parts                 types.List   `tfsdk:"part"` // configPartModel
+	Boundary              types.String `tfsdk:"boundary"`
+	CloudInitVersion      types.String `tfsdk:"cloud_init_version"`
+	CloudConfigValidation types.String `tfsdk:"cloud_config_validation"`
+	CustomContentTypes    types.List   `tfsdk:"custom_content_types"` // types.String
+	OutputFormat          types.String `tfsdk:"output_format"`
+	ExpandArchiveParts    types.Bool   `tfsdk:"expand_archive_parts"`
+	MetaData              types.String `tfsdk:"meta_data"`
+	NetworkConfig         types.String `tfsdk:"network_config"`
+	VendorData            types.String `tfsdk:"vendor_data"`
+	UserData              types.String `tfsdk:"user_data"`
+	ContentBase64         types.String `tfsdk:"content_base64"`
+	ContentSHA256         types.String `tfsdk:"content_sha256"`
+	Size                  types.Int64  `tfsdk:"size"`
+}
+
+func (r *noCloudSeedResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_nocloud_seed"
+}
+
+func (r *noCloudSeedResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var seed noCloudSeedModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &seed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(seed.userDataConfig().validate(ctx)...)
+}
+
+func (r *noCloudSeedResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Blocks: map[string]schema.Block{
+			"part": imagePartBlock("A nested block type which adds a file to the `user-data` of the image. Use multiple `part` blocks to " +
+				"specify multiple files, which will be included in order of declaration in the final MIME document."),
+		},
+		Attributes: resourceRenderAttributes(map[string]schema.Attribute{
+			"filename": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The path of a local file to write the image to, such as `seed.iso`, creating parent directories as " +
+					"needed. The file is deleted with the resource, and written again when it is missing or changed outside of Terraform. " +
+					"When omitted, the image is only available as `content_base64`.",
+			},
+			"format": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.OneOf(seedFormatISO9660, seedFormatVFAT),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				Computed: true,
+				Default:  stringdefault.StaticString(seedFormatISO9660),
+				MarkdownDescription: "The filesystem of the image, `iso9660` for an ISO 9660 image with Joliet and Rock Ridge extensions, as " +
+					"written by `genisoimage -J -r`, or `vfat` for a FAT12 image with long file names. Defaults to `iso9660`.",
+			},
+			"meta_data": schema.StringAttribute{
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The content of the `meta-data` file, a YAML document such as the `rendered` output of the " +
+					"`cloudinit_meta_data` data source or the result of `yamlencode`, with the `instance-id` and `local-hostname` of the " +
+					"instance. cloud-init runs per-instance modules again when the `instance-id` changes. Defaults to an empty file, with " +
+					"which cloud-init uses `nocloud` as the `instance-id`.",
+			},
+			"network_config": schema.StringAttribute{
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The content of the `network-config` file, a [network configuration](https://cloudinit.readthedocs.io/en/latest/reference/network-config.html) " +
+					"in version 1 or 2 format, such as the `rendered` output of the `cloudinit_network_config` data source. The file is left out when omitted.",
+			},
+			"vendor_data": schema.StringAttribute{
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The content of the `vendor-data` file, which takes any user data format and is overridden by the " +
+					"`user-data`. The file is left out when omitted.",
+			},
+			"output_format": schema.StringAttribute{
+				Validators: []validator.String{
+					stringvalidator.OneOf(outputFormats()...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Optional: true,
+				MarkdownDescription: "The format of the `user-data` file. `mime` renders a multi-part MIME message. `raw` renders the " +
+					"content of the only part as is, and requires exactly one part whose content starts with the marker of its content " +
+					"type, such as `#cloud-config` or `#!`. `archive` renders a " +
+					"[cloud-config-archive](https://cloudinit.readthedocs.io/en/latest/explanation/format.html#cloud-config-archive) document. " +
+					"Defaults to `mime`.",
+			},
+			"expand_archive_parts": schema.BoolAttribute{
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.RequiresReplace(),
+				},
+				Optional:            true,
+				MarkdownDescription: expandArchivePartsDescription,
+			},
+			"user_data": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The content of the `user-data` file, rendered from the parts like the `cloudinit_config` data source does without gzip or base64.",
+			},
+			"content_base64": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The base64 encoded image, for uploading it without a local file.",
+			},
+			"content_sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Hex encoded SHA-256 digest of the image. The image only depends on the files, so it stays the same as long as they do.",
+			},
+			"size": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "The size in bytes of the image.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The same as `content_sha256`.",
+			},
+		}),
+		MarkdownDescription: "Builds a [NoCloud](https://cloudinit.readthedocs.io/en/latest/reference/datasources/nocloud.html) seed image, " +
+			"the disk labelled `cidata` which cloud-init reads `user-data`, `meta-data`, `network-config` and `vendor-data` from, for " +
+			"virtual machines without a metadata service such as libvirt, Proxmox or plain QEMU.\n\n" +
+			"The `user-data` is rendered from `part` blocks like the `cloudinit_config` data source does. The image is built in the " +
+			"provider, without `genisoimage`, `mkisofs` or `mkfs.vfat`, and written to `filename` and exposed as `content_base64`.",
+	}
+}
+
+func (r *noCloudSeedResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var seed noCloudSeedModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &seed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	image, diags := seed.update(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !seed.Filename.IsNull() {
+		if err := writeImage(seed.Filename.ValueString(), image); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("filename"), "Unable to write seed image", err.Error())
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, seed)...)
+}
+
+// Read removes the resource when the file is missing or has changed, so that
+// it is written again.
+func (r *noCloudSeedResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var seed noCloudSeedModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &seed)...)
+	if resp.Diagnostics.HasError() || seed.Filename.IsNull() {
+		return
+	}
+
+	image, err := os.ReadFile(seed.Filename.ValueString())
+	if errors.Is(err, fs.ErrNotExist) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("filename"), "Unable to read seed image", err.Error())
+		return
+	}
+
+	if hashcode.SHA256(string(image)) != seed.ContentSHA256.ValueString() {
+		resp.State.RemoveResource(ctx)
+	}
+}
+
+func (r *noCloudSeedResource) Update(_ context.Context, _ resource.UpdateRequest, _ *resource.UpdateResponse) {
+}
+
+func (r *noCloudSeedResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var seed noCloudSeedModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &seed)...)
+	if resp.Diagnostics.HasError() || seed.Filename.IsNull() {
+		return
+	}
+
+	if err := os.Remove(seed.Filename.ValueString()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		resp.Diagnostics.AddAttributeError(path.Root("filename"), "Unable to delete seed image", err.Error())
+	}
+}
+
+// userDataConfig returns the config the user-data is rendered from. The
+// image holds the user-data as is, so it is neither gzipped nor base64
+// encoded.
+func (seed noCloudSeedModel) userDataConfig() configModel {
+	return configModel{
+		Parts:                 seed.Parts,
+		Gzip:                  types.BoolValue(false),
+		Base64Encode:          types.BoolValue(false),
+		Boundary:              seed.Boundary,
+		CloudInitVersion:      seed.CloudInitVersion,
+		CloudConfigValidation: seed.CloudConfigValidation,
+		TargetPlatform:        types.StringNull(),
+		CustomContentTypes:    seed.CustomContentTypes,
+		OutputFormat:          seed.OutputFormat,
+		ExpandArchiveParts:    seed.ExpandArchiveParts,
+		SensitiveOutput:       types.BoolNull(),
+	}
+}
+
+// update renders the user-data, builds the image and sets the computed
+// attributes. The image is returned for writing to filename.
+func (seed *noCloudSeedModel) update(ctx context.Context) ([]byte, diag.Diagnostics) {
+	userDataConfig := seed.userDataConfig()
+
+	diags := userDataConfig.update(ctx)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	seed.Parts = userDataConfig.Parts
+	seed.UserData = userDataConfig.Rendered
+
+	files := []diskimage.File{
+		{Path: "user-data", Content: []byte(seed.UserData.ValueString())},
+		{Path: "meta-data", Content: []byte(seed.MetaData.ValueString())},
+	}
+
+	if !seed.NetworkConfig.IsNull() {
+		files = append(files, diskimage.File{Path: "network-config", Content: []byte(seed.NetworkConfig.ValueString())})
+	}
+
+	if !seed.VendorData.IsNull() {
+		files = append(files, diskimage.File{Path: "vendor-data", Content: []byte(seed.VendorData.ValueString())})
+	}
+
+	var image []byte
+	var err error
+
+	if seed.Format.ValueString() == seedFormatVFAT {
+		image, err = diskimage.VFAT(noCloudLabel, files)
+	} else {
+		image, err = diskimage.ISO9660(noCloudLabel, files)
+	}
+
+	if err != nil {
+		diags.AddError("Unable to build seed image", err.Error())
+		return nil, diags
+	}
+
+	seed.ContentBase64 = types.StringValue(base64.StdEncoding.EncodeToString(image))
+	seed.ContentSHA256 = types.StringValue(hashcode.SHA256(string(image)))
+	seed.Size = types.Int64Value(int64(len(image)))
+	seed.ID = seed.ContentSHA256
+
+	return image, diags
+}
+
+// writeImage writes image to filename, creating its parent directories.
+func writeImage(filename string, image []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, image, 0o644)
+}
+
+// imagePartBlock returns the part block of the resources building images, whose
+// parts are rendered into the user data file of the image.
+func imagePartBlock(description string) schema.ListNestedBlock {
+	return schema.ListNestedBlock{
+		Validators: []validator.List{
+			listvalidator.IsRequired(),
+		},
+		PlanModifiers: []planmodifier.List{
+			listplanmodifier.RequiresReplace(),
+		},
+		NestedObject:        resourcePartObject(partContentAttributes, nil),
+		MarkdownDescription: description,
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	r "github.com/hashicorp/terraform-plugin-testing/helper/resource"
+
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/hashcode"
+)
+
+func TestNoCloudSeedResource(t *testing.T) {
+	testCases := []struct {
+		Format         string
+		ExpectedSHA256 string
+		ExpectedSize   string
+	}{
+		{
+			"iso9660",
+			"ddba3b6d8d6aefaad728fecbd2484b16dfc90e4b8f91b6a5e09ca774a7101845",
+			"57344",
+		},
+		{
+			"vfat",
+			"a6b69e826cfaacc075dfa62669d27f4f214803e5218a2f958283e1331b34adc0",
+			"18944",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Format, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "seed", "seed.img")
+
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config: fmt.Sprintf(`resource "cloudinit_nocloud_seed" "foo" {
+							filename  = %q
+							format    = %q
+							meta_data = "instance-id: iid-local01\nlocal-hostname: cloudimg\n"
+
+							part {
+								content = "#cloud-config\npackages:\n  - git\n"
+							}
+						}`, filename, tt.Format),
+						Check: r.ComposeTestCheckFunc(
+							r.TestCheckResourceAttr("cloudinit_nocloud_seed.foo", "user_data", "Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\nMIME-Version: 1.0\r\n\r\n--MIMEBOUNDARY\r\nContent-Transfer-Encoding: 7bit\r\nContent-Type: text/cloud-config\r\nMime-Version: 1.0\r\n\r\n#cloud-config\npackages:\n  - git\n\r\n--MIMEBOUNDARY--\r\n"),
+							r.TestCheckResourceAttr("cloudinit_nocloud_seed.foo", "content_sha256", tt.ExpectedSHA256),
+							r.TestCheckResourceAttr("cloudinit_nocloud_seed.foo", "size", tt.ExpectedSize),
+							r.TestCheckResourceAttr("cloudinit_nocloud_seed.foo", "part.0.content_type", "text/cloud-config"),
+							testCheckImageFile("cloudinit_nocloud_seed.foo", filename),
+						),
+					},
+				},
+			})
+		})
+	}
+}
+
+func TestNoCloudSeedResource_missingFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "seed.iso")
+	config := fmt.Sprintf(`resource "cloudinit_nocloud_seed" "foo" {
+		filename = %q
+
+		part {
+			content = "#!/bin/sh\necho hello\n"
+		}
+	}`, filename)
+
+	r.UnitTest(t, r.TestCase{
+		ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+		Steps: []r.TestStep{
+			{
+				Config: config,
+				Check:  testCheckImageFile("cloudinit_nocloud_seed.foo", filename),
+			},
+			{
+				PreConfig: func() {
+					if err := os.Remove(filename); err != nil {
+						t.Fatalf("unable to remove %s: %s", filename, err)
+					}
+				},
+				Config: config,
+				Check:  testCheckImageFile("cloudinit_nocloud_seed.foo", filename),
+			},
+		},
+	})
+}
+
+func TestNoCloudSeedResource_handleErrors(t *testing.T) {
+	testCases := []struct {
+		Name          string
+		ResourceBlock string
+		ErrorMatch    *regexp.Regexp
+	}{
+		{
+			"unknown format",
+			`resource "cloudinit_nocloud_seed" "foo" {
+				format = "ext4"
+
+				part {
+					content = "#!/bin/sh\necho hello\n"
+				}
+			}`,
+			regexp.MustCompile(`value must be one of`),
+		},
+		{
+			"at least one part is required",
+			`resource "cloudinit_nocloud_seed" "foo" {
+				meta_data = "instance-id: iid-local01\n"
+			}`,
+			regexp.MustCompile("part must have a configuration value"),
+		},
+		{
+			"invalid cloud-config value",
+			`resource "cloudinit_nocloud_seed" "foo" {
+				cloud_config_validation = "error"
+
+				part {
+					content_type = "text/cloud-config"
+					content      = "#cloud-config\npackage_update: \"yes\"\n"
+				}
+			}`,
+			regexp.MustCompile(`package_update: expected boolean, got string`),
+		},
+		{
+			"raw output format with more than one part",
+			`resource "cloudinit_nocloud_seed" "foo" {
+				output_format = "raw"
+
+				part {
+					content = "#!/bin/sh\necho hello\n"
+				}
+
+				part {
+					content = "#!/bin/sh\necho world\n"
+				}
+			}`,
+			regexp.MustCompile(`Expected exactly one part when output_format is "raw"`),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.Name, func(t *testing.T) {
+			r.UnitTest(t, r.TestCase{
+				ProtoV5ProviderFactories: testProtoV5ProviderFactories,
+				Steps: []r.TestStep{
+					{
+						Config:      tt.ResourceBlock,
+						ExpectError: tt.ErrorMatch,
+					},
+				},
+			})
+		})
+	}
+}
+
+// testCheckImageFile checks that filename holds the image of the resource.
+func testCheckImageFile(name, filename string) r.TestCheckFunc {
+	return r.TestCheckResourceAttrWith(name, "content_sha256", func(value string) error {
+		image, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+
+		if actual := hashcode.SHA256(string(image)); actual != value {
+			return fmt.Errorf("expected %s to have the SHA-256 digest %s, got %s", filename, value, actual)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,38 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"github.com/hashicorp/terraform-provider-cloudinit/internal/cloudconfig"
+)
+
+// redactedDetail replaces the details of problems found in the content of a
+// part when sensitive_output is set, as they may quote the content.
+const redactedDetail = "details are hidden as sensitive_output is set"
+
+// contentDetail returns detail, which may quote the content of a part, or
+// redactedDetail when sensitive_output is set.
+func (c configModel) contentDetail(detail string) string {
+	if c.SensitiveOutput.ValueBool() {
+		return redactedDetail
+	}
+
+	return detail
+}
+
+// contentProblem describes a problem found in the cloud-config content of a
+// part. When sensitive_output is set only its position and the path of the
+// offending key are kept, as the message may quote values.
+func (c configModel) contentProblem(problem cloudconfig.Diagnostic) string {
+	if !c.SensitiveOutput.ValueBool() {
+		return problem.String()
+	}
+
+	problem.Message = redactedDetail
+	if problem.Path != "" {
+		problem.Message = problem.Path + ": " + redactedDetail
+	}
+
+	return problem.String()
+}
@@ -0,0 +1,105 @@
+// Copyright IBM Corp. 2019, 2026
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// platformLimit is the user data size limit of a target platform.
+type platformLimit struct {
+	// Bytes is the maximum size of the user data, or zero if the platform has
+	// no limit worth checking.
+	Bytes int
+
+	// Decoded is true when the limit applies to the user data before it is
+	// base64 encoded, rather than to the rendered string.
+	Decoded bool
+
+	// Soft is true when the limit can be raised by the operator, in which case
+	// exceeding it is only a warning.
+	Soft bool
+}
+
+var platformLimits = map[string]platformLimit{
+	// https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/user-data.html
+	"aws": {Bytes: 16 * 1024, Decoded: true},
+	// https://learn.microsoft.com/en-us/azure/virtual-machines/custom-data
+	"azure": {Bytes: 64*1024 - 1, Decoded: true},
+	// https://cloud.google.com/compute/docs/metadata/setting-custom-metadata#limitations
+	"gcp": {Bytes: 256 * 1024},
+	// https://docs.openstack.org/nova/latest/user/metadata.html#user-provided-data
+	"openstack": {Bytes: 64*1024 - 1},
+	// guestinfo values are limited by the host's guestinfo size, 64 KiB by default.
+	"vmware": {Bytes: 64 * 1024, Soft: true},
+	// NoCloud seed images have no practical size limit.
+	"libvirt": {},
+}
+
+// targetPlatforms returns the supported values of target_platform, sorted.
+func targetPlatforms() []string {
+	platforms := make([]string, 0, len(platformLimits))
+	for platform := range platformLimits {
+		platforms = append(platforms, platform)
+	}
+
+	sort.Strings(platforms)
+
+	return platforms
+}
+
+// validatePlatformLimit renders the config to check its size against the limit
+// of the target platform before it is applied, which requires all of it to be
+// known. The data source is checked when read instead, as it renders the config
+// while planning anyway.
+func (c configModel) validatePlatformLimit(ctx context.Context) diag.Diagnostics {
+	if c.TargetPlatform.IsNull() || !c.isKnown() {
+		return nil
+	}
+
+	return c.update(ctx)
+}
+
+// checkPlatformLimit compares the size of the user data with the limit of the
+// target platform. decodedSize is the size before base64 encoding, and
+// renderedSize the size of the final rendered string.
+func (c configModel) checkPlatformLimit(decodedSize int, renderedSize int) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if c.TargetPlatform.IsNull() || c.TargetPlatform.IsUnknown() {
+		return diags
+	}
+
+	platform := c.TargetPlatform.ValueString()
+
+	limit, ok := platformLimits[platform]
+	if !ok || limit.Bytes == 0 {
+		return diags
+	}
+
+	size, sizeDescription := renderedSize, ""
+	if limit.Decoded {
+		size, sizeDescription = decodedSize, " before base64 encoding"
+	}
+
+	if size <= limit.Bytes {
+		return diags
+	}
+
+	detail := fmt.Sprintf("The rendered cloud-init config is %d bytes%s, which exceeds the %d byte user data limit of %s. "+
+		"Consider enabling gzip, or moving large files out of the user data.", size, sizeDescription, limit.Bytes, platform)
+
+	if limit.Soft {
+		diags.AddAttributeWarning(path.Root("target_platform"), "User Data Size Limit Exceeded", detail)
+	} else {
+		diags.AddAttributeError(path.Root("target_platform"), "User Data Size Limit Exceeded", detail)
+	}
+
+	return diags
+}